@@ -12,29 +12,36 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
 	"github.com/grafana/grafana/pkg/internal/bus"
 	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/grafana/grafana/pkg/internal/util/errutil"
 	"github.com/urfave/cli/v2"
 )
 
-func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore.SQLStore) error) func(context *cli.Context) error {
-	return func(context *cli.Context) error {
-		cmd := &utils.ContextCommandLine{Context: context}
-		debug := cmd.Bool("debug")
+func loadConfig(cmd *utils.ContextCommandLine) (*setting.Cfg, error) {
+	cfg := setting.NewCfg()
 
-		cfg := setting.NewCfg()
+	configOptions := strings.Split(cmd.String("configOverrides"), " ")
+	if err := cfg.Load(&setting.CommandLineArgs{
+		Config:   cmd.ConfigFile(),
+		HomePath: cmd.HomePath(),
+		Args:     append(configOptions, cmd.Args().Slice()...), // tailing arguments have precedence over the options string
+	}); err != nil {
+		return nil, errutil.Wrap("failed to load configuration", err)
+	}
 
-		configOptions := strings.Split(cmd.String("configOverrides"), " ")
-		if err := cfg.Load(&setting.CommandLineArgs{
-			Config:   cmd.ConfigFile(),
-			HomePath: cmd.HomePath(),
-			Args:     append(configOptions, cmd.Args().Slice()...), // tailing arguments have precedence over the options string
-		}); err != nil {
-			return errutil.Wrap("failed to load configuration", err)
-		}
+	if cmd.Bool("debug") {
+		cfg.LogConfigSources()
+	}
+	return cfg, nil
+}
 
-		if debug {
-			cfg.LogConfigSources()
+func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore.SQLStore) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
 		}
 
 		engine := &sqlstore.SQLStore{}
@@ -53,6 +60,30 @@ func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore
 	}
 }
 
+// runMigratorCommand runs a command with the migrator of the configured database, without running the migrations.
+func runMigratorCommand(command func(commandLine utils.CommandLine, mg *migrator.Migrator) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		engine := &sqlstore.SQLStore{Cfg: cfg}
+		mg, err := engine.NewMigrator()
+		if err != nil {
+			return errutil.Wrap("failed to initialize SQL engine", err)
+		}
+
+		if err := command(cmd, mg); err != nil {
+			return err
+		}
+
+		logger.Info("\n\n")
+		return nil
+	}
+}
+
 func runPluginCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		cmd := &utils.ContextCommandLine{Context: context}
@@ -79,6 +110,12 @@ var pluginCommands = []*cli.Command{
 		Name:   "install",
 		Usage:  "install <plugin id> <plugin version (optional)>",
 		Action: runPluginCommand(cmd.installCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from-bundle",
+				Usage: "install the plugin and its dependencies from an offline plugin bundle",
+			},
+		},
 	}, {
 		Name:   "list-remote",
 		Usage:  "list remote available plugins",
@@ -122,6 +159,79 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "rotate-secrets-key",
+		Usage:  "Re-encrypts all stored secrets with data keys wrapped by a new key encryption key",
+		Action: runDbCommand(datamigrations.RotateSecretsKey),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "key-file",
+				Usage: "path to the file holding the new key encryption key",
+			},
+		},
+	},
+	{
+		Name:   "export",
+		Usage:  "export <directory>: dumps dashboards, folders, datasources, library elements and alert rules in the file provisioning format",
+		Action: runDbCommand(exportCommand),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "with-secrets",
+				Usage: "export the secrets of datasources, decrypted",
+				Value: false,
+			},
+		},
+	},
+	{
+		Name:   "import",
+		Usage:  "import <directory>: loads a directory created by the export command, updating existing items with the same UID",
+		Action: runDbCommand(importCommand),
+	},
+	{
+		Name:   "import-alert-rules",
+		Usage:  "import-alert-rules <rule file>...: converts the rules of Prometheus or Loki rule files into Grafana managed alert rules",
+		Action: runDbCommand(importAlertRulesCommand),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "the organization of the alert rules",
+				Value: 1,
+			},
+			&cli.StringFlag{
+				Name:     "folder",
+				Usage:    "the title of the folder where the rule groups are saved",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "datasource",
+				Usage:    "the UID of the Prometheus or Loki data source queried by the alert rules",
+				Required: true,
+			},
+		},
+	},
+	{
+		Name:  "migrations",
+		Usage: "Reviews the database migrations before upgrading",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "status",
+				Usage:  "Lists the applied and pending migrations",
+				Action: runMigratorCommand(migrationsStatusCommand),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "sql",
+						Usage: "print the SQL of pending migrations for the configured database",
+						Value: false,
+					},
+				},
+			},
+			{
+				Name:   "dry-run",
+				Usage:  "Runs the pending migrations in a transaction that is rolled back. Not supported for MySQL.",
+				Action: runMigratorCommand(migrationsDryRunCommand),
+			},
+		},
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your db",
@@ -131,6 +241,17 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "copy-database",
+				Usage:  "Copies all data to the database configured in --target-config, which can be of another type, after running the migrations on it. Stop Grafana first.",
+				Action: runDbCommand(datamigrations.CopyDatabase),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "target-config",
+						Usage: "path to a configuration file with the [database] section of the target database",
+					},
+				},
+			},
 		},
 	},
 }
@@ -9,6 +9,11 @@ import (
 
 // NewCliContext creates a new CLI context with a certain set of flags.
 func NewCliContext(flags map[string]string) (*utils.ContextCommandLine, error) {
+	return NewCliContextWithArgs(flags)
+}
+
+// NewCliContextWithArgs creates a new CLI context with a certain set of flags and positional arguments.
+func NewCliContextWithArgs(flags map[string]string, args ...string) (*utils.ContextCommandLine, error) {
 	app := cli.App{
 		Name: "Test",
 	}
@@ -19,6 +24,9 @@ func NewCliContext(flags map[string]string) (*utils.ContextCommandLine, error) {
 			return nil, err
 		}
 	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
 
 	return &utils.ContextCommandLine{
 		Context: cli.NewContext(&app, flagSet, nil),
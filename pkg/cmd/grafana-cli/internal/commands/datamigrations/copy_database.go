@@ -0,0 +1,58 @@
+package datamigrations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// CopyDatabase copies the configured database into the database configured in the [database] section of
+// the file given by --target-config, which can be of another type. The target database is migrated first,
+// and its existing rows are replaced.
+func CopyDatabase(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	targetConfig := c.String("target-config")
+	if targetConfig == "" {
+		return errors.New("--target-config is required")
+	}
+
+	raw, err := ini.Load(targetConfig)
+	if err != nil {
+		return errutil.Wrapf(err, "failed to load %s", targetConfig)
+	}
+
+	// relative SQLite paths and the feature toggles affecting migrations are taken from the current configuration
+	cfg := setting.NewCfg()
+	cfg.Raw = raw
+	cfg.DataPath = sqlStore.Cfg.DataPath
+	cfg.FeatureToggles = sqlStore.Cfg.FeatureToggles
+
+	target := &sqlstore.SQLStore{Cfg: cfg}
+	if err := target.Migrate(); err != nil {
+		return errutil.Wrap("failed to migrate target database", err)
+	}
+	logger.Infof("%s Migrated target database\n", color.GreenString("✔"))
+
+	results, err := sqlstore.CopyDatabase(context.Background(), sqlStore, target)
+	for _, result := range results {
+		if result.SourceRows == result.TargetRows {
+			logger.Infof("%s Copied %d rows of %s\n", color.GreenString("✔"), result.TargetRows, result.Table)
+		} else {
+			logger.Infof("%s Copied %d of %d rows of %s\n", color.RedString("✗"), result.TargetRows, result.SourceRows, result.Table)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("\n")
+	logger.Warnf("Update the [database] section of your configuration to use the target database before restarting Grafana.\n")
+	return nil
+}
@@ -0,0 +1,72 @@
+package datamigrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/commands/commandstest"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestCopyDatabaseCommand(t *testing.T) {
+	source := sqlstore.InitTestDB(t)
+	session := source.NewSession()
+	defer session.Close()
+
+	_, err := session.Insert(&models.Org{Id: 2, Name: "Org", Created: time.Now(), Updated: time.Now()})
+	require.NoError(t, err)
+	for i := 1; i <= 150; i++ {
+		_, err := source.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     2,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": fmt.Sprintf("Dashboard %d", i)}),
+		})
+		require.NoError(t, err)
+	}
+
+	targetPath := filepath.Join(t.TempDir(), "target.db")
+	targetConfig := filepath.Join(t.TempDir(), "target.ini")
+	err = ioutil.WriteFile(targetConfig, []byte(fmt.Sprintf("[database]\ntype = sqlite3\npath = %s\n", targetPath)), 0600)
+	require.NoError(t, err)
+
+	c, err := commandstest.NewCliContext(map[string]string{"target-config": targetConfig})
+	require.NoError(t, err)
+	require.NoError(t, CopyDatabase(c, source))
+
+	raw, err := ini.Load(targetConfig)
+	require.NoError(t, err)
+	cfg := setting.NewCfg()
+	cfg.Raw = raw
+	target := &sqlstore.SQLStore{Cfg: cfg}
+	require.NoError(t, target.Migrate())
+
+	targetSession := target.NewSession()
+	defer targetSession.Close()
+
+	var orgs []*models.Org
+	require.NoError(t, targetSession.Find(&orgs))
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "Org", orgs[0].Name)
+
+	var dashboards []*models.Dashboard
+	require.NoError(t, targetSession.Asc("id").Find(&dashboards))
+	require.Len(t, dashboards, 150)
+	assert.Equal(t, "Dashboard 150", dashboards[149].Title)
+	assert.Equal(t, "Dashboard 150", dashboards[149].Data.Get("title").MustString())
+
+	t.Run("Copying again replaces the rows of the target", func(t *testing.T) {
+		require.NoError(t, CopyDatabase(c, source))
+
+		count, err := targetSession.Count(&models.Dashboard{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(150), count)
+	})
+}
@@ -0,0 +1,179 @@
+package datamigrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// secureColumn is a column holding secrets. reEncrypt decrypts the secrets of a value of the column and
+// encrypts them again with a key provider. The values of text columns are written as strings, those of
+// blob columns as bytes.
+type secureColumn struct {
+	table     string
+	column    string
+	blob      bool
+	reEncrypt func(value []byte, provider envelope.KeyProvider) ([]byte, error)
+}
+
+var secureColumns = []secureColumn{
+	{table: "data_source", column: "secure_json_data", reEncrypt: reEncryptSecureJSONData},
+	{table: "plugin_setting", column: "secure_json_data", reEncrypt: reEncryptSecureJSONData},
+	{table: "alert_notification", column: "secure_settings", reEncrypt: reEncryptSecureJSONData},
+	{table: "alert_recording_config", column: "secure_settings", reEncrypt: reEncryptSecureJSONData},
+	{table: "alert_configuration", column: "alertmanager_configuration", reEncrypt: reEncryptAlertmanagerConfiguration},
+	{table: "dashboard_snapshot", column: "dashboard_encrypted", blob: true, reEncrypt: reEncryptSecret},
+	{table: "user_auth", column: "o_auth_access_token", reEncrypt: reEncryptEncodedSecret},
+	{table: "user_auth", column: "o_auth_refresh_token", reEncrypt: reEncryptEncodedSecret},
+	{table: "user_auth", column: "o_auth_token_type", reEncrypt: reEncryptEncodedSecret},
+}
+
+// RotateSecretsKey decrypts every stored secret and seals it again with a data key wrapped by the
+// key encryption key in the file given by --key-file. Secrets are read using the current
+// configuration, so the configuration must be switched to the new key file afterwards.
+func RotateSecretsKey(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	keyFile := c.String("key-file")
+	if keyFile == "" {
+		return errors.New("--key-file is required")
+	}
+
+	provider, err := envelope.NewLocalFileProvider(keyFile)
+	if err != nil {
+		return err
+	}
+
+	return sqlStore.WithTransactionalDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+		for _, sc := range secureColumns {
+			updated, err := reEncryptColumn(session, sc, provider)
+			if err != nil {
+				return errutil.Wrapf(err, "failed to re-encrypt %s.%s", sc.table, sc.column)
+			}
+
+			logger.Infof("%s Re-encrypted %s for %d rows in %s\n", color.GreenString("✔"), sc.column, updated, sc.table)
+		}
+
+		logger.Info("\n")
+		logger.Warnf("Set envelope_encryption_provider = %s and envelope_encryption_key_file = %s in the [security] "+
+			"section of your configuration before restarting Grafana.\n", envelope.LocalFileProviderType, keyFile)
+		return nil
+	})
+}
+
+func reEncryptColumn(session *sqlstore.DBSession, sc secureColumn, provider envelope.KeyProvider) (int, error) {
+	var rows []map[string][]byte
+
+	session.Cols("id", sc.column)
+	session.Table(sc.table)
+	session.Where(sc.column + " IS NOT NULL AND " + sc.column + " != ''")
+	if err := session.Find(&rows); err != nil {
+		return 0, errutil.Wrapf(err, "failed to select column: %s", sc.column)
+	}
+
+	var rowsUpdated int
+	for _, row := range rows {
+		data, err := sc.reEncrypt(row[sc.column], provider)
+		if err != nil {
+			return 0, errutil.Wrapf(err, "failed to re-encrypt row %s", string(row["id"]))
+		}
+
+		var value interface{} = string(data)
+		if sc.blob {
+			value = data
+		}
+		session.Table(sc.table)
+		session.Where("id = ?", string(row["id"]))
+		session.Cols(sc.column)
+		if _, err := session.Update(map[string]interface{}{sc.column: value}); err != nil {
+			return 0, err
+		}
+
+		rowsUpdated++
+	}
+
+	return rowsUpdated, nil
+}
+
+func reEncryptSecret(encrypted []byte, provider envelope.KeyProvider) ([]byte, error) {
+	decrypted, err := envelope.Decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.Seal(decrypted, provider)
+}
+
+// reEncryptEncodedSecret re-encrypts a base64 encoded secret.
+func reEncryptEncodedSecret(encoded []byte, provider envelope.KeyProvider) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, err
+	}
+	reEncrypted, err := reEncryptSecret(encrypted, provider)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(reEncrypted)), nil
+}
+
+// reEncryptSecureJSONData re-encrypts the secrets of a securejsondata.SecureJsonData.
+func reEncryptSecureJSONData(value []byte, provider envelope.KeyProvider) ([]byte, error) {
+	var secrets map[string][]byte
+	if err := json.Unmarshal(value, &secrets); err != nil {
+		return nil, err
+	}
+
+	for key, encrypted := range secrets {
+		reEncrypted, err := reEncryptSecret(encrypted, provider)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to re-encrypt %q", key)
+		}
+		secrets[key] = reEncrypted
+	}
+
+	return json.Marshal(secrets)
+}
+
+// reEncryptAlertmanagerConfiguration re-encrypts the base64 encoded secure settings of the Grafana managed
+// receivers of an Alertmanager configuration. The configuration is handled as plain JSON, so that it is
+// otherwise left as is.
+func reEncryptAlertmanagerConfiguration(value []byte, provider envelope.KeyProvider) ([]byte, error) {
+	var config map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	amConfig, _ := config["alertmanager_config"].(map[string]interface{})
+	receivers, _ := amConfig["receivers"].([]interface{})
+	for _, r := range receivers {
+		receiver, _ := r.(map[string]interface{})
+		grafanaReceivers, _ := receiver["grafana_managed_receiver_configs"].([]interface{})
+		for _, gr := range grafanaReceivers {
+			grafanaReceiver, _ := gr.(map[string]interface{})
+			secureSettings, _ := grafanaReceiver["secureSettings"].(map[string]interface{})
+			for key, v := range secureSettings {
+				encoded, ok := v.(string)
+				if !ok {
+					continue
+				}
+				reEncrypted, err := reEncryptEncodedSecret([]byte(encoded), provider)
+				if err != nil {
+					return nil, errutil.Wrapf(err, "failed to re-encrypt %q of receiver %v", key, receiver["name"])
+				}
+				secureSettings[key] = string(reEncrypted)
+			}
+		}
+	}
+
+	return json.Marshal(config)
+}
@@ -0,0 +1,155 @@
+package datamigrations
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/commands/commandstest"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
+	"github.com/grafana/grafana/pkg/internal/components/securedata"
+	"github.com/grafana/grafana/pkg/internal/components/securejsondata"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSecretsKeyCommand(t *testing.T) {
+	sqlstore := sqlstore.InitTestDB(t)
+	session := sqlstore.NewSession()
+	defer session.Close()
+
+	ds := &models.DataSource{
+		Type:    "prometheus",
+		Name:    "prometheus",
+		Uid:     "prom",
+		Created: time.Now(),
+		Updated: time.Now(),
+		SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			"password": "foobar",
+		}),
+	}
+	_, err := session.Insert(ds)
+	require.NoError(t, err)
+
+	amConfig := apimodels.PostableUserConfig{}
+	require.NoError(t, json.Unmarshal([]byte(`{"alertmanager_config": {"route": {"receiver": "slack"}, "receivers": [
+		{"name": "slack", "grafana_managed_receiver_configs": [{"name": "slack", "type": "slack", "settings": {}, "secureSettings": {"url": "https://hooks.slack.com/secret"}}]}
+	]}}`), &amConfig))
+	require.NoError(t, amConfig.ProcessConfig())
+	rawAMConfig, err := json.Marshal(amConfig)
+	require.NoError(t, err)
+	_, err = session.Insert(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: string(rawAMConfig), ConfigurationVersion: "v1"})
+	require.NoError(t, err)
+
+	encryptedDashboard, err := securedata.Encrypt([]byte(`{"title": "secret"}`))
+	require.NoError(t, err)
+	_, err = session.Insert(&models.DashboardSnapshot{Key: "snapshot", DeleteKey: "delete", DashboardEncrypted: encryptedDashboard,
+		Dashboard: simplejson.New(), Created: time.Now(), Updated: time.Now(), Expires: time.Now()})
+	require.NoError(t, err)
+
+	encryptedToken, err := envelope.Encrypt([]byte("token"))
+	require.NoError(t, err)
+	_, err = session.Insert(&models.UserAuth{UserId: 1, AuthModule: "oauth_github", AuthId: "1", Created: time.Now(),
+		OAuthAccessToken: base64.StdEncoding.EncodeToString(encryptedToken)})
+	require.NoError(t, err)
+
+	_, err = session.Insert(&ngmodels.RecordingConfig{OrgID: 1, RemoteWriteURL: "http://prometheus:9090/api/v1/write", BasicAuthUser: "grafana",
+		SecureSettings: securejsondata.GetEncryptedJsonData(map[string]string{"basicAuthPassword": "remote-write"}), Updated: time.Now()})
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "kek")
+	err = ioutil.WriteFile(keyFile, []byte("a new key encryption key\n"), 0600)
+	require.NoError(t, err)
+
+	c, err := commandstest.NewCliContext(map[string]string{"key-file": keyFile})
+	require.NoError(t, err)
+	err = RotateSecretsKey(c, sqlstore)
+	require.NoError(t, err)
+
+	provider, err := envelope.NewLocalFileProvider(keyFile)
+	require.NoError(t, err)
+
+	var dss []*models.DataSource
+	err = session.SQL("select * from data_source").Find(&dss)
+	require.NoError(t, err)
+	require.Len(t, dss, 1)
+
+	id, err := envelope.ProviderID(dss[0].SecureJsonData["password"])
+	require.NoError(t, err)
+	assert.Equal(t, provider.ID(), id)
+
+	t.Cleanup(func() {
+		setting.EnvelopeEncryptionProvider = ""
+		setting.EnvelopeEncryptionKeyFile = ""
+	})
+	setting.EnvelopeEncryptionProvider = envelope.LocalFileProviderType
+	setting.EnvelopeEncryptionKeyFile = keyFile
+
+	assert.Equal(t, map[string]string{"password": "foobar"}, dss[0].SecureJsonData.Decrypt())
+
+	t.Run("the secure settings of the contact points are re-encrypted", func(t *testing.T) {
+		var configs []*ngmodels.AlertConfiguration
+		require.NoError(t, session.Table("alert_configuration").Find(&configs))
+		require.Len(t, configs, 1)
+		var rotated apimodels.PostableUserConfig
+		require.NoError(t, json.Unmarshal([]byte(configs[0].AlertmanagerConfiguration), &rotated))
+		receiver := rotated.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0]
+
+		assertRotated(t, provider, receiver.SecureSettings["url"])
+		url, err := receiver.GetDecryptedSecret("url")
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.slack.com/secret", url)
+	})
+
+	t.Run("the remote write password of the recording rules is re-encrypted", func(t *testing.T) {
+		var configs []*ngmodels.RecordingConfig
+		require.NoError(t, session.Table("alert_recording_config").Find(&configs))
+		require.Len(t, configs, 1)
+
+		id, err := envelope.ProviderID(configs[0].SecureSettings["basicAuthPassword"])
+		require.NoError(t, err)
+		assert.Equal(t, provider.ID(), id)
+		assert.Equal(t, map[string]string{"basicAuthPassword": "remote-write"}, configs[0].SecureSettings.Decrypt())
+	})
+
+	t.Run("the encrypted dashboards of the snapshots are re-encrypted", func(t *testing.T) {
+		var snapshots []*models.DashboardSnapshot
+		require.NoError(t, session.Table("dashboard_snapshot").Find(&snapshots))
+		require.Len(t, snapshots, 1)
+
+		id, err := envelope.ProviderID(snapshots[0].DashboardEncrypted)
+		require.NoError(t, err)
+		assert.Equal(t, provider.ID(), id)
+		dashboard, err := snapshots[0].DashboardJSON()
+		require.NoError(t, err)
+		assert.Equal(t, "secret", dashboard.Get("title").MustString())
+	})
+
+	t.Run("the OAuth tokens are re-encrypted", func(t *testing.T) {
+		var userAuths []*models.UserAuth
+		require.NoError(t, session.Table("user_auth").Find(&userAuths))
+		require.Len(t, userAuths, 1)
+
+		assertRotated(t, provider, userAuths[0].OAuthAccessToken)
+		assert.Empty(t, userAuths[0].OAuthRefreshToken)
+	})
+}
+
+// assertRotated asserts that a base64 encoded secret is sealed with a data key wrapped by provider.
+func assertRotated(t *testing.T, provider envelope.KeyProvider, encoded string) {
+	t.Helper()
+	encrypted, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	id, err := envelope.ProviderID(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, provider.ID(), id)
+}
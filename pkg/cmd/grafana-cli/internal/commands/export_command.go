@@ -0,0 +1,438 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// The layout of an export directory. The dashboards and datasources directories follow the file
+// provisioning format, so the export directory can be used as provisioning directory as is.
+const (
+	exportFoldersFile         = "folders.json"
+	exportDashboardsDir       = "dashboards"
+	exportDashboardsFile      = "dashboards.yaml"
+	exportDatasourcesDir      = "datasources"
+	exportDatasourcesFile     = "datasources.yaml"
+	exportLibraryElementsDir  = "library-elements"
+	exportAlertRulesDir       = "alert-rules"
+	exportOrgDirPrefix        = "org_"
+	exportProvisioningVersion = 1
+)
+
+// exportedFolder is a dashboard folder in folders.json, which lists parent folders before their
+// subfolders. Path is the directory of its dashboards, relative to the export directory, whose name
+// is the folder title unless that isn't a valid directory name. The directory of a subfolder is
+// within the directory of its parent.
+type exportedFolder struct {
+	OrgID     int64  `json:"orgId"`
+	UID       string `json:"uid"`
+	ParentUID string `json:"parentUid,omitempty"`
+	Title     string `json:"title"`
+	Path      string `json:"path"`
+}
+
+type exportedDashboardProviders struct {
+	APIVersion int64                       `yaml:"apiVersion"`
+	Providers  []exportedDashboardProvider `yaml:"providers"`
+}
+
+type exportedDashboardProvider struct {
+	Name    string                 `yaml:"name"`
+	OrgID   int64                  `yaml:"orgId"`
+	Type    string                 `yaml:"type"`
+	Options map[string]interface{} `yaml:"options"`
+}
+
+type exportedDatasources struct {
+	APIVersion  int64                `yaml:"apiVersion"`
+	Datasources []exportedDatasource `yaml:"datasources"`
+}
+
+type exportedDatasource struct {
+	OrgID             int64                  `yaml:"orgId"`
+	UID               string                 `yaml:"uid,omitempty"`
+	Name              string                 `yaml:"name"`
+	Type              string                 `yaml:"type"`
+	Access            string                 `yaml:"access,omitempty"`
+	URL               string                 `yaml:"url,omitempty"`
+	User              string                 `yaml:"user,omitempty"`
+	Password          string                 `yaml:"password,omitempty"`
+	Database          string                 `yaml:"database,omitempty"`
+	BasicAuth         bool                   `yaml:"basicAuth,omitempty"`
+	BasicAuthUser     string                 `yaml:"basicAuthUser,omitempty"`
+	BasicAuthPassword string                 `yaml:"basicAuthPassword,omitempty"`
+	WithCredentials   bool                   `yaml:"withCredentials,omitempty"`
+	IsDefault         bool                   `yaml:"isDefault,omitempty"`
+	JSONData          map[string]interface{} `yaml:"jsonData,omitempty"`
+	SecureJSONData    map[string]string      `yaml:"secureJsonData,omitempty"`
+	Editable          bool                   `yaml:"editable"`
+}
+
+type exportedLibraryElement struct {
+	UID         string          `json:"uid"`
+	FolderUID   string          `json:"folderUid,omitempty"`
+	Name        string          `json:"name"`
+	Kind        int64           `json:"kind"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Model       json.RawMessage `json:"model"`
+	// Connections are the UIDs of the dashboards using the element.
+	Connections []string `json:"connections,omitempty"`
+}
+
+type exportedAlertRule struct {
+	UID                 string                       `json:"uid"`
+	NamespaceUID        string                       `json:"namespaceUid"`
+	RuleGroup           string                       `json:"ruleGroup"`
+	RuleGroupIndex      int                          `json:"ruleGroupIndex,omitempty"`
+	RuleGroupPaused     bool                         `json:"ruleGroupPaused,omitempty"`
+	RuleGroupSequential bool                         `json:"ruleGroupSequential,omitempty"`
+	Title               string                       `json:"title"`
+	Condition           string                       `json:"condition"`
+	Data                []ngmodels.AlertQuery        `json:"data"`
+	IntervalSeconds     int64                        `json:"intervalSeconds"`
+	NoDataState         ngmodels.NoDataState         `json:"noDataState"`
+	ExecErrState        ngmodels.ExecutionErrorState `json:"execErrState"`
+	For                 string                       `json:"for"`
+	KeepFiringFor       string                       `json:"keepFiringFor,omitempty"`
+	Annotations         map[string]string            `json:"annotations,omitempty"`
+	Labels              map[string]string            `json:"labels,omitempty"`
+	// Record is set for recording rules, which have no condition.
+	Record   *ngmodels.Record `json:"record,omitempty"`
+	IsPaused bool             `json:"isPaused,omitempty"`
+}
+
+// exportCommand dumps the dashboards, folders, datasources, library elements and alert rules of every
+// organization into the directory given as argument. Datasource secrets are only exported, decrypted,
+// with --with-secrets.
+func exportCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	if c.Args().First() == "" {
+		return errors.New("export directory is required")
+	}
+	dir, err := filepath.Abs(c.Args().First())
+	if err != nil {
+		return err
+	}
+	withSecrets := c.Bool("with-secrets")
+
+	return sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		folders, err := exportDashboards(sess, dir)
+		if err != nil {
+			return errutil.Wrap("failed to export dashboards", err)
+		}
+		if err := exportDatasources(sess, dir, withSecrets); err != nil {
+			return errutil.Wrap("failed to export datasources", err)
+		}
+		if err := exportLibraryElements(sess, dir, folders); err != nil {
+			return errutil.Wrap("failed to export library elements", err)
+		}
+		if err := exportAlertRules(sess, dir); err != nil {
+			return errutil.Wrap("failed to export alert rules", err)
+		}
+
+		logger.Infof("%s Exported to %s\n", color.GreenString("✔"), dir)
+		if withSecrets {
+			logger.Warnf("The export contains datasource secrets in plain text, keep it safe.\n")
+		}
+		return nil
+	})
+}
+
+// exportDashboards writes every dashboard into the directory of its folder and returns the exported folders by ID.
+func exportDashboards(sess *sqlstore.DBSession, dir string) (map[int64]exportedFolder, error) {
+	var dashboards []*models.Dashboard
+	if err := sess.Table("dashboard").Asc("org_id", "id").Find(&dashboards); err != nil {
+		return nil, err
+	}
+
+	folderDashboards := map[int64]*models.Dashboard{}
+	orgs := map[int64]bool{}
+	for _, dash := range dashboards {
+		orgs[dash.OrgId] = true
+		if dash.IsFolder {
+			folderDashboards[dash.Id] = dash
+		}
+	}
+
+	folders := map[int64]exportedFolder{}
+	var folderList []exportedFolder
+	usedPaths := map[string]bool{}
+	// exportFolder adds a folder after its parents, in the directory of its parent.
+	var exportFolder func(dash *models.Dashboard, visited map[int64]bool) exportedFolder
+	exportFolder = func(dash *models.Dashboard, visited map[int64]bool) exportedFolder {
+		if folder, exists := folders[dash.Id]; exists {
+			return folder
+		}
+		visited[dash.Id] = true
+
+		parentPath := filepath.Join(exportDashboardsDir, orgDirName(dash.OrgId))
+		var parentUID string
+		if parent, exists := folderDashboards[dash.FolderId]; exists && !visited[parent.Id] {
+			parentFolder := exportFolder(parent, visited)
+			parentPath = filepath.FromSlash(parentFolder.Path)
+			parentUID = parentFolder.UID
+		}
+
+		path := filepath.Join(parentPath, folderDirName(dash.Title, dash.Uid))
+		if usedPaths[path] {
+			path = filepath.Join(parentPath, dash.Uid)
+		}
+		usedPaths[path] = true
+
+		folder := exportedFolder{OrgID: dash.OrgId, UID: dash.Uid, ParentUID: parentUID, Title: dash.Title, Path: filepath.ToSlash(path)}
+		folders[dash.Id] = folder
+		folderList = append(folderList, folder)
+		return folder
+	}
+	for _, dash := range dashboards {
+		if dash.IsFolder {
+			exportFolder(dash, map[int64]bool{})
+		}
+	}
+
+	var count int
+	for _, dash := range dashboards {
+		if dash.IsFolder {
+			continue
+		}
+
+		path := filepath.Join(exportDashboardsDir, orgDirName(dash.OrgId))
+		if folder, exists := folders[dash.FolderId]; exists {
+			path = filepath.FromSlash(folder.Path)
+		}
+
+		dash.Data.Del("id")
+		data, err := dash.Data.EncodePretty()
+		if err != nil {
+			return nil, err
+		}
+		if err := writeExportFile(filepath.Join(dir, path, dash.Uid+".json"), data); err != nil {
+			return nil, err
+		}
+		count++
+	}
+
+	providers := exportedDashboardProviders{APIVersion: exportProvisioningVersion}
+	for _, orgID := range sortedOrgIDs(orgs) {
+		providers.Providers = append(providers.Providers, exportedDashboardProvider{
+			Name:  fmt.Sprintf("export-%s", orgDirName(orgID)),
+			OrgID: orgID,
+			Type:  "file",
+			Options: map[string]interface{}{
+				// relative to the directory of dashboards.yaml, so the export directory can be moved
+				"path":                      orgDirName(orgID),
+				"foldersFromFilesStructure": true,
+			},
+		})
+	}
+	if err := writeExportYAML(filepath.Join(dir, exportDashboardsDir, exportDashboardsFile), providers); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(folderList, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeExportFile(filepath.Join(dir, exportFoldersFile), data); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("%s Exported %d folders and %d dashboards\n", color.GreenString("✔"), len(folderList), count)
+	return folders, nil
+}
+
+func exportDatasources(sess *sqlstore.DBSession, dir string, withSecrets bool) error {
+	var dataSources []*models.DataSource
+	if err := sess.Table("data_source").Asc("org_id", "id").Find(&dataSources); err != nil {
+		return err
+	}
+
+	exported := exportedDatasources{APIVersion: exportProvisioningVersion}
+	for _, ds := range dataSources {
+		eds := exportedDatasource{
+			OrgID:           ds.OrgId,
+			UID:             ds.Uid,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          string(ds.Access),
+			URL:             ds.Url,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			Editable:        !ds.ReadOnly,
+		}
+		if ds.JsonData != nil {
+			eds.JSONData = ds.JsonData.MustMap()
+		}
+
+		if withSecrets {
+			eds.Password = ds.Password
+			eds.BasicAuthPassword = ds.BasicAuthPassword
+			eds.SecureJSONData = map[string]string{}
+			for key, encrypted := range ds.SecureJsonData {
+				decrypted, err := envelope.Decrypt(encrypted)
+				if err != nil {
+					return errutil.Wrapf(err, "failed to decrypt %q of datasource %s", key, ds.Name)
+				}
+				eds.SecureJSONData[key] = string(decrypted)
+			}
+		}
+
+		exported.Datasources = append(exported.Datasources, eds)
+	}
+
+	if err := writeExportYAML(filepath.Join(dir, exportDatasourcesDir, exportDatasourcesFile), exported); err != nil {
+		return err
+	}
+
+	logger.Infof("%s Exported %d datasources\n", color.GreenString("✔"), len(dataSources))
+	return nil
+}
+
+func exportLibraryElements(sess *sqlstore.DBSession, dir string, folders map[int64]exportedFolder) error {
+	var elements []*libraryelements.LibraryElement
+	if err := sess.Table("library_element").Asc("org_id", "id").Find(&elements); err != nil {
+		return err
+	}
+
+	var connections []struct {
+		ElementID    int64  `xorm:"element_id"`
+		DashboardUID string `xorm:"dashboard_uid"`
+	}
+	err := sess.SQL("SELECT lec.element_id, d.uid AS dashboard_uid FROM library_element_connection lec " +
+		"INNER JOIN dashboard d ON d.id = lec.connection_id WHERE lec.kind = 1 ORDER BY d.uid").Find(&connections)
+	if err != nil {
+		return err
+	}
+	dashboardUIDs := map[int64][]string{}
+	for _, connection := range connections {
+		dashboardUIDs[connection.ElementID] = append(dashboardUIDs[connection.ElementID], connection.DashboardUID)
+	}
+
+	for _, element := range elements {
+		exported := exportedLibraryElement{
+			UID:         element.UID,
+			FolderUID:   folders[element.FolderID].UID,
+			Name:        element.Name,
+			Kind:        element.Kind,
+			Type:        element.Type,
+			Description: element.Description,
+			Model:       element.Model,
+			Connections: dashboardUIDs[element.ID],
+		}
+
+		path := filepath.Join(dir, exportLibraryElementsDir, orgDirName(element.OrgID), element.UID+".json")
+		if err := writeExportJSON(path, exported); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("%s Exported %d library elements\n", color.GreenString("✔"), len(elements))
+	return nil
+}
+
+func exportAlertRules(sess *sqlstore.DBSession, dir string) error {
+	var rules []*ngmodels.AlertRule
+	if err := sess.Table("alert_rule").Asc("org_id", "id").Find(&rules); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		exported := exportedAlertRule{
+			UID:                 rule.UID,
+			NamespaceUID:        rule.NamespaceUID,
+			RuleGroup:           rule.RuleGroup,
+			RuleGroupIndex:      rule.RuleGroupIndex,
+			RuleGroupPaused:     rule.RuleGroupPaused,
+			RuleGroupSequential: rule.RuleGroupSequential,
+			Title:               rule.Title,
+			Condition:           rule.Condition,
+			Data:                rule.Data,
+			IntervalSeconds:     rule.IntervalSeconds,
+			NoDataState:         rule.NoDataState,
+			ExecErrState:        rule.ExecErrState,
+			For:                 rule.For.String(),
+			Annotations:         rule.Annotations,
+			Labels:              rule.Labels,
+			Record:              rule.Record,
+			IsPaused:            rule.IsPaused,
+		}
+		if rule.KeepFiringFor > 0 {
+			exported.KeepFiringFor = rule.KeepFiringFor.String()
+		}
+
+		path := filepath.Join(dir, exportAlertRulesDir, orgDirName(rule.OrgID), rule.UID+".json")
+		if err := writeExportJSON(path, exported); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("%s Exported %d alert rules\n", color.GreenString("✔"), len(rules))
+	return nil
+}
+
+func sortedOrgIDs(orgs map[int64]bool) []int64 {
+	orgIDs := make([]int64, 0, len(orgs))
+	for orgID := range orgs {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+	return orgIDs
+}
+
+func orgDirName(orgID int64) string {
+	return fmt.Sprintf("%s%d", exportOrgDirPrefix, orgID)
+}
+
+// folderDirName returns the title of a folder as directory name, or its UID if the title isn't a valid directory name.
+func folderDirName(title, uid string) string {
+	if title == "" || title == "." || title == ".." || strings.ContainsAny(title, `/\`) {
+		return uid
+	}
+	return title
+}
+
+func writeExportYAML(path string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeExportFile(path, data)
+}
+
+func writeExportJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeExportFile(path, data)
+}
+
+func writeExportFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/commands/commandstest"
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/securejsondata"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportCommands(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	seedExportData(t, sqlStore)
+	exportDir := t.TempDir()
+
+	t.Run("Export writes the file provisioning format", func(t *testing.T) {
+		c, err := commandstest.NewCliContextWithArgs(map[string]string{"with-secrets": "true"}, exportDir)
+		require.NoError(t, err)
+		err = exportCommand(c, sqlStore)
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(exportDir, "dashboards", "org_1", "Team", "dash.json"))
+		assert.FileExists(t, filepath.Join(exportDir, "dashboards", "org_1", "Team", "Sub", "subdash.json"))
+		assert.FileExists(t, filepath.Join(exportDir, "library-elements", "org_1", "panel.json"))
+		assert.FileExists(t, filepath.Join(exportDir, "alert-rules", "org_1", "rule.json"))
+
+		data, err := ioutil.ReadFile(filepath.Join(exportDir, "dashboards", "dashboards.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: org_1\n")
+
+		data, err = ioutil.ReadFile(filepath.Join(exportDir, "datasources", "datasources.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "password: secret")
+
+		var element exportedLibraryElement
+		data, err = ioutil.ReadFile(filepath.Join(exportDir, "library-elements", "org_1", "panel.json"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &element))
+		assert.Equal(t, []string{"dash"}, element.Connections)
+
+		var folders []exportedFolder
+		data, err = ioutil.ReadFile(filepath.Join(exportDir, "folders.json"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &folders))
+		assert.Equal(t, []exportedFolder{
+			{OrgID: 1, UID: "team", Title: "Team", Path: "dashboards/org_1/Team"},
+			{OrgID: 1, UID: "sub", ParentUID: "team", Title: "Sub", Path: "dashboards/org_1/Team/Sub"},
+		}, folders)
+	})
+
+	t.Run("Import loads an export into an empty database", func(t *testing.T) {
+		sqlStore := sqlstore.InitTestDB(t)
+		c, err := commandstest.NewCliContextWithArgs(map[string]string{}, exportDir)
+		require.NoError(t, err)
+
+		// importing twice updates the imported items
+		for i := 0; i < 2; i++ {
+			require.NoError(t, importCommand(c, sqlStore))
+		}
+
+		folder := &models.GetDashboardQuery{OrgId: 1, Uid: "team"}
+		require.NoError(t, bus.Dispatch(folder))
+		assert.True(t, folder.Result.IsFolder)
+
+		dash := &models.GetDashboardQuery{OrgId: 1, Uid: "dash"}
+		require.NoError(t, bus.Dispatch(dash))
+		assert.Equal(t, "Dashboard", dash.Result.Title)
+		assert.Equal(t, folder.Result.Id, dash.Result.FolderId)
+
+		subfolder := &models.GetDashboardQuery{OrgId: 1, Uid: "sub"}
+		require.NoError(t, bus.Dispatch(subfolder))
+		assert.Equal(t, folder.Result.Id, subfolder.Result.FolderId)
+
+		subdash := &models.GetDashboardQuery{OrgId: 1, Uid: "subdash"}
+		require.NoError(t, bus.Dispatch(subdash))
+		assert.Equal(t, subfolder.Result.Id, subdash.Result.FolderId)
+
+		ds := &models.GetDataSourceQuery{OrgId: 1, Uid: "prom"}
+		require.NoError(t, bus.Dispatch(ds))
+		assert.Equal(t, map[string]string{"password": "secret"}, ds.Result.SecureJsonData.Decrypt())
+		assert.Equal(t, "5s", ds.Result.JsonData.Get("timeInterval").MustString())
+
+		session := sqlStore.NewSession()
+		defer session.Close()
+
+		var element libraryelements.LibraryElement
+		exists, err := session.Table("library_element").Where("uid = ?", "panel").Get(&element)
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.Equal(t, folder.Result.Id, element.FolderID)
+		assert.Equal(t, int64(2), element.Version)
+
+		var connections []int64
+		require.NoError(t, session.Table("library_element_connection").Where("element_id = ?", element.ID).Cols("connection_id").Find(&connections))
+		assert.Equal(t, []int64{dash.Result.Id}, connections)
+
+		var rules []*ngmodels.AlertRule
+		require.NoError(t, session.Table("alert_rule").Asc("uid").Find(&rules))
+		require.Len(t, rules, 2)
+		assert.Equal(t, "recording", rules[0].UID)
+		assert.Equal(t, &ngmodels.Record{Metric: "two", From: "A"}, rules[0].Record)
+		assert.Equal(t, 10*time.Minute, rules[0].KeepFiringFor)
+		assert.True(t, rules[0].IsPaused)
+		assert.True(t, rules[0].RuleGroupPaused)
+		assert.Equal(t, 1, rules[0].RuleGroupIndex)
+		assert.True(t, rules[0].RuleGroupSequential)
+		assert.Equal(t, "rule", rules[1].UID)
+		assert.Equal(t, "team", rules[1].NamespaceUID)
+		assert.Equal(t, 5*time.Minute, rules[1].For)
+		assert.Nil(t, rules[1].Record)
+		assert.False(t, rules[1].IsPaused)
+		assert.Equal(t, int64(2), rules[1].Version)
+	})
+}
+
+func seedExportData(t *testing.T, sqlStore *sqlstore.SQLStore) {
+	t.Helper()
+
+	folder, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		IsFolder: true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "team",
+			"title": "Team",
+		}),
+	})
+	require.NoError(t, err)
+
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		FolderId: folder.Id,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "dash",
+			"title": "Dashboard",
+		}),
+	})
+	require.NoError(t, err)
+
+	subfolder, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		FolderId: folder.Id,
+		IsFolder: true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "sub",
+			"title": "Sub",
+		}),
+	})
+	require.NoError(t, err)
+
+	_, err = sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		FolderId: subfolder.Id,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "subdash",
+			"title": "Subfolder dashboard",
+		}),
+	})
+	require.NoError(t, err)
+
+	session := sqlStore.NewSession()
+	defer session.Close()
+
+	_, err = session.Insert(&models.DataSource{
+		OrgId:          1,
+		Type:           "prometheus",
+		Name:           "Prometheus",
+		Uid:            "prom",
+		Access:         models.DS_ACCESS_PROXY,
+		JsonData:       simplejson.NewFromAny(map[string]interface{}{"timeInterval": "5s"}),
+		SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{"password": "secret"}),
+		Created:        time.Now(),
+		Updated:        time.Now(),
+	})
+	require.NoError(t, err)
+
+	element := libraryelements.LibraryElement{
+		OrgID:    1,
+		FolderID: folder.Id,
+		UID:      "panel",
+		Name:     "Panel",
+		Kind:     int64(libraryelements.Panel),
+		Type:     "text",
+		Model:    []byte(`{"type": "text"}`),
+		Version:  1,
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}
+	_, err = session.Table("library_element").Insert(&element)
+	require.NoError(t, err)
+	_, err = session.Exec("INSERT INTO library_element_connection (element_id, kind, connection_id, created, created_by) "+
+		"VALUES (?, 1, ?, ?, 1)", element.ID, dash.Id, time.Now())
+	require.NoError(t, err)
+
+	_, err = session.Table("alert_rule").Insert(&ngmodels.AlertRule{
+		OrgID:        1,
+		UID:          "rule",
+		NamespaceUID: "team",
+		RuleGroup:    "group",
+		Title:        "Rule",
+		Condition:    "A",
+		Data: []ngmodels.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type": "math", "expression": "1 > 0"}`),
+		}},
+		IntervalSeconds: 60,
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+		For:             5 * time.Minute,
+		Version:         1,
+		Updated:         time.Now(),
+	})
+	require.NoError(t, err)
+	_, err = session.Table("alert_rule").Insert(&ngmodels.AlertRule{
+		OrgID:        1,
+		UID:          "recording",
+		NamespaceUID: "team",
+		RuleGroup:    "group",
+		Title:        "Recording",
+		Data: []ngmodels.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type": "math", "expression": "1 + 1"}`),
+		}},
+		IntervalSeconds:     60,
+		NoDataState:         ngmodels.NoData,
+		ExecErrState:        ngmodels.AlertingErrState,
+		KeepFiringFor:       10 * time.Minute,
+		Record:              &ngmodels.Record{Metric: "two", From: "A"},
+		IsPaused:            true,
+		RuleGroupPaused:     true,
+		RuleGroupIndex:      1,
+		RuleGroupSequential: true,
+		Version:             1,
+		Updated:             time.Now(),
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	"github.com/grafana/grafana/pkg/internal/bus"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// alertingBaseInterval is the interval of the alert rule scheduler, of which the intervals of the rule groups must
+// be a multiple.
+const alertingBaseInterval = 10 * time.Second
+
+// importAlertRulesCommand converts the alerting and recording rules of Prometheus or Loki rule files into Grafana
+// managed rules querying a data source, and saves them in a folder. The rule groups with the same names are replaced.
+func importAlertRulesCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("at least one rule file is required")
+	}
+	orgID := int64(c.Int("org-id"))
+
+	folder, err := sqlStore.GetFolderByTitle(orgID, c.String("folder"))
+	if err != nil {
+		return errutil.Wrapf(err, "failed to find folder %s", c.String("folder"))
+	}
+
+	dsQuery := &models.GetDataSourceQuery{Uid: c.String("datasource"), OrgId: orgID}
+	if err := bus.Dispatch(dsQuery); err != nil {
+		return errutil.Wrapf(err, "failed to find data source %s", c.String("datasource"))
+	}
+	converter, err := prom.NewConverter(dsQuery.Result)
+	if err != nil {
+		return err
+	}
+
+	// all the files are converted before saving any of them
+	var configs []apimodels.PostableRuleGroupConfig
+	for _, path := range files {
+		// It's safe to ignore gosec warning G304 since the rule files are given by the administrator
+		// nolint:gosec
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := prom.ParseRuleFile(data)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to parse %s", path)
+		}
+		converted, err := converter.ConvertRuleFile(file)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to convert %s", path)
+		}
+		configs = append(configs, converted...)
+	}
+
+	st := &store.DBstore{SQLStore: sqlStore, BaseInterval: alertingBaseInterval}
+	if _, err := prom.SaveRuleGroups(st, orgID, folder.Uid, configs); err != nil {
+		return errutil.Wrap("failed to save rule groups", err)
+	}
+
+	logger.Infof("%s Imported %d rule groups into folder %s\n", color.GreenString("✔"), len(configs), folder.Title)
+	return nil
+}
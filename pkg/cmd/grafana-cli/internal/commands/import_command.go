@@ -0,0 +1,639 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// folderKey identifies a dashboard folder of an export by organization and directory.
+type folderKey struct {
+	orgID int64
+	path  string
+}
+
+// folderUID identifies a dashboard folder of an export by organization and UID.
+type folderUID struct {
+	orgID int64
+	uid   string
+}
+
+// importCommand loads an export directory, created by exportCommand, into the database. Existing
+// folders, dashboards, datasources, library elements and alert rules with the same UID are updated.
+// Datasource secrets are encrypted with the secrets configuration of the target instance.
+func importCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	dir := c.Args().First()
+	if dir == "" {
+		return errors.New("export directory is required")
+	}
+	if _, err := os.Stat(filepath.Join(dir, exportFoldersFile)); err != nil {
+		return errutil.Wrapf(err, "%s is not an export directory", dir)
+	}
+
+	folderIDs, err := importFolders(sqlStore, dir)
+	if err != nil {
+		return errutil.Wrap("failed to import folders", err)
+	}
+	if err := importDashboards(sqlStore, dir, folderIDs); err != nil {
+		return errutil.Wrap("failed to import dashboards", err)
+	}
+	if err := importDatasources(dir); err != nil {
+		return errutil.Wrap("failed to import datasources", err)
+	}
+	if err := importLibraryElements(sqlStore, dir); err != nil {
+		return errutil.Wrap("failed to import library elements", err)
+	}
+	if err := importAlertRules(sqlStore, dir); err != nil {
+		return errutil.Wrap("failed to import alert rules", err)
+	}
+
+	logger.Infof("%s Imported from %s\n", color.GreenString("✔"), dir)
+	return nil
+}
+
+func importFolders(sqlStore *sqlstore.SQLStore, dir string) (map[folderKey]int64, error) {
+	// It's safe to ignore gosec warning G304 since the export directory is given by the administrator
+	// nolint:gosec
+	data, err := ioutil.ReadFile(filepath.Join(dir, exportFoldersFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []exportedFolder
+	if err := json.Unmarshal(data, &folders); err != nil {
+		return nil, errutil.Wrapf(err, "failed to parse %s", exportFoldersFile)
+	}
+
+	exported := map[folderUID]exportedFolder{}
+	for _, folder := range folders {
+		exported[folderUID{orgID: folder.OrgID, uid: folder.UID}] = folder
+	}
+
+	ids := map[folderUID]int64{}
+	// saveExportedFolder saves a folder after its parent folders.
+	var saveExportedFolder func(folder exportedFolder, visited map[folderUID]bool) (int64, error)
+	saveExportedFolder = func(folder exportedFolder, visited map[folderUID]bool) (int64, error) {
+		key := folderUID{orgID: folder.OrgID, uid: folder.UID}
+		if id, exists := ids[key]; exists {
+			return id, nil
+		}
+		visited[key] = true
+
+		var parentID int64
+		parentKey := folderUID{orgID: folder.OrgID, uid: folder.ParentUID}
+		if parent, exists := exported[parentKey]; exists && folder.ParentUID != "" && !visited[parentKey] {
+			var err error
+			if parentID, err = saveExportedFolder(parent, visited); err != nil {
+				return 0, err
+			}
+		}
+
+		id, err := saveFolder(sqlStore, folder.OrgID, parentID, folder.UID, folder.Title)
+		if err != nil {
+			return 0, errutil.Wrapf(err, "failed to save folder %s", folder.Title)
+		}
+		ids[key] = id
+		return id, nil
+	}
+
+	folderIDs := map[folderKey]int64{}
+	for _, folder := range folders {
+		id, err := saveExportedFolder(folder, map[folderUID]bool{})
+		if err != nil {
+			return nil, err
+		}
+		folderIDs[folderKey{orgID: folder.OrgID, path: filepath.FromSlash(folder.Path)}] = id
+	}
+
+	logger.Infof("%s Imported %d folders\n", color.GreenString("✔"), len(folders))
+	return folderIDs, nil
+}
+
+// saveFolder saves a folder into the folder with ID parentID, or at the top level if parentID is 0.
+func saveFolder(sqlStore *sqlstore.SQLStore, orgID, parentID int64, uid, title string) (int64, error) {
+	data := simplejson.New()
+	data.Set("title", title)
+	if uid != "" {
+		data.Set("uid", uid)
+	}
+
+	folder, err := saveDashboard(sqlStore, orgID, parentID, true, data)
+	if err != nil {
+		return 0, err
+	}
+	return folder.Id, nil
+}
+
+// saveDashboard saves a dashboard or folder, overwriting the existing one with the same UID or else with the same
+// title in the folder.
+func saveDashboard(sqlStore *sqlstore.SQLStore, orgID, folderID int64, isFolder bool, data *simplejson.Json) (*models.Dashboard, error) {
+	uid := data.Get("uid").MustString()
+	title := data.Get("title").MustString()
+
+	var existing models.Dashboard
+	var exists bool
+	err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var err error
+		if uid != "" {
+			if exists, err = sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&existing); err != nil || exists {
+				return err
+			}
+		}
+		exists, err = sess.Where("org_id = ? AND folder_id = ? AND title = ?", orgID, folderID, title).Get(&existing)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data.Del("id")
+	if exists {
+		if existing.IsFolder != isFolder {
+			return nil, models.ErrDashboardTypeMismatch
+		}
+		data.Set("id", existing.Id)
+		data.Set("version", existing.Version)
+	}
+
+	return sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		Dashboard: data,
+		OrgId:     orgID,
+		FolderId:  folderID,
+		IsFolder:  isFolder,
+		Overwrite: true,
+	})
+}
+
+// importDashboards saves the dashboards of every organization directory. Dashboards in a directory that isn't a
+// folder of folders.json are saved into a folder titled after the directory, as file provisioning does.
+func importDashboards(sqlStore *sqlstore.SQLStore, dir string, folderIDs map[folderKey]int64) error {
+	orgDirs, err := readOrgDirs(filepath.Join(dir, exportDashboardsDir))
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for orgID, orgDir := range orgDirs {
+		err := filepath.Walk(orgDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".json" {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+
+			folderID, err := dirFolderID(sqlStore, orgID, orgDir, filepath.Dir(path), rel, folderIDs)
+			if err != nil {
+				return err
+			}
+
+			// nolint:gosec
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			dashboard, err := simplejson.NewJson(data)
+			if err != nil {
+				return errutil.Wrapf(err, "failed to parse dashboard %s", path)
+			}
+
+			if _, err := saveDashboard(sqlStore, orgID, folderID, false, dashboard); err != nil {
+				return errutil.Wrapf(err, "failed to save dashboard %s", path)
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("%s Imported %d dashboards\n", color.GreenString("✔"), count)
+	return nil
+}
+
+// dirFolderID returns the ID of the folder of a dashboard directory, relative to the export directory as rel, or 0 for
+// the organization directory. The folders of directories that aren't in folders.json are saved, after their parents.
+func dirFolderID(sqlStore *sqlstore.SQLStore, orgID int64, orgDir, dir, rel string, folderIDs map[folderKey]int64) (int64, error) {
+	if dir == orgDir {
+		return 0, nil
+	}
+	key := folderKey{orgID: orgID, path: rel}
+	if id, exists := folderIDs[key]; exists {
+		return id, nil
+	}
+
+	parentID, err := dirFolderID(sqlStore, orgID, orgDir, filepath.Dir(dir), filepath.Dir(rel), folderIDs)
+	if err != nil {
+		return 0, err
+	}
+	id, err := saveFolder(sqlStore, orgID, parentID, "", filepath.Base(rel))
+	if err != nil {
+		return 0, errutil.Wrapf(err, "failed to save folder %s", filepath.Base(rel))
+	}
+	folderIDs[key] = id
+	return id, nil
+}
+
+// importDatasources adds or updates the datasources of datasources.yaml, matched by UID or else by name. The secrets
+// of existing datasources are kept if the export doesn't contain secrets.
+func importDatasources(dir string) error {
+	// nolint:gosec
+	data, err := ioutil.ReadFile(filepath.Join(dir, exportDatasourcesDir, exportDatasourcesFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var exported exportedDatasources
+	if err := yaml.Unmarshal(data, &exported); err != nil {
+		return errutil.Wrapf(err, "failed to parse %s", exportDatasourcesFile)
+	}
+
+	for _, ds := range exported.Datasources {
+		existing, err := findDatasource(ds)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to get datasource %s", ds.Name)
+		}
+
+		jsonData := simplejson.NewFromAny(ds.JSONData)
+		if existing == nil {
+			err = bus.Dispatch(&models.AddDataSourceCommand{
+				OrgId:             ds.OrgID,
+				Uid:               ds.UID,
+				Name:              ds.Name,
+				Type:              ds.Type,
+				Access:            models.DsAccess(ds.Access),
+				Url:               ds.URL,
+				User:              ds.User,
+				Password:          ds.Password,
+				Database:          ds.Database,
+				BasicAuth:         ds.BasicAuth,
+				BasicAuthUser:     ds.BasicAuthUser,
+				BasicAuthPassword: ds.BasicAuthPassword,
+				WithCredentials:   ds.WithCredentials,
+				IsDefault:         ds.IsDefault,
+				JsonData:          jsonData,
+				SecureJsonData:    ds.SecureJSONData,
+				ReadOnly:          !ds.Editable,
+			})
+		} else {
+			cmd := &models.UpdateDataSourceCommand{
+				Id:                existing.Id,
+				OrgId:             ds.OrgID,
+				Uid:               ds.UID,
+				Version:           existing.Version,
+				Name:              ds.Name,
+				Type:              ds.Type,
+				Access:            models.DsAccess(ds.Access),
+				Url:               ds.URL,
+				User:              ds.User,
+				Password:          ds.Password,
+				Database:          ds.Database,
+				BasicAuth:         ds.BasicAuth,
+				BasicAuthUser:     ds.BasicAuthUser,
+				BasicAuthPassword: ds.BasicAuthPassword,
+				WithCredentials:   ds.WithCredentials,
+				IsDefault:         ds.IsDefault,
+				JsonData:          jsonData,
+				SecureJsonData:    ds.SecureJSONData,
+				ReadOnly:          !ds.Editable,
+			}
+			if cmd.Uid == "" {
+				cmd.Uid = existing.Uid
+			}
+			if len(ds.SecureJSONData) == 0 {
+				cmd.SecureJsonData = existing.SecureJsonData.Decrypt()
+			}
+			if ds.Password == "" && ds.BasicAuthPassword == "" {
+				cmd.Password = existing.Password
+				cmd.BasicAuthPassword = existing.BasicAuthPassword
+			}
+			err = bus.Dispatch(cmd)
+		}
+		if err != nil {
+			return errutil.Wrapf(err, "failed to save datasource %s", ds.Name)
+		}
+	}
+
+	logger.Infof("%s Imported %d datasources\n", color.GreenString("✔"), len(exported.Datasources))
+	return nil
+}
+
+// findDatasource returns the datasource with the UID of an exported datasource or else with its name, if any.
+func findDatasource(ds exportedDatasource) (*models.DataSource, error) {
+	queries := []*models.GetDataSourceQuery{{OrgId: ds.OrgID, Name: ds.Name}}
+	if ds.UID != "" {
+		queries = append([]*models.GetDataSourceQuery{{OrgId: ds.OrgID, Uid: ds.UID}}, queries...)
+	}
+
+	for _, query := range queries {
+		err := bus.Dispatch(query)
+		if err == nil {
+			return query.Result, nil
+		}
+		if !errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func importLibraryElements(sqlStore *sqlstore.SQLStore, dir string) error {
+	var count int
+	err := walkOrgFiles(filepath.Join(dir, exportLibraryElementsDir), func(orgID int64, data []byte) error {
+		var exported exportedLibraryElement
+		if err := json.Unmarshal(data, &exported); err != nil {
+			return err
+		}
+
+		folderID, err := folderIDByUID(orgID, exported.FolderUID)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to find folder of library element %s", exported.Name)
+		}
+
+		err = sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			element := libraryelements.LibraryElement{
+				OrgID:       orgID,
+				FolderID:    folderID,
+				UID:         exported.UID,
+				Name:        exported.Name,
+				Kind:        exported.Kind,
+				Type:        exported.Type,
+				Description: exported.Description,
+				Model:       exported.Model,
+				Version:     1,
+				Created:     time.Now(),
+				Updated:     time.Now(),
+			}
+
+			var existing libraryelements.LibraryElement
+			exists, err := sess.Table("library_element").Where("org_id = ? AND uid = ?", orgID, exported.UID).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if exists {
+				element.ID = existing.ID
+				element.Version = existing.Version + 1
+				element.Created = existing.Created
+				element.CreatedBy = existing.CreatedBy
+				if _, err := sess.Table("library_element").ID(existing.ID).AllCols().Update(&element); err != nil {
+					return err
+				}
+			} else if _, err := sess.Table("library_element").Insert(&element); err != nil {
+				return err
+			}
+
+			return importLibraryElementConnections(sess, orgID, element.ID, exported.Connections)
+		})
+		if err != nil {
+			return errutil.Wrapf(err, "failed to save library element %s", exported.Name)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%s Imported %d library elements\n", color.GreenString("✔"), count)
+	return nil
+}
+
+// importLibraryElementConnections connects a library element to the dashboards with the given UIDs. Dashboards that
+// don't exist and existing connections are skipped.
+func importLibraryElementConnections(sess *sqlstore.DBSession, orgID, elementID int64, dashboardUIDs []string) error {
+	for _, uid := range dashboardUIDs {
+		var dashboard models.Dashboard
+		exists, err := sess.Where("org_id = ? AND uid = ? AND is_folder = ?", orgID, uid, false).Get(&dashboard)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			logger.Warnf("Skipping connection of library element to missing dashboard %s\n", uid)
+			continue
+		}
+
+		exists, err = sess.Table("library_element_connection").
+			Where("element_id = ? AND kind = 1 AND connection_id = ?", elementID, dashboard.Id).Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		_, err = sess.Exec("INSERT INTO library_element_connection (element_id, kind, connection_id, created, created_by) "+
+			"VALUES (?, 1, ?, ?, 0)", elementID, dashboard.Id, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importAlertRules(sqlStore *sqlstore.SQLStore, dir string) error {
+	var count int
+	err := walkOrgFiles(filepath.Join(dir, exportAlertRulesDir), func(orgID int64, data []byte) error {
+		var exported exportedAlertRule
+		if err := json.Unmarshal(data, &exported); err != nil {
+			return err
+		}
+
+		if _, err := folderIDByUID(orgID, exported.NamespaceUID); err != nil {
+			return errutil.Wrapf(err, "failed to find folder of alert rule %s", exported.Title)
+		}
+
+		forDuration, err := time.ParseDuration(exported.For)
+		if err != nil {
+			return errutil.Wrapf(err, "invalid for duration of alert rule %s", exported.Title)
+		}
+
+		var keepFiringFor time.Duration
+		if exported.KeepFiringFor != "" {
+			keepFiringFor, err = time.ParseDuration(exported.KeepFiringFor)
+			if err != nil {
+				return errutil.Wrapf(err, "invalid keep firing for duration of alert rule %s", exported.Title)
+			}
+		}
+
+		rule := ngmodels.AlertRule{
+			OrgID:               orgID,
+			UID:                 exported.UID,
+			NamespaceUID:        exported.NamespaceUID,
+			RuleGroup:           exported.RuleGroup,
+			RuleGroupIndex:      exported.RuleGroupIndex,
+			RuleGroupPaused:     exported.RuleGroupPaused,
+			RuleGroupSequential: exported.RuleGroupSequential,
+			Title:               exported.Title,
+			Condition:           exported.Condition,
+			Data:                exported.Data,
+			IntervalSeconds:     exported.IntervalSeconds,
+			NoDataState:         exported.NoDataState,
+			ExecErrState:        exported.ExecErrState,
+			For:                 forDuration,
+			KeepFiringFor:       keepFiringFor,
+			Annotations:         exported.Annotations,
+			Labels:              exported.Labels,
+			Record:              exported.Record,
+			IsPaused:            exported.IsPaused,
+			Version:             1,
+		}
+		if err := rule.PreSave(time.Now); err != nil {
+			return errutil.Wrapf(err, "invalid alert rule %s", exported.Title)
+		}
+
+		err = sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			var existing ngmodels.AlertRule
+			exists, err := sess.Table("alert_rule").Where("org_id = ? AND uid = ?", orgID, exported.UID).Get(&existing)
+			if err != nil {
+				return err
+			}
+
+			var parentVersion int64
+			if exists {
+				rule.ID = existing.ID
+				rule.Version = existing.Version + 1
+				parentVersion = existing.Version
+				if _, err := sess.Table("alert_rule").ID(existing.ID).AllCols().Update(&rule); err != nil {
+					return err
+				}
+			} else if _, err := sess.Table("alert_rule").Insert(&rule); err != nil {
+				return err
+			}
+
+			_, err = sess.Insert(&ngmodels.AlertRuleVersion{
+				RuleOrgID:           rule.OrgID,
+				RuleUID:             rule.UID,
+				RuleNamespaceUID:    rule.NamespaceUID,
+				RuleGroup:           rule.RuleGroup,
+				ParentVersion:       parentVersion,
+				Version:             rule.Version,
+				Created:             rule.Updated,
+				Condition:           rule.Condition,
+				Title:               rule.Title,
+				Data:                rule.Data,
+				IntervalSeconds:     rule.IntervalSeconds,
+				NoDataState:         rule.NoDataState,
+				ExecErrState:        rule.ExecErrState,
+				For:                 rule.For,
+				KeepFiringFor:       rule.KeepFiringFor,
+				Annotations:         rule.Annotations,
+				Labels:              rule.Labels,
+				Record:              rule.Record,
+				IsPaused:            rule.IsPaused,
+				RuleGroupPaused:     rule.RuleGroupPaused,
+				RuleGroupIndex:      rule.RuleGroupIndex,
+				RuleGroupSequential: rule.RuleGroupSequential,
+			})
+			return err
+		})
+		if err != nil {
+			return errutil.Wrapf(err, "failed to save alert rule %s", exported.Title)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%s Imported %d alert rules\n", color.GreenString("✔"), count)
+	return nil
+}
+
+// folderIDByUID returns the ID of a folder, or 0 for the General folder if uid is empty.
+func folderIDByUID(orgID int64, uid string) (int64, error) {
+	if uid == "" {
+		return 0, nil
+	}
+
+	query := &models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := bus.Dispatch(query); err != nil {
+		return 0, err
+	}
+	if !query.Result.IsFolder {
+		return 0, models.ErrFolderNotFound
+	}
+	return query.Result.Id, nil
+}
+
+// readOrgDirs returns the organization directories within dir by organization ID.
+func readOrgDirs(dir string) (map[int64]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	orgDirs := map[int64]string{}
+	for _, f := range files {
+		if !f.IsDir() || !strings.HasPrefix(f.Name(), exportOrgDirPrefix) {
+			continue
+		}
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(f.Name(), exportOrgDirPrefix), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid organization directory %s", filepath.Join(dir, f.Name()))
+		}
+		orgDirs[orgID] = filepath.Join(dir, f.Name())
+	}
+	return orgDirs, nil
+}
+
+// walkOrgFiles calls fn with the content of every JSON file within the organization directories of dir.
+func walkOrgFiles(dir string, fn func(orgID int64, data []byte) error) error {
+	orgDirs, err := readOrgDirs(dir)
+	if err != nil {
+		return err
+	}
+
+	for orgID, orgDir := range orgDirs {
+		files, err := ioutil.ReadDir(orgDir)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+				continue
+			}
+
+			// nolint:gosec
+			data, err := ioutil.ReadFile(filepath.Join(orgDir, f.Name()))
+			if err != nil {
+				return err
+			}
+			if err := fn(orgID, data); err != nil {
+				return errutil.Wrapf(err, "failed to import %s", filepath.Join(orgDir, f.Name()))
+			}
+		}
+	}
+	return nil
+}
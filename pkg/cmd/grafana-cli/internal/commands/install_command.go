@@ -26,7 +26,7 @@ import (
 
 func validateInput(c utils.CommandLine, pluginFolder string) error {
 	arg := c.Args().First()
-	if arg == "" {
+	if arg == "" && c.String("from-bundle") == "" {
 		return errors.New("please specify plugin to install")
 	}
 
@@ -61,6 +61,9 @@ func (cmd Command) installCommand(c utils.CommandLine) error {
 	skipTLSVerify := c.Bool("insecure")
 
 	i := installer.New(skipTLSVerify, services.GrafanaVersion, services.Logger)
+	if bundlePath := c.String("from-bundle"); bundlePath != "" {
+		return i.InstallFromBundle(context.Background(), bundlePath, c.PluginDirectory())
+	}
 	return i.Install(context.Background(), pluginID, version, c.PluginDirectory(), c.PluginURL(), c.PluginRepoURL())
 }
 
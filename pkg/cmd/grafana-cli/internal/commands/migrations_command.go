@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/internal/utils"
+	logger "github.com/grafana/grafana/pkg/internal/infra/clilog"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore/migrator"
+)
+
+// migrationsStatusCommand lists the applied and pending migrations of the configured database, with the SQL of the
+// pending migrations if --sql is set.
+func migrationsStatusCommand(c utils.CommandLine, mg *migrator.Migrator) error {
+	statuses, err := mg.Status()
+	if err != nil {
+		return err
+	}
+
+	var pending int
+	for _, status := range statuses {
+		printMigrationStatus(status)
+		if status.State == migrator.MigrationApplied {
+			continue
+		}
+
+		pending++
+		if c.Bool("sql") {
+			logger.Infof("%s\n\n", status.SQL)
+		}
+	}
+
+	logger.Infof("\n%d migrations applied, %d pending for %s\n", len(statuses)-pending, pending, mg.Dialect.DriverName())
+	return nil
+}
+
+// migrationsDryRunCommand runs the pending migrations of the configured database in a transaction that is rolled back.
+func migrationsDryRunCommand(c utils.CommandLine, mg *migrator.Migrator) error {
+	statuses, err := mg.DryRun()
+	if err != nil {
+		return err
+	}
+
+	var ran int
+	for _, status := range statuses {
+		if status.State == migrator.MigrationApplied {
+			continue
+		}
+
+		printMigrationStatus(status)
+		if status.State == migrator.MigrationFailed {
+			return fmt.Errorf("migration %s failed, the dry run was rolled back: %s", status.ID, status.Error)
+		}
+		ran++
+	}
+
+	logger.Infof("\n%s Ran %d pending migrations and rolled them back\n", color.GreenString("✔"), ran)
+	return nil
+}
+
+func printMigrationStatus(status migrator.MigrationStatus) {
+	switch status.State {
+	case migrator.MigrationApplied:
+		logger.Infof("%s %-8s %s (%s)\n", color.GreenString("✔"), status.State, status.ID,
+			status.Timestamp.Format("2006-01-02 15:04:05"))
+	case migrator.MigrationFailed:
+		logger.Infof("%s %-8s %s: %s\n", color.RedString("✗"), status.State, status.ID, status.Error)
+	default:
+		logger.Infof("%s %-8s %s\n", color.YellowString("•"), status.State, status.ID)
+	}
+}
@@ -314,6 +314,7 @@ func (hs *HTTPServer) registerRoutes() {
 				folderUidRoute.Get("/", routing.Wrap(hs.GetFolderByUID))
 				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", routing.Wrap(hs.DeleteFolder))
+				folderUidRoute.Post("/move", bind(models.MoveFolderCommand{}), routing.Wrap(hs.MoveFolder))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", routing.Wrap(hs.GetFolderPermissionList))
@@ -342,6 +343,8 @@ func (hs *HTTPServer) registerRoutes() {
 				dashIdRoute.Get("/versions", routing.Wrap(GetDashboardVersions))
 				dashIdRoute.Get("/versions/:id", routing.Wrap(GetDashboardVersion))
 				dashIdRoute.Post("/restore", bind(dtos.RestoreDashboardVersionCommand{}), routing.Wrap(hs.RestoreDashboardVersion))
+				dashIdRoute.Post("/merge", bind(dtos.MergeDashboardCommand{}), routing.Wrap(MergeDashboard))
+				dashIdRoute.Post("/merge/save", bind(dtos.MergeDashboardCommand{}), routing.Wrap(hs.SaveMergedDashboard))
 
 				dashIdRoute.Group("/permissions", func(dashboardPermissionRoute routing.RouteRegister) {
 					dashboardPermissionRoute.Get("/", routing.Wrap(hs.GetDashboardPermissionList))
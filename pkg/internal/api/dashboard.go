@@ -52,7 +52,7 @@ func (hs *HTTPServer) TrimDashboard(c *models.ReqContext, cmd models.TrimDashboa
 	meta := cmd.Meta
 
 	trimedResult := *dash
-	if !hs.LoadSchemaService.IsDisabled() {
+	if hs.Cfg.IsTrimDefaultsEnabled() {
 		trimedResult, err = hs.LoadSchemaService.DashboardTrimDefaults(*dash)
 		if err != nil {
 			return response.Error(500, "Error while trim default value from dashboard json", err)
@@ -176,8 +176,14 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		return response.Error(500, "Error while loading library panels", err)
 	}
 
+	// load library variables JSON for this dashboard
+	err = hs.LibraryVariableService.LoadLibraryVariablesForDashboard(c, dash)
+	if err != nil {
+		return response.Error(500, "Error while loading library variables", err)
+	}
+
 	var trimedJson simplejson.Json
-	if trimDefaults && !hs.LoadSchemaService.IsDisabled() {
+	if trimDefaults && hs.Cfg.IsTrimDefaultsEnabled() {
 		trimedJson, err = hs.LoadSchemaService.DashboardTrimDefaults(*dash.Data)
 		if err != nil {
 			return response.Error(500, "Error while trim default value from dashboard json", err)
@@ -286,7 +292,7 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.UserId
 	trimDefaults := c.QueryBoolWithDefault("trimdefaults", false)
-	if trimDefaults && !hs.LoadSchemaService.IsDisabled() {
+	if trimDefaults && hs.Cfg.IsTrimDefaultsEnabled() {
 		cmd.Dashboard, err = hs.LoadSchemaService.DashboardApplyDefaults(cmd.Dashboard)
 		if err != nil {
 			return response.Error(500, "Error while applying default value to the dashboard json", err)
@@ -321,6 +327,12 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return response.Error(500, "Error while cleaning library panels", err)
 	}
 
+	// clean up all unnecessary library variables JSON properties so we store a minimum JSON
+	err = hs.LibraryVariableService.CleanLibraryVariablesForDashboard(dash)
+	if err != nil {
+		return response.Error(500, "Error while cleaning library variables", err)
+	}
+
 	dashItem := &dashboards.SaveDashboardDTO{
 		Dashboard: dash,
 		Message:   cmd.Message,
@@ -374,6 +386,12 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return response.Error(500, "Error while connecting library panels", err)
 	}
 
+	// connect library variables for this dashboard after the dashboard is stored and has an ID
+	err = hs.LibraryVariableService.ConnectLibraryVariablesForDashboard(c, dashboard)
+	if err != nil {
+		return response.Error(500, "Error while connecting library variables", err)
+	}
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(200, util.DynMap{
 		"status":  "success",
@@ -406,6 +424,15 @@ func (hs *HTTPServer) dashboardSaveErrorToApiResponse(err error) response.Respon
 		return response.Error(422, validationErr.Error(), nil)
 	}
 
+	var schemaErr models.DashboardSchemaError
+	if ok := errors.As(err, &schemaErr); ok {
+		return response.JSON(400, util.DynMap{
+			"status":  "schema-validation-failed",
+			"message": "Dashboard is not valid with respect to the dashboard schema",
+			"errors":  schemaErr.Errors,
+		})
+	}
+
 	var pluginErr models.UpdatePluginDashboardError
 	if ok := errors.As(err, &pluginErr); ok {
 		message := fmt.Sprintf("The dashboard belongs to plugin %s.", pluginErr.PluginId)
@@ -657,6 +684,79 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext, apiCmd dtos.
 	return hs.PostDashboard(c, saveCmd)
 }
 
+// MergeDashboard merges the changes of a dashboard, based on an older version, with the latest version
+// and returns the merged dashboard along with the conflicting changes.
+func MergeDashboard(c *models.ReqContext, apiCmd dtos.MergeDashboardCommand) response.Response {
+	dash, result, rsp := mergeDashboardHelper(c, apiCmd)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(200, util.DynMap{
+		"merged":    result.Merged,
+		"conflicts": result.Conflicts,
+		"version":   dash.Version,
+	})
+}
+
+// SaveMergedDashboard merges the changes of a dashboard with the latest version like MergeDashboard
+// and saves the merged dashboard, unless some conflicts are left unresolved.
+func (hs *HTTPServer) SaveMergedDashboard(c *models.ReqContext, apiCmd dtos.MergeDashboardCommand) response.Response {
+	dash, result, rsp := mergeDashboardHelper(c, apiCmd)
+	if rsp != nil {
+		return rsp
+	}
+
+	if len(result.Conflicts) > 0 {
+		return response.JSON(409, util.DynMap{
+			"status":    "merge-conflict",
+			"message":   "The dashboard has unresolved conflicts",
+			"conflicts": result.Conflicts,
+			"version":   dash.Version,
+		})
+	}
+
+	saveCmd := models.SaveDashboardCommand{}
+	saveCmd.OrgId = c.OrgId
+	saveCmd.UserId = c.UserId
+	saveCmd.Dashboard = result.Merged
+	saveCmd.Message = apiCmd.Message
+	saveCmd.FolderId = result.FolderId
+
+	return hs.PostDashboard(c, saveCmd)
+}
+
+func mergeDashboardHelper(c *models.ReqContext, apiCmd dtos.MergeDashboardCommand) (*models.Dashboard,
+	*dashdiffs.MergeResult, response.Response) {
+	dash, rsp := getDashboardHelper(c.OrgId, "", c.ParamsInt64(":dashboardId"), "")
+	if rsp != nil {
+		return nil, nil, rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return nil, nil, dashboardGuardianResponse(err)
+	}
+
+	result, err := dashdiffs.MergeWithLatest(&dashdiffs.MergeOptions{
+		OrgId:       c.OrgId,
+		DashboardId: dash.Id,
+		Local:       apiCmd.Dashboard,
+		Resolutions: apiCmd.Resolutions,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardVersionNotFound) {
+			return nil, nil, response.Error(404, "Dashboard version not found", err)
+		}
+		if errors.Is(err, dashdiffs.ErrUnknownResolution) {
+			return nil, nil, response.Error(400, err.Error(), nil)
+		}
+		return nil, nil, response.Error(500, "Unable to merge dashboard", err)
+	}
+
+	return dash, result, nil
+}
+
 func GetDashboardTags(c *models.ReqContext) {
 	query := models.GetDashboardTagsQuery{OrgId: c.OrgId}
 	err := bus.Dispatch(&query)
@@ -174,9 +174,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					state := setUp()
 
 					callDeleteDashboardBySlug(sc, &HTTPServer{
-						Cfg:                   setting.NewCfg(),
-						LibraryPanelService:   &mockLibraryPanelService{},
-						LibraryElementService: &mockLibraryElementService{},
+						Cfg:                    setting.NewCfg(),
+						LibraryPanelService:    &mockLibraryPanelService{},
+						LibraryVariableService: &mockLibraryVariableService{},
+						LibraryElementService:  &mockLibraryElementService{},
 					})
 					assert.Equal(t, 403, sc.resp.Code)
 
@@ -188,9 +189,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					state := setUp()
 
 					callDeleteDashboardBySlug(sc, &HTTPServer{
-						Cfg:                   setting.NewCfg(),
-						LibraryPanelService:   &mockLibraryPanelService{},
-						LibraryElementService: &mockLibraryElementService{},
+						Cfg:                    setting.NewCfg(),
+						LibraryPanelService:    &mockLibraryPanelService{},
+						LibraryVariableService: &mockLibraryVariableService{},
+						LibraryElementService:  &mockLibraryElementService{},
 					})
 					assert.Equal(t, 403, sc.resp.Code)
 
@@ -247,9 +249,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					state := setUp()
 
 					callDeleteDashboardBySlug(sc, &HTTPServer{
-						Cfg:                   setting.NewCfg(),
-						LibraryPanelService:   &mockLibraryPanelService{},
-						LibraryElementService: &mockLibraryElementService{},
+						Cfg:                    setting.NewCfg(),
+						LibraryPanelService:    &mockLibraryPanelService{},
+						LibraryVariableService: &mockLibraryVariableService{},
+						LibraryElementService:  &mockLibraryElementService{},
 					})
 					assert.Equal(t, 200, sc.resp.Code)
 					assert.Equal(t, "child-dash", state.dashQueries[0].Slug)
@@ -260,9 +263,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					state := setUp()
 
 					callDeleteDashboardBySlug(sc, &HTTPServer{
-						Cfg:                   setting.NewCfg(),
-						LibraryPanelService:   &mockLibraryPanelService{},
-						LibraryElementService: &mockLibraryElementService{},
+						Cfg:                    setting.NewCfg(),
+						LibraryPanelService:    &mockLibraryPanelService{},
+						LibraryVariableService: &mockLibraryVariableService{},
+						LibraryElementService:  &mockLibraryElementService{},
 					})
 					assert.Equal(t, 200, sc.resp.Code)
 					assert.Equal(t, "abcdefghi", state.dashQueries[0].Uid)
@@ -288,10 +292,11 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 	t.Run("Given a dashboard with a parent folder which has an ACL", func(t *testing.T) {
 		hs := &HTTPServer{
-			Cfg:                   setting.NewCfg(),
-			Live:                  newTestLive(t),
-			LibraryPanelService:   &mockLibraryPanelService{},
-			LibraryElementService: &mockLibraryElementService{},
+			Cfg:                    setting.NewCfg(),
+			Live:                   newTestLive(t),
+			LibraryPanelService:    &mockLibraryPanelService{},
+			LibraryVariableService: &mockLibraryVariableService{},
+			LibraryElementService:  &mockLibraryElementService{},
 		}
 
 		setUp := func() *testState {
@@ -833,6 +838,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				{SaveError: models.ErrDashboardTitleEmpty, ExpectedStatusCode: 400},
 				{SaveError: models.ErrDashboardFolderCannotHaveParent, ExpectedStatusCode: 400},
 				{SaveError: alerting.ValidationError{Reason: "Mu"}, ExpectedStatusCode: 422},
+				{SaveError: models.DashboardSchemaError{Errors: []models.DashboardSchemaErrorItem{{Path: "graphTooltip", Message: "invalid value"}}}, ExpectedStatusCode: 400},
 				{SaveError: models.ErrDashboardFailedGenerateUniqueUid, ExpectedStatusCode: 500},
 				{SaveError: models.ErrDashboardTypeMismatch, ExpectedStatusCode: 400},
 				{SaveError: models.ErrDashboardFolderWithSameNameAsDashboard, ExpectedStatusCode: 400},
@@ -1017,6 +1023,98 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			})
 	})
 
+	t.Run("Given dashboard changed since the version being edited", func(t *testing.T) {
+		setUp := func() {
+			fakeDash := models.NewDashboard("Dash")
+			fakeDash.Id = 2
+			fakeDash.Uid = "uid"
+			fakeDash.Version = 3
+			fakeDash.HasAcl = false
+			fakeDash.Data.Set("description", "remote")
+
+			queries := 0
+			bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+				// the dashboard is moved to another folder while merging
+				movedDash := *fakeDash
+				if queries > 0 {
+					movedDash.FolderId = 5
+				}
+				queries++
+				query.Result = &movedDash
+				return nil
+			})
+
+			bus.AddHandler("test", func(query *models.GetDashboardVersionQuery) error {
+				data := models.NewDashboard("Dash").Data
+				data.Set("description", "base")
+				query.Result = &models.DashboardVersion{
+					DashboardId: 2,
+					Version:     2,
+					Data:        data,
+				}
+				return nil
+			})
+		}
+
+		localDashboard := func(description string) *simplejson.Json {
+			dash := models.NewDashboard("Local dash").Data
+			dash.Set("version", 2)
+			dash.Set("description", description)
+			return dash
+		}
+
+		mock := &dashboards.FakeDashboardService{
+			SaveDashboardResult: &models.Dashboard{
+				Id:      2,
+				Uid:     "uid",
+				Title:   "Local dash",
+				Slug:    "local-dash",
+				Version: 4,
+			},
+		}
+
+		cmd := dtos.MergeDashboardCommand{Dashboard: localDashboard("base"), Message: "Merged"}
+		saveMergedDashboardScenario(t, "When calling POST on", "/api/dashboards/id/2/merge/save",
+			"/api/dashboards/id/:dashboardId/merge/save", mock, cmd, func(sc *scenarioContext) {
+				setUp()
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 200, sc.resp.Code)
+				dto := mock.SavedDashboards[0]
+				assert.Equal(t, "Local dash", dto.Dashboard.Title)
+				assert.Equal(t, "remote", dto.Dashboard.Data.Get("description").MustString())
+				assert.Equal(t, 3, dto.Dashboard.Version)
+				assert.Equal(t, int64(5), dto.Dashboard.FolderId)
+				assert.Equal(t, "Merged", dto.Message)
+			})
+
+		cmd = dtos.MergeDashboardCommand{Dashboard: localDashboard("local")}
+		saveMergedDashboardScenario(t, "When calling POST with conflicts on", "/api/dashboards/id/2/merge/save",
+			"/api/dashboards/id/:dashboardId/merge/save", mock, cmd, func(sc *scenarioContext) {
+				setUp()
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 409, sc.resp.Code)
+				result := sc.ToJSON()
+				assert.Equal(t, "merge-conflict", result.Get("status").MustString())
+				assert.Equal(t, "description", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+			})
+
+		cmd = dtos.MergeDashboardCommand{
+			Dashboard:   localDashboard("local"),
+			Resolutions: map[string]string{"description": "local"},
+		}
+		saveMergedDashboardScenario(t, "When calling POST with resolved conflicts on", "/api/dashboards/id/2/merge/save",
+			"/api/dashboards/id/:dashboardId/merge/save", mock, cmd, func(sc *scenarioContext) {
+				setUp()
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 200, sc.resp.Code)
+				dto := mock.SavedDashboards[len(mock.SavedDashboards)-1]
+				assert.Equal(t, "local", dto.Dashboard.Data.Get("description").MustString())
+			})
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		setUp := func() {
 			bus.AddHandler("test", func(query *models.GetDashboardsBySlugQuery) error {
@@ -1051,9 +1149,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				setUp()
 
 				callDeleteDashboardBySlug(sc, &HTTPServer{
-					Cfg:                   setting.NewCfg(),
-					LibraryPanelService:   &mockLibraryPanelService{},
-					LibraryElementService: &mockLibraryElementService{},
+					Cfg:                    setting.NewCfg(),
+					LibraryPanelService:    &mockLibraryPanelService{},
+					LibraryVariableService: &mockLibraryVariableService{},
+					LibraryElementService:  &mockLibraryElementService{},
 				})
 
 				assert.Equal(t, 400, sc.resp.Code)
@@ -1065,9 +1164,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			setUp()
 
 			callDeleteDashboardBySlug(sc, &HTTPServer{
-				Cfg:                   setting.NewCfg(),
-				LibraryPanelService:   &mockLibraryPanelService{},
-				LibraryElementService: &mockLibraryElementService{},
+				Cfg:                    setting.NewCfg(),
+				LibraryPanelService:    &mockLibraryPanelService{},
+				LibraryVariableService: &mockLibraryVariableService{},
+				LibraryElementService:  &mockLibraryElementService{},
 			})
 
 			assert.Equal(t, 400, sc.resp.Code)
@@ -1100,10 +1200,11 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}
 
 			hs := &HTTPServer{
-				Cfg:                   setting.NewCfg(),
-				ProvisioningService:   mock,
-				LibraryPanelService:   &mockLibraryPanelService{},
-				LibraryElementService: &mockLibraryElementService{},
+				Cfg:                    setting.NewCfg(),
+				ProvisioningService:    mock,
+				LibraryPanelService:    &mockLibraryPanelService{},
+				LibraryVariableService: &mockLibraryVariableService{},
+				LibraryElementService:  &mockLibraryElementService{},
 			}
 			callGetDashboard(sc, hs)
 
@@ -1128,10 +1229,11 @@ func getDashboardShouldReturn200WithConfig(sc *scenarioContext, provisioningServ
 	libraryElementsService := mockLibraryElementService{}
 
 	hs := &HTTPServer{
-		Cfg:                   setting.NewCfg(),
-		LibraryPanelService:   &libraryPanelsService,
-		LibraryElementService: &libraryElementsService,
-		ProvisioningService:   provisioningService,
+		Cfg:                    setting.NewCfg(),
+		LibraryPanelService:    &libraryPanelsService,
+		LibraryVariableService: &mockLibraryVariableService{},
+		LibraryElementService:  &libraryElementsService,
+		ProvisioningService:    provisioningService,
 	}
 
 	callGetDashboard(sc, hs)
@@ -1220,9 +1322,10 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 			QuotaService: &quota.QuotaService{
 				Cfg: cfg,
 			},
-			PluginManager:         &fakePluginManager{},
-			LibraryPanelService:   &mockLibraryPanelService{},
-			LibraryElementService: &mockLibraryElementService{},
+			PluginManager:          &fakePluginManager{},
+			LibraryPanelService:    &mockLibraryPanelService{},
+			LibraryVariableService: &mockLibraryVariableService{},
+			LibraryElementService:  &mockLibraryElementService{},
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -1279,13 +1382,14 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 
 		cfg := setting.NewCfg()
 		hs := HTTPServer{
-			Cfg:                   cfg,
-			Bus:                   bus.GetBus(),
-			ProvisioningService:   provisioning.NewProvisioningServiceMock(),
-			Live:                  newTestLive(t),
-			QuotaService:          &quota.QuotaService{Cfg: cfg},
-			LibraryPanelService:   &mockLibraryPanelService{},
-			LibraryElementService: &mockLibraryElementService{},
+			Cfg:                    cfg,
+			Bus:                    bus.GetBus(),
+			ProvisioningService:    provisioning.NewProvisioningServiceMock(),
+			Live:                   newTestLive(t),
+			QuotaService:           &quota.QuotaService{Cfg: cfg},
+			LibraryPanelService:    &mockLibraryPanelService{},
+			LibraryVariableService: &mockLibraryVariableService{},
+			LibraryElementService:  &mockLibraryElementService{},
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -1348,6 +1452,21 @@ func (m *mockLibraryPanelService) ConnectLibraryPanelsForDashboard(c *models.Req
 	return nil
 }
 
+type mockLibraryVariableService struct {
+}
+
+func (m *mockLibraryVariableService) LoadLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error {
+	return nil
+}
+
+func (m *mockLibraryVariableService) CleanLibraryVariablesForDashboard(dash *models.Dashboard) error {
+	return nil
+}
+
+func (m *mockLibraryVariableService) ConnectLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error {
+	return nil
+}
+
 type mockLibraryElementService struct {
 }
 
@@ -1365,6 +1484,11 @@ func (l *mockLibraryElementService) ConnectElementsToDashboard(c *models.ReqCont
 	return nil
 }
 
+// ConnectElementsOfKindToDashboard connects elements of a specific kind to a specific dashboard.
+func (l *mockLibraryElementService) ConnectElementsOfKindToDashboard(c *models.ReqContext, kind libraryelements.LibraryElementKind, elementUIDs []string, dashboardID int64) error {
+	return nil
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c *models.ReqContext, dashboardID int64) error {
 	return nil
@@ -1374,3 +1498,49 @@ func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c *models.Re
 func (l *mockLibraryElementService) DeleteLibraryElementsInFolder(c *models.ReqContext, folderUID string) error {
 	return nil
 }
+
+func saveMergedDashboardScenario(t *testing.T, desc string, url string, routePattern string,
+	mock *dashboards.FakeDashboardService, cmd dtos.MergeDashboardCommand, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		defer bus.ClearBusHandlers()
+
+		cfg := setting.NewCfg()
+		hs := HTTPServer{
+			Cfg:                    cfg,
+			Bus:                    bus.GetBus(),
+			ProvisioningService:    provisioning.NewProvisioningServiceMock(),
+			Live:                   newTestLive(t),
+			QuotaService:           &quota.QuotaService{Cfg: cfg},
+			LibraryPanelService:    &mockLibraryPanelService{},
+			LibraryVariableService: &mockLibraryVariableService{},
+			LibraryElementService:  &mockLibraryElementService{},
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{
+				OrgId:  testOrgID,
+				UserId: testUserID,
+			}
+			sc.context.OrgRole = models.ROLE_ADMIN
+
+			return hs.SaveMergedDashboard(c, cmd)
+		})
+
+		origProvisioningService := dashboards.NewProvisioningService
+		origNewDashboardService := dashboards.NewService
+		t.Cleanup(func() {
+			dashboards.NewService = origNewDashboardService
+			dashboards.NewProvisioningService = origProvisioningService
+		})
+		dashboards.NewProvisioningService = func(dboards.Store) dashboards.DashboardProvisioningService {
+			return mockDashboardProvisioningService{}
+		}
+		dashboards.MockDashboardService(mock)
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
@@ -61,3 +61,9 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+type MergeDashboardCommand struct {
+	Dashboard   *simplejson.Json  `json:"dashboard" binding:"Required"`
+	Resolutions map[string]string `json:"resolutions"`
+	Message     string            `json:"message"`
+}
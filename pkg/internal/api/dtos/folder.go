@@ -16,10 +16,14 @@ type Folder struct {
 	UpdatedBy string    `json:"updatedBy"`
 	Updated   time.Time `json:"updated"`
 	Version   int       `json:"version"`
+	ParentId  int64     `json:"parentId,omitempty"`
+	// Parents are the folders above the folder the user can view, starting with the top level folder.
+	Parents []FolderSearchHit `json:"parents,omitempty"`
 }
 
 type FolderSearchHit struct {
-	Id    int64  `json:"id"`
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Id       int64  `json:"id"`
+	Uid      string `json:"uid"`
+	Title    string `json:"title"`
+	ParentId int64  `json:"parentId,omitempty"`
 }
@@ -3,6 +3,7 @@ package dtos
 import (
 	"github.com/grafana/grafana/pkg/internal/components/simplejson"
 	"github.com/grafana/grafana/pkg/internal/plugins"
+	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin"
 )
 
 type PluginSetting struct {
@@ -25,6 +26,10 @@ type PluginSetting struct {
 	Signature     plugins.PluginSignatureStatus `json:"signature"`
 	SignatureType plugins.PluginSignatureType   `json:"signatureType"`
 	SignatureOrg  string                        `json:"signatureOrg"`
+	SignatureKey  string                        `json:"signatureKey"`
+
+	// Process is the status of the process of a started backend plugin.
+	Process *backendplugin.ProcessStatus `json:"process,omitempty"`
 }
 
 type PluginListItem struct {
@@ -42,6 +47,7 @@ type PluginListItem struct {
 	Signature     plugins.PluginSignatureStatus `json:"signature"`
 	SignatureType plugins.PluginSignatureType   `json:"signatureType"`
 	SignatureOrg  string                        `json:"signatureOrg"`
+	SignatureKey  string                        `json:"signatureKey"`
 }
 
 type PluginList []PluginListItem
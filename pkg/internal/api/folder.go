@@ -25,9 +25,10 @@ func (hs *HTTPServer) GetFolders(c *models.ReqContext) response.Response {
 
 	for _, f := range folders {
 		result = append(result, dtos.FolderSearchHit{
-			Id:    f.Id,
-			Uid:   f.Uid,
-			Title: f.Title,
+			Id:       f.Id,
+			Uid:      f.Uid,
+			Title:    f.Title,
+			ParentId: f.ParentId,
 		})
 	}
 
@@ -42,7 +43,7 @@ func (hs *HTTPServer) GetFolderByUID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, folder))
+	return response.JSON(200, toFolderDto(g, s, folder))
 }
 
 func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
@@ -53,12 +54,18 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, folder))
+	return response.JSON(200, toFolderDto(g, s, folder))
 }
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolderCommand) response.Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	folder, err := s.CreateFolder(cmd.Title, cmd.Uid)
+	var folder *models.Folder
+	var err error
+	if cmd.ParentUid != "" {
+		folder, err = s.CreateSubfolder(cmd.ParentUid, cmd.Title, cmd.Uid)
+	} else {
+		folder, err = s.CreateFolder(cmd.Title, cmd.Uid)
+	}
 	if err != nil {
 		return ToFolderErrorResponse(err)
 	}
@@ -71,7 +78,7 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolder
 	}
 
 	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, folder))
+	return response.JSON(200, toFolderDto(g, s, folder))
 }
 
 func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) response.Response {
@@ -82,7 +89,18 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolder
 	}
 
 	g := guardian.New(cmd.Result.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, cmd.Result))
+	return response.JSON(200, toFolderDto(g, s, cmd.Result))
+}
+
+func (hs *HTTPServer) MoveFolder(c *models.ReqContext, cmd models.MoveFolderCommand) response.Response {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	err := s.MoveFolder(c.Params(":uid"), &cmd)
+	if err != nil {
+		return ToFolderErrorResponse(err)
+	}
+
+	g := guardian.New(cmd.Result.Id, c.OrgId, c.SignedInUser)
+	return response.JSON(200, toFolderDto(g, s, cmd.Result))
 }
 
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
@@ -107,11 +125,19 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	})
 }
 
-func toFolderDto(g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
+func toFolderDto(g guardian.DashboardGuardian, s dashboards.FolderService, folder *models.Folder) dtos.Folder {
 	canEdit, _ := g.CanEdit()
 	canSave, _ := g.CanSave()
 	canAdmin, _ := g.CanAdmin()
 
+	var parents []dtos.FolderSearchHit
+	if folder.ParentId > 0 {
+		parentFolders, _ := s.GetFolderParents(folder.Uid)
+		for _, p := range parentFolders {
+			parents = append(parents, dtos.FolderSearchHit{Id: p.Id, Uid: p.Uid, Title: p.Title, ParentId: p.ParentId})
+		}
+	}
+
 	// Finding creator and last updater of the folder
 	updater, creator := anonString, anonString
 	if folder.CreatedBy > 0 {
@@ -135,6 +161,8 @@ func toFolderDto(g guardian.DashboardGuardian, folder *models.Folder) dtos.Folde
 		UpdatedBy: updater,
 		Updated:   folder.Updated,
 		Version:   folder.Version,
+		ParentId:  folder.ParentId,
+		Parents:   parents,
 	}
 }
 
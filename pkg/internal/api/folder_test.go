@@ -131,6 +131,88 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestFoldersAPIEndpoint_MoveFolder(t *testing.T) {
+	t.Run("Given a correct request for moving a folder", func(t *testing.T) {
+		cmd := models.MoveFolderCommand{
+			ParentUid: "parent",
+		}
+
+		mock := &fakeFolderService{
+			MoveFolderResult:       &models.Folder{Id: 2, Uid: "uid", Title: "Folder", ParentId: 1},
+			GetFolderParentsResult: []*models.Folder{{Id: 1, Uid: "parent", Title: "Parent"}},
+		}
+
+		moveFolderScenario(t, "When calling POST on", "/api/folders/uid/move", "/api/folders/:uid/move", mock, cmd,
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				folder := dtos.Folder{}
+				err := json.NewDecoder(sc.resp.Body).Decode(&folder)
+				require.NoError(t, err)
+				assert.Equal(t, int64(2), folder.Id)
+				assert.Equal(t, int64(1), folder.ParentId)
+				require.Len(t, folder.Parents, 1)
+				assert.Equal(t, "parent", folder.Parents[0].Uid)
+			})
+	})
+
+	t.Run("Given incorrect requests for moving a folder", func(t *testing.T) {
+		testCases := []struct {
+			Error              error
+			ExpectedStatusCode int
+		}{
+			{Error: models.ErrDashboardFolderCannotBeMovedIntoItself, ExpectedStatusCode: 400},
+			{Error: models.ErrDashboardFolderMaxDepthExceeded, ExpectedStatusCode: 400},
+			{Error: models.ErrFolderSameNameExists, ExpectedStatusCode: 400},
+			{Error: models.ErrFolderAccessDenied, ExpectedStatusCode: 403},
+			{Error: models.ErrFolderNotFound, ExpectedStatusCode: 404},
+			{Error: models.ErrFolderVersionMismatch, ExpectedStatusCode: 412},
+		}
+
+		for _, tc := range testCases {
+			mock := &fakeFolderService{
+				MoveFolderError: tc.Error,
+			}
+
+			moveFolderScenario(t, fmt.Sprintf("Expect '%s' error when calling POST on", tc.Error.Error()),
+				"/api/folders/uid/move", "/api/folders/:uid/move", mock, models.MoveFolderCommand{},
+				func(sc *scenarioContext) {
+					sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+					assert.Equalf(t, tc.ExpectedStatusCode, sc.resp.Code, "Wrong status code for %s", tc.Error)
+				})
+		}
+	})
+}
+
+func moveFolderScenario(t *testing.T, desc string, url string, routePattern string, mock *fakeFolderService,
+	cmd models.MoveFolderCommand, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		hs := HTTPServer{
+			Cfg: setting.NewCfg(),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: testUserID}
+
+			return hs.MoveFolder(c, cmd)
+		})
+
+		origNewFolderService := dashboards.NewFolderService
+		t.Cleanup(func() {
+			dashboards.NewFolderService = origNewFolderService
+		})
+		mockFolderService(mock)
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func callCreateFolder(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
@@ -202,19 +284,22 @@ func updateFolderScenario(t *testing.T, desc string, url string, routePattern st
 type fakeFolderService struct {
 	dashboards.FolderService
 
-	GetFoldersResult     []*models.Folder
-	GetFoldersError      error
-	GetFolderByUIDResult *models.Folder
-	GetFolderByUIDError  error
-	GetFolderByIDResult  *models.Folder
-	GetFolderByIDError   error
-	CreateFolderResult   *models.Folder
-	CreateFolderError    error
-	UpdateFolderResult   *models.Folder
-	UpdateFolderError    error
-	DeleteFolderResult   *models.Folder
-	DeleteFolderError    error
-	DeletedFolderUids    []string
+	GetFoldersResult       []*models.Folder
+	GetFoldersError        error
+	GetFolderByUIDResult   *models.Folder
+	GetFolderByUIDError    error
+	GetFolderByIDResult    *models.Folder
+	GetFolderByIDError     error
+	CreateFolderResult     *models.Folder
+	CreateFolderError      error
+	UpdateFolderResult     *models.Folder
+	UpdateFolderError      error
+	DeleteFolderResult     *models.Folder
+	DeleteFolderError      error
+	DeletedFolderUids      []string
+	MoveFolderResult       *models.Folder
+	MoveFolderError        error
+	GetFolderParentsResult []*models.Folder
 }
 
 func (s *fakeFolderService) GetFolders(limit int64) ([]*models.Folder, error) {
@@ -243,6 +328,19 @@ func (s *fakeFolderService) DeleteFolder(uid string) (*models.Folder, error) {
 	return s.DeleteFolderResult, s.DeleteFolderError
 }
 
+func (s *fakeFolderService) CreateSubfolder(parentUID, title, uid string) (*models.Folder, error) {
+	return s.CreateFolderResult, s.CreateFolderError
+}
+
+func (s *fakeFolderService) MoveFolder(uid string, cmd *models.MoveFolderCommand) error {
+	cmd.Result = s.MoveFolderResult
+	return s.MoveFolderError
+}
+
+func (s *fakeFolderService) GetFolderParents(uid string) ([]*models.Folder, error) {
+	return s.GetFolderParentsResult, nil
+}
+
 func mockFolderService(mock *fakeFolderService) {
 	dashboards.NewFolderService = func(orgId int64, user *models.SignedInUser,
 		dashboardStore dboards.Store) dashboards.FolderService {
@@ -15,6 +15,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
 	"github.com/grafana/grafana/pkg/internal/services/librarypanels"
+	"github.com/grafana/grafana/pkg/internal/services/libraryvariables"
 
 	"github.com/grafana/grafana/pkg/internal/api/routing"
 	httpstatic "github.com/grafana/grafana/pkg/internal/api/static"
@@ -104,6 +105,7 @@ type HTTPServer struct {
 	LoadSchemaService      *schemaloader.SchemaLoaderService       `inject:""`
 	Alertmanager           *notifier.Alertmanager                  `inject:""`
 	LibraryPanelService    librarypanels.Service                   `inject:""`
+	LibraryVariableService libraryvariables.Service                `inject:""`
 	LibraryElementService  libraryelements.Service                 `inject:""`
 	Listener               net.Listener
 }
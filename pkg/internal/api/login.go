@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/internal/api/dtos"
 	"github.com/grafana/grafana/pkg/internal/api/response"
 	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 	"github.com/grafana/grafana/pkg/internal/infra/log"
 	"github.com/grafana/grafana/pkg/internal/infra/metrics"
 	"github.com/grafana/grafana/pkg/internal/infra/network"
@@ -18,7 +19,6 @@ import (
 	"github.com/grafana/grafana/pkg/internal/middleware/cookies"
 	"github.com/grafana/grafana/pkg/internal/models"
 	"github.com/grafana/grafana/pkg/internal/setting"
-	"github.com/grafana/grafana/pkg/internal/util"
 	"github.com/grafana/grafana/pkg/internal/util/errutil"
 )
 
@@ -309,12 +309,12 @@ func tryGetEncryptedCookie(ctx *models.ReqContext, cookieName string) (string, b
 		return "", false
 	}
 
-	decryptedError, err := util.Decrypt(decoded, setting.SecretKey)
+	decryptedError, err := envelope.Decrypt(decoded)
 	return string(decryptedError), err == nil
 }
 
 func (hs *HTTPServer) trySetEncryptedCookie(ctx *models.ReqContext, cookieName string, value string, maxAge int) error {
-	encryptedError, err := util.Encrypt([]byte(value), setting.SecretKey)
+	encryptedError, err := envelope.Encrypt([]byte(value))
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -27,6 +28,13 @@ func Search(c *models.ReqContext) response.Response {
 		return response.Error(422, "Limit is above maximum allowed (5000), use page parameter to access hits beyond limit", nil)
 	}
 
+	contentFields := c.QueryStrings("contentField")
+	for _, field := range contentFields {
+		if !isDashboardContentField(field) {
+			return response.Error(400, fmt.Sprintf("Unknown content field %q", field), nil)
+		}
+	}
+
 	if c.Query("permission") == "Edit" {
 		permission = models.PERMISSION_EDIT
 	}
@@ -48,18 +56,20 @@ func Search(c *models.ReqContext) response.Response {
 	}
 
 	searchQuery := search.Query{
-		Title:        query,
-		Tags:         tags,
-		SignedInUser: c.SignedInUser,
-		Limit:        limit,
-		Page:         page,
-		IsStarred:    starred == "true",
-		OrgId:        c.OrgId,
-		DashboardIds: dbIDs,
-		Type:         dashboardType,
-		FolderIds:    folderIDs,
-		Permission:   permission,
-		Sort:         sort,
+		Title:         query,
+		Content:       c.Query("content"),
+		ContentFields: contentFields,
+		Tags:          tags,
+		SignedInUser:  c.SignedInUser,
+		Limit:         limit,
+		Page:          page,
+		IsStarred:     starred == "true",
+		OrgId:         c.OrgId,
+		DashboardIds:  dbIDs,
+		Type:          dashboardType,
+		FolderIds:     folderIDs,
+		Permission:    permission,
+		Sort:          sort,
 	}
 
 	err := bus.Dispatch(&searchQuery)
@@ -71,6 +81,15 @@ func Search(c *models.ReqContext) response.Response {
 	return response.JSON(200, searchQuery.Result)
 }
 
+func isDashboardContentField(field string) bool {
+	for _, f := range models.DashboardContentFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
 func (hs *HTTPServer) ListSortOptions(c *models.ReqContext) response.Response {
 	opts := hs.SearchService.SortOptions()
 
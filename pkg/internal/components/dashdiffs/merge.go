@@ -0,0 +1,324 @@
+package dashdiffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+)
+
+// Values of Resolution picking a side of a Conflict.
+const (
+	ResolutionLocal  = "local"
+	ResolutionRemote = "remote"
+)
+
+var (
+	// ErrUnknownResolution occurs when a conflict is resolved with something else than ResolutionLocal or ResolutionRemote.
+	ErrUnknownResolution = errors.New("dashdiff: unknown conflict resolution")
+)
+
+// Conflict is a value that was changed both locally and remotely since the common base version.
+// Base, Local and Remote are nil if the value doesn't exist in the respective version.
+type Conflict struct {
+	Path   string      `json:"path"`
+	Base   interface{} `json:"base"`
+	Local  interface{} `json:"local"`
+	Remote interface{} `json:"remote"`
+}
+
+// MergeResult is the result of a three-way merge of dashboards. Conflicting values are
+// set to their remote value in Merged unless they have been resolved otherwise.
+type MergeResult struct {
+	Merged    *simplejson.Json `json:"merged"`
+	Conflicts []Conflict       `json:"conflicts"`
+	// FolderId is the folder of the latest version when merging with it, since the folder isn't
+	// part of the dashboard JSON.
+	FolderId int64 `json:"folderId"`
+}
+
+// MergeOptions are the options for merging local changes of a dashboard with its latest version.
+type MergeOptions struct {
+	OrgId       int64
+	DashboardId int64
+	// Local is the locally changed dashboard. Its version is the base version of the merge.
+	Local *simplejson.Json
+	// Resolutions maps the paths of conflicts to ResolutionLocal or ResolutionRemote.
+	Resolutions map[string]string
+}
+
+// MergeWithLatest merges the local changes of a dashboard with the changes saved since the
+// version the local changes are based on. The version of the merged dashboard is set to the
+// latest version, so that it can be saved unless it changed again in the meantime.
+func MergeWithLatest(options *MergeOptions) (*MergeResult, error) {
+	latestQuery := models.GetDashboardQuery{Id: options.DashboardId, OrgId: options.OrgId}
+	if err := bus.Dispatch(&latestQuery); err != nil {
+		return nil, err
+	}
+
+	baseVersionQuery := models.GetDashboardVersionQuery{
+		DashboardId: options.DashboardId,
+		Version:     options.Local.Get("version").MustInt(),
+		OrgId:       options.OrgId,
+	}
+	if err := bus.Dispatch(&baseVersionQuery); err != nil {
+		return nil, err
+	}
+
+	result, err := Merge(baseVersionQuery.Result.Data, options.Local, latestQuery.Result.Data, options.Resolutions)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Merged.Set("id", latestQuery.Result.Id)
+	result.Merged.Set("uid", latestQuery.Result.Uid)
+	result.Merged.Set("version", latestQuery.Result.Version)
+	result.FolderId = latestQuery.Result.FolderId
+	return result, nil
+}
+
+// Merge performs a three-way merge of the dashboards local and remote, which were both changed
+// from base. Changes made on only one side are applied, while values changed differently on both
+// sides are reported as conflicts. Arrays of objects identified by an id or name, such as panels
+// and template variables, are merged element by element.
+func Merge(base, local, remote *simplejson.Json, resolutions map[string]string) (*MergeResult, error) {
+	for path, resolution := range resolutions {
+		if resolution != ResolutionLocal && resolution != ResolutionRemote {
+			return nil, fmt.Errorf("%w %q for %q", ErrUnknownResolution, resolution, path)
+		}
+	}
+
+	b, err := normalize(base)
+	if err != nil {
+		return nil, err
+	}
+	l, err := normalize(local)
+	if err != nil {
+		return nil, err
+	}
+	r, err := normalize(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &merger{resolutions: resolutions, conflicts: make([]Conflict, 0)}
+	merged, _ := m.merge("", b, l, r, true, true, true)
+
+	return &MergeResult{
+		Merged:    simplejson.NewFromAny(merged),
+		Conflicts: m.conflicts,
+	}, nil
+}
+
+// normalize returns a deep copy of the JSON, with all numbers decoded as json.Number
+// so that values can be compared regardless of how the JSON was created.
+func normalize(j *simplejson.Json) (interface{}, error) {
+	data, err := j.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// mergeIgnoredKeys are the keys of the dashboard that are managed by Grafana, and thus never conflict.
+var mergeIgnoredKeys = map[string]bool{"id": true, "uid": true, "version": true}
+
+type merger struct {
+	resolutions map[string]string
+	conflicts   []Conflict
+}
+
+// merge merges a single value, where the has* arguments tell whether the value exists in the
+// respective version. It returns the merged value and whether it exists.
+func (m *merger) merge(path string, base, local, remote interface{}, hasBase, hasLocal, hasRemote bool) (
+	interface{}, bool) {
+	switch {
+	case hasLocal == hasRemote && reflect.DeepEqual(local, remote):
+		return local, hasLocal
+	case hasLocal == hasBase && reflect.DeepEqual(local, base):
+		return remote, hasRemote
+	case hasRemote == hasBase && reflect.DeepEqual(remote, base):
+		return local, hasLocal
+	}
+
+	if hasLocal && hasRemote {
+		baseMap, _ := base.(map[string]interface{})
+		localMap, localIsMap := local.(map[string]interface{})
+		remoteMap, remoteIsMap := remote.(map[string]interface{})
+		if localIsMap && remoteIsMap {
+			return m.mergeObjects(path, baseMap, localMap, remoteMap), true
+		}
+
+		baseArr, _ := base.([]interface{})
+		localArr, localIsArr := local.([]interface{})
+		remoteArr, remoteIsArr := remote.([]interface{})
+		if localIsArr && remoteIsArr {
+			if merged, ok := m.mergeArrays(path, baseArr, localArr, remoteArr); ok {
+				return merged, true
+			}
+		}
+	}
+
+	return m.conflict(path, base, local, remote, hasBase, hasLocal, hasRemote)
+}
+
+func (m *merger) conflict(path string, base, local, remote interface{}, hasBase, hasLocal, hasRemote bool) (
+	interface{}, bool) {
+	c := Conflict{Path: path}
+	if hasBase {
+		c.Base = base
+	}
+	if hasLocal {
+		c.Local = local
+	}
+	if hasRemote {
+		c.Remote = remote
+	}
+
+	switch m.resolutions[path] {
+	case ResolutionLocal:
+		return local, hasLocal
+	case ResolutionRemote:
+		return remote, hasRemote
+	}
+
+	m.conflicts = append(m.conflicts, c)
+	return remote, hasRemote
+}
+
+func (m *merger) mergeObjects(path string, base, local, remote map[string]interface{}) map[string]interface{} {
+	keys := make(map[string]bool)
+	for _, obj := range []map[string]interface{}{base, local, remote} {
+		for k := range obj {
+			keys[k] = true
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	merged := make(map[string]interface{}, len(keys))
+	for _, k := range sortedKeys {
+		if path == "" && mergeIgnoredKeys[k] {
+			if v, ok := remote[k]; ok {
+				merged[k] = v
+			}
+			continue
+		}
+
+		b, hasBase := base[k]
+		l, hasLocal := local[k]
+		r, hasRemote := remote[k]
+		if v, ok := m.merge(joinPath(path, k), b, l, r, hasBase, hasLocal, hasRemote); ok {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// mergeArrays merges arrays of objects element by element, if all elements can be identified by a
+// unique id or name. The merged array keeps the remote order, followed by the elements added locally.
+func (m *merger) mergeArrays(path string, base, local, remote []interface{}) ([]interface{}, bool) {
+	var identity string
+	var baseByKey, localByKey, remoteByKey map[string]interface{}
+	for _, key := range []string{"id", "name"} {
+		var ok bool
+		if baseByKey, ok = elementsByKey(base, key); !ok {
+			continue
+		}
+		if localByKey, ok = elementsByKey(local, key); !ok {
+			continue
+		}
+		if remoteByKey, ok = elementsByKey(remote, key); !ok {
+			continue
+		}
+		identity = key
+		break
+	}
+	if identity == "" {
+		return nil, false
+	}
+
+	order := make([]string, 0, len(remote)+len(local))
+	seen := make(map[string]bool)
+	for _, arr := range [][]interface{}{remote, local, base} {
+		for _, el := range arr {
+			k := elementKey(el, identity)
+			if !seen[k] {
+				seen[k] = true
+				order = append(order, k)
+			}
+		}
+	}
+
+	merged := make([]interface{}, 0, len(order))
+	for _, k := range order {
+		b, hasBase := baseByKey[k]
+		l, hasLocal := localByKey[k]
+		r, hasRemote := remoteByKey[k]
+		elementPath := fmt.Sprintf("%s[%s=%s]", path, identity, k)
+		if v, ok := m.merge(elementPath, b, l, r, hasBase, hasLocal, hasRemote); ok {
+			merged = append(merged, v)
+		}
+	}
+
+	return merged, true
+}
+
+// elementsByKey indexes the elements of an array by the value of key, which must be set and unique
+// for every element.
+func elementsByKey(arr []interface{}, key string) (map[string]interface{}, bool) {
+	byKey := make(map[string]interface{}, len(arr))
+	for _, el := range arr {
+		k := elementKey(el, key)
+		if k == "" {
+			return nil, false
+		}
+		if _, exists := byKey[k]; exists {
+			return nil, false
+		}
+		byKey[k] = el
+	}
+	return byKey, true
+}
+
+func elementKey(el interface{}, key string) string {
+	obj, ok := el.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	switch v := obj[key].(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return strings.Join([]string{path, key}, ".")
+}
@@ -0,0 +1,126 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	const (
+		baseJSON = `{
+			"id": 1,
+			"version": 3,
+			"title": "Dashboard",
+			"description": "base",
+			"tags": ["a"],
+			"panels": [
+				{"id": 1, "title": "Panel 1", "type": "graph"},
+				{"id": 2, "title": "Panel 2", "type": "graph"}
+			]
+		}`
+
+		localJSON = `{
+			"id": 1,
+			"version": 3,
+			"title": "Local title",
+			"description": "local",
+			"tags": ["a"],
+			"panels": [
+				{"id": 1, "title": "Panel 1", "type": "stat"},
+				{"id": 2, "title": "Panel 2", "type": "graph"},
+				{"id": 3, "title": "Local panel", "type": "graph"}
+			]
+		}`
+
+		remoteJSON = `{
+			"id": 1,
+			"version": 5,
+			"title": "Dashboard",
+			"description": "remote",
+			"tags": ["a", "b"],
+			"panels": [
+				{"id": 2, "title": "Panel 2", "type": "graph"},
+				{"id": 1, "title": "Remote panel 1", "type": "graph"}
+			]
+		}`
+	)
+
+	parse := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+
+	t.Run("Changes from both sides are merged", func(t *testing.T) {
+		result, err := Merge(parse(t, baseJSON), parse(t, localJSON), parse(t, remoteJSON), nil)
+		require.NoError(t, err)
+
+		merged := result.Merged
+		assert.Equal(t, "Local title", merged.Get("title").MustString())
+		assert.Equal(t, []string{"a", "b"}, merged.Get("tags").MustStringArray())
+		assert.Equal(t, json.Number("5"), merged.Get("version").Interface())
+
+		panels := merged.Get("panels").MustArray()
+		require.Len(t, panels, 3)
+		assert.Equal(t, "Panel 2", merged.Get("panels").GetIndex(0).Get("title").MustString())
+		assert.Equal(t, "Remote panel 1", merged.Get("panels").GetIndex(1).Get("title").MustString())
+		assert.Equal(t, "stat", merged.Get("panels").GetIndex(1).Get("type").MustString())
+		assert.Equal(t, "Local panel", merged.Get("panels").GetIndex(2).Get("title").MustString())
+	})
+
+	t.Run("Values changed on both sides are conflicts", func(t *testing.T) {
+		result, err := Merge(parse(t, baseJSON), parse(t, localJSON), parse(t, remoteJSON), nil)
+		require.NoError(t, err)
+
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, Conflict{Path: "description", Base: "base", Local: "local", Remote: "remote"}, result.Conflicts[0])
+		assert.Equal(t, "remote", result.Merged.Get("description").MustString())
+	})
+
+	t.Run("Conflicts can be resolved", func(t *testing.T) {
+		result, err := Merge(parse(t, baseJSON), parse(t, localJSON), parse(t, remoteJSON),
+			map[string]string{"description": ResolutionLocal})
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, "local", result.Merged.Get("description").MustString())
+	})
+
+	t.Run("Conflicts in array elements have the element in their path", func(t *testing.T) {
+		local := parse(t, baseJSON)
+		local.Get("panels").GetIndex(1).Set("title", "Local panel 2")
+		remote := parse(t, baseJSON)
+		remote.Get("panels").GetIndex(1).Set("title", "Remote panel 2")
+
+		result, err := Merge(parse(t, baseJSON), local, remote, nil)
+		require.NoError(t, err)
+
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, "panels[id=2].title", result.Conflicts[0].Path)
+	})
+
+	t.Run("Deleting a value changed on the other side is a conflict", func(t *testing.T) {
+		local := parse(t, baseJSON)
+		local.Del("description")
+		remote := parse(t, baseJSON)
+		remote.Set("description", "remote")
+
+		result, err := Merge(parse(t, baseJSON), local, remote, nil)
+		require.NoError(t, err)
+
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, Conflict{Path: "description", Base: "base", Remote: "remote"}, result.Conflicts[0])
+	})
+
+	t.Run("Unknown resolutions are rejected", func(t *testing.T) {
+		_, err := Merge(parse(t, baseJSON), parse(t, localJSON), parse(t, remoteJSON),
+			map[string]string{"description": "mine"})
+		require.ErrorIs(t, err, ErrUnknownResolution)
+	})
+}
@@ -0,0 +1,183 @@
+// Package envelope implements envelope encryption for secrets stored in the database.
+//
+// Every secret is encrypted with its own random data key (DEK). The data key is in turn
+// encrypted by a key encryption key (KEK) owned by a KeyProvider, and stored next to the
+// ciphertext together with the ID of the provider that wrapped it. Secrets can therefore be
+// decrypted with any configured provider, and rotating the KEK re-seals every stored secret
+// with a new data key wrapped by the new KEK.
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magic prefixes every enveloped payload. It contains characters that never occur in the
+// salt of payloads produced by util.Encrypt, which lets us tell both formats apart.
+var magic = []byte("#env1#")
+
+const dataKeyLength = 32
+
+var (
+	// ErrPayloadTooShort is returned when a payload is too short to be an envelope.
+	ErrPayloadTooShort = errors.New("envelope payload too short")
+	// ErrNotEnvelope is returned when trying to open a payload that isn't enveloped.
+	ErrNotEnvelope = errors.New("payload is not envelope encrypted")
+)
+
+// KeyProvider wraps and unwraps data keys with a key encryption key.
+type KeyProvider interface {
+	// ID uniquely identifies the key encryption key of the provider. It's stored
+	// with every envelope so that the right key can be found on decryption.
+	ID() string
+	// WrapKey encrypts a data key.
+	WrapKey(dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key previously encrypted by WrapKey.
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// ProviderResolver returns the KeyProvider for a given provider ID.
+type ProviderResolver func(id string) (KeyProvider, error)
+
+// IsEnvelope returns true if the payload has been encrypted by Seal.
+func IsEnvelope(payload []byte) bool {
+	return bytes.HasPrefix(payload, magic)
+}
+
+// Seal encrypts payload with a new data key and wraps the data key with the given provider.
+//
+// The resulting layout is:
+//
+//	magic | len(id) uint8 | id | len(wrapped key) uint16 | wrapped key | nonce | ciphertext
+func Seal(payload []byte, provider KeyProvider) ([]byte, error) {
+	id := provider.ID()
+	if len(id) == 0 || len(id) > 255 {
+		return nil, fmt.Errorf("invalid key provider ID %q", id)
+	}
+
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := provider.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	if len(wrapped) > 65535 {
+		return nil, errors.New("wrapped data key too long")
+	}
+
+	ciphertext, err := encryptGCM(dataKey, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(magic)+1+len(id)+2+len(wrapped)+len(ciphertext)))
+	buf.Write(magic)
+	buf.WriteByte(byte(len(id)))
+	buf.WriteString(id)
+	lenBuf := make([]byte, 2)
+	binary.BigEndian.PutUint16(lenBuf, uint16(len(wrapped)))
+	buf.Write(lenBuf)
+	buf.Write(wrapped)
+	buf.Write(ciphertext)
+
+	return buf.Bytes(), nil
+}
+
+// Open decrypts a payload produced by Seal, looking up the provider that wrapped
+// the data key with resolve.
+func Open(payload []byte, resolve ProviderResolver) ([]byte, error) {
+	id, wrapped, ciphertext, err := split(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := resolve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := provider.UnwrapKey(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with provider %q: %w", id, err)
+	}
+
+	return decryptGCM(dataKey, ciphertext)
+}
+
+// ProviderID returns the ID of the provider that wrapped the data key of an enveloped payload.
+func ProviderID(payload []byte) (string, error) {
+	id, _, _, err := split(payload)
+	return id, err
+}
+
+func split(payload []byte) (id string, wrapped []byte, ciphertext []byte, err error) {
+	if !IsEnvelope(payload) {
+		return "", nil, nil, ErrNotEnvelope
+	}
+	rest := payload[len(magic):]
+
+	if len(rest) < 1 {
+		return "", nil, nil, ErrPayloadTooShort
+	}
+	idLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < idLen+2 {
+		return "", nil, nil, ErrPayloadTooShort
+	}
+	id = string(rest[:idLen])
+	rest = rest[idLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest[:2]))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return "", nil, nil, ErrPayloadTooShort
+	}
+
+	return id, rest[:wrappedLen], rest[wrappedLen:], nil
+}
+
+// encryptGCM encrypts payload with AES-GCM and prepends the nonce to the ciphertext.
+func encryptGCM(key, payload []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, payload, nil), nil
+}
+
+func decryptGCM(key, payload []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, ErrPayloadTooShort
+	}
+
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,212 @@
+package envelope
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/grafana/grafana/pkg/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	provider, err := NewSecretKeyProvider("kek")
+	require.NoError(t, err)
+
+	resolve := func(id string) (KeyProvider, error) {
+		if id != provider.ID() {
+			return nil, errors.New("unknown provider")
+		}
+		return provider, nil
+	}
+
+	t.Run("sealing and opening a payload", func(t *testing.T) {
+		sealed, err := Seal([]byte("grafana"), provider)
+		require.NoError(t, err)
+		assert.True(t, IsEnvelope(sealed))
+
+		id, err := ProviderID(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, provider.ID(), id)
+
+		opened, err := Open(sealed, resolve)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), opened)
+	})
+
+	t.Run("every payload gets its own data key", func(t *testing.T) {
+		a, err := Seal([]byte("grafana"), provider)
+		require.NoError(t, err)
+		b, err := Seal([]byte("grafana"), provider)
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("opening with another key fails", func(t *testing.T) {
+		other, err := NewSecretKeyProvider("another kek")
+		require.NoError(t, err)
+		assert.NotEqual(t, provider.ID(), other.ID())
+
+		sealed, err := Seal([]byte("grafana"), other)
+		require.NoError(t, err)
+
+		_, err = Open(sealed, resolve)
+		require.Error(t, err)
+	})
+
+	t.Run("opening a truncated payload fails", func(t *testing.T) {
+		sealed, err := Seal([]byte("grafana"), provider)
+		require.NoError(t, err)
+
+		_, err = Open(sealed[:len(magic)+3], resolve)
+		require.ErrorIs(t, err, ErrPayloadTooShort)
+	})
+
+	t.Run("legacy payloads aren't envelopes", func(t *testing.T) {
+		encrypted, err := util.Encrypt([]byte("grafana"), "secret")
+		require.NoError(t, err)
+		assert.False(t, IsEnvelope(encrypted))
+	})
+}
+
+func TestLocalFileProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("empty key file", func(t *testing.T) {
+		path := filepath.Join(dir, "empty")
+		require.NoError(t, ioutil.WriteFile(path, []byte("  \n"), 0600))
+
+		_, err := NewLocalFileProvider(path)
+		require.Error(t, err)
+	})
+
+	t.Run("trailing whitespace is ignored", func(t *testing.T) {
+		a := filepath.Join(dir, "a")
+		require.NoError(t, ioutil.WriteFile(a, []byte("key"), 0600))
+		b := filepath.Join(dir, "b")
+		require.NoError(t, ioutil.WriteFile(b, []byte("key\n"), 0600))
+
+		pa, err := NewLocalFileProvider(a)
+		require.NoError(t, err)
+		pb, err := NewLocalFileProvider(b)
+		require.NoError(t, err)
+		assert.Equal(t, pa.ID(), pb.ID())
+	})
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	origSecretKey := setting.SecretKey
+	t.Cleanup(func() {
+		setting.SecretKey = origSecretKey
+		setting.EnvelopeEncryptionProvider = ""
+		setting.EnvelopeEncryptionKeyFile = ""
+		setting.EnvelopeEncryptionPreviousKeyFiles = nil
+	})
+	setting.SecretKey = "secret"
+
+	t.Run("without envelope encryption the legacy format is used", func(t *testing.T) {
+		setting.EnvelopeEncryptionProvider = ""
+
+		encrypted, err := Encrypt([]byte("grafana"))
+		require.NoError(t, err)
+		assert.False(t, IsEnvelope(encrypted))
+
+		decrypted, err := util.Decrypt(encrypted, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("with a local key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "kek")
+		require.NoError(t, ioutil.WriteFile(path, []byte("kek"), 0600))
+
+		legacy, err := util.Encrypt([]byte("legacy"), "secret")
+		require.NoError(t, err)
+
+		setting.EnvelopeEncryptionProvider = LocalFileProviderType
+		setting.EnvelopeEncryptionKeyFile = path
+
+		encrypted, err := Encrypt([]byte("grafana"))
+		require.NoError(t, err)
+		assert.True(t, IsEnvelope(encrypted))
+
+		decrypted, err := Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		decrypted, err = Decrypt(legacy)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("legacy"), decrypted)
+	})
+
+	t.Run("secrets wrapped with secret_key stay readable after switching providers", func(t *testing.T) {
+		setting.EnvelopeEncryptionProvider = SecretKeyProviderType
+		encrypted, err := Encrypt([]byte("grafana"))
+		require.NoError(t, err)
+
+		setting.EnvelopeEncryptionProvider = ""
+		decrypted, err := Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("secrets wrapped with a key file stay readable after switching to another provider", func(t *testing.T) {
+		dir := t.TempDir()
+		oldPath, newPath := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+		require.NoError(t, ioutil.WriteFile(oldPath, []byte("old"), 0600))
+		require.NoError(t, ioutil.WriteFile(newPath, []byte("new"), 0600))
+
+		setting.EnvelopeEncryptionProvider = LocalFileProviderType
+		setting.EnvelopeEncryptionKeyFile = oldPath
+		encrypted, err := Encrypt([]byte("grafana"))
+		require.NoError(t, err)
+
+		setting.EnvelopeEncryptionProvider = SecretKeyProviderType
+		decrypted, err := Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		setting.EnvelopeEncryptionProvider = LocalFileProviderType
+		setting.EnvelopeEncryptionKeyFile = newPath
+		_, err = Decrypt(encrypted)
+		require.Error(t, err)
+
+		setting.EnvelopeEncryptionPreviousKeyFiles = []string{oldPath}
+		decrypted, err = Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("key files that fail to load are skipped when resolving the provider of a secret", func(t *testing.T) {
+		dir := t.TempDir()
+		oldPath, missingPath := filepath.Join(dir, "old"), filepath.Join(dir, "missing")
+		require.NoError(t, ioutil.WriteFile(oldPath, []byte("old"), 0600))
+
+		setting.EnvelopeEncryptionProvider = LocalFileProviderType
+		setting.EnvelopeEncryptionKeyFile = oldPath
+		setting.EnvelopeEncryptionPreviousKeyFiles = nil
+		encrypted, err := Encrypt([]byte("grafana"))
+		require.NoError(t, err)
+
+		setting.EnvelopeEncryptionKeyFile = missingPath
+		setting.EnvelopeEncryptionPreviousKeyFiles = []string{missingPath, oldPath}
+		decrypted, err := Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		setting.EnvelopeEncryptionPreviousKeyFiles = []string{missingPath}
+		_, err = Decrypt(encrypted)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, os.ErrNotExist), err)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		setting.EnvelopeEncryptionProvider = "vault"
+		_, err := Encrypt([]byte("grafana"))
+		require.Error(t, err)
+	})
+}
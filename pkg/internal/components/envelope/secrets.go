@@ -0,0 +1,143 @@
+package envelope
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/grafana/grafana/pkg/internal/util"
+)
+
+type providerCacheKey struct {
+	providerType string
+	keyFile      string
+	secretKey    string
+}
+
+var (
+	logger        = log.New("envelope")
+	providerMu    sync.Mutex
+	providerCache = map[providerCacheKey]KeyProvider{}
+)
+
+// Encrypt encrypts a secret for storage in the database. If envelope encryption is
+// configured, the secret is sealed with a data key wrapped by the configured provider.
+// Otherwise the legacy util.Encrypt format keyed by secret_key is used.
+func Encrypt(payload []byte) ([]byte, error) {
+	provider, err := ActiveProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	if provider == nil {
+		return util.Encrypt(payload, setting.SecretKey)
+	}
+
+	return Seal(payload, provider)
+}
+
+// Decrypt decrypts a secret encrypted by Encrypt, in either the envelope or the legacy format.
+func Decrypt(payload []byte) ([]byte, error) {
+	if !IsEnvelope(payload) {
+		return util.Decrypt(payload, setting.SecretKey)
+	}
+
+	return Open(payload, ResolveProvider)
+}
+
+// ActiveProvider returns the KeyProvider configured through envelope_encryption_provider,
+// or nil if envelope encryption is disabled.
+func ActiveProvider() (KeyProvider, error) {
+	switch setting.EnvelopeEncryptionProvider {
+	case "":
+		return nil, nil
+	case LocalFileProviderType:
+		return cachedProvider(LocalFileProviderType, setting.EnvelopeEncryptionKeyFile)
+	case SecretKeyProviderType:
+		return cachedProvider(SecretKeyProviderType, "")
+	default:
+		return nil, fmt.Errorf("unknown envelope encryption provider %q", setting.EnvelopeEncryptionProvider)
+	}
+}
+
+// ResolveProvider returns the configured KeyProvider matching id. Every configured provider is
+// considered, whether it is active or not: the provider keyed by secret_key, the provider of
+// envelope_encryption_key_file and those of envelope_encryption_previous_key_files. Secrets hence
+// remain readable after the key encryption key is switched to another provider.
+//
+// A provider that fails to load, such as one with a stale key file, is skipped so that the providers
+// listed after it can still match. Its error is only returned if no provider matches.
+func ResolveProvider(id string) (KeyProvider, error) {
+	// the active provider is configured again below, where an error loading it is handled
+	if active, err := ActiveProvider(); err == nil && active != nil && active.ID() == id {
+		return active, nil
+	}
+
+	type configuredProvider struct {
+		providerType string
+		keyFile      string
+	}
+	var configured []configuredProvider
+	if setting.SecretKey != "" {
+		configured = append(configured, configuredProvider{providerType: SecretKeyProviderType})
+	}
+	if setting.EnvelopeEncryptionKeyFile != "" {
+		configured = append(configured, configuredProvider{LocalFileProviderType, setting.EnvelopeEncryptionKeyFile})
+	}
+	for _, keyFile := range setting.EnvelopeEncryptionPreviousKeyFiles {
+		configured = append(configured, configuredProvider{LocalFileProviderType, keyFile})
+	}
+
+	var loadErr error
+	for _, c := range configured {
+		p, err := cachedProvider(c.providerType, c.keyFile)
+		if err != nil {
+			logger.Warn("Skipping key provider that failed to load", "type", c.providerType, "keyFile", c.keyFile, "error", err)
+			if loadErr == nil {
+				loadErr = err
+			}
+			continue
+		}
+		if p.ID() == id {
+			return p, nil
+		}
+	}
+
+	if loadErr != nil {
+		return nil, fmt.Errorf("no key provider configured for key %q: %w", id, loadErr)
+	}
+	return nil, fmt.Errorf("no key provider configured for key %q", id)
+}
+
+func cachedProvider(providerType string, keyFile string) (KeyProvider, error) {
+	key := providerCacheKey{providerType: providerType}
+	switch providerType {
+	case LocalFileProviderType:
+		key.keyFile = keyFile
+	case SecretKeyProviderType:
+		key.secretKey = setting.SecretKey
+	}
+
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	if p, ok := providerCache[key]; ok {
+		return p, nil
+	}
+
+	var p KeyProvider
+	var err error
+	switch providerType {
+	case LocalFileProviderType:
+		p, err = NewLocalFileProvider(key.keyFile)
+	case SecretKeyProviderType:
+		p, err = NewSecretKeyProvider(key.secretKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	providerCache[key] = p
+	return p, nil
+}
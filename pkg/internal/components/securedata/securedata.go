@@ -1,16 +1,15 @@
 package securedata
 
 import (
-	"github.com/grafana/grafana/pkg/internal/setting"
-	"github.com/grafana/grafana/pkg/internal/util"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 )
 
 type SecureData []byte
 
 func Encrypt(data []byte) (SecureData, error) {
-	return util.Encrypt(data, setting.SecretKey)
+	return envelope.Encrypt(data)
 }
 
 func (s SecureData) Decrypt() ([]byte, error) {
-	return util.Decrypt(s, setting.SecretKey)
+	return envelope.Decrypt(s)
 }
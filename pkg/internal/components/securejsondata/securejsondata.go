@@ -1,9 +1,8 @@
 package securejsondata
 
 import (
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 	"github.com/grafana/grafana/pkg/internal/infra/log"
-	"github.com/grafana/grafana/pkg/internal/setting"
-	"github.com/grafana/grafana/pkg/internal/util"
 )
 
 // SecureJsonData is used to store encrypted data (for example in data_source table). Only values are separately
@@ -14,7 +13,7 @@ type SecureJsonData map[string][]byte
 // is true if the key exists and false if not.
 func (s SecureJsonData) DecryptedValue(key string) (string, bool) {
 	if value, ok := s[key]; ok {
-		decryptedData, err := util.Decrypt(value, setting.SecretKey)
+		decryptedData, err := envelope.Decrypt(value)
 		if err != nil {
 			log.Fatalf(4, err.Error())
 		}
@@ -28,7 +27,7 @@ func (s SecureJsonData) DecryptedValue(key string) (string, bool) {
 func (s SecureJsonData) Decrypt() map[string]string {
 	decrypted := make(map[string]string)
 	for key, data := range s {
-		decryptedData, err := util.Decrypt(data, setting.SecretKey)
+		decryptedData, err := envelope.Decrypt(data)
 		if err != nil {
 			log.Fatalf(4, err.Error())
 		}
@@ -42,7 +41,7 @@ func (s SecureJsonData) Decrypt() map[string]string {
 func GetEncryptedJsonData(sjd map[string]string) SecureJsonData {
 	encrypted := make(SecureJsonData)
 	for key, data := range sjd {
-		encryptedData, err := util.Encrypt([]byte(data), setting.SecretKey)
+		encryptedData, err := envelope.Encrypt([]byte(data))
 		if err != nil {
 			log.Fatalf(4, err.Error())
 		}
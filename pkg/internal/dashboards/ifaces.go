@@ -8,6 +8,10 @@ type Store interface {
 	ValidateDashboardBeforeSave(dashboard *models.Dashboard, overwrite bool) (bool, error)
 	// GetFolderByTitle retrieves a dashboard by its title and is used by unified alerting
 	GetFolderByTitle(orgID int64, title string) (*models.Dashboard, error)
+	// GetFolderAncestors returns a folder and the folders above it, starting with the top level folder.
+	GetFolderAncestors(orgID int64, folderID int64) ([]*models.Dashboard, error)
+	// GetFolderDescendants returns all subfolders of a folder, at any depth.
+	GetFolderDescendants(orgID int64, folderID int64) ([]*models.Dashboard, error)
 	GetProvisionedDataByDashboardID(dashboardID int64) (*models.DashboardProvisioning, error)
 	GetProvisionedDashboardData(name string) ([]*models.DashboardProvisioning, error)
 	SaveProvisionedDashboard(cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error)
@@ -64,6 +64,21 @@ func Max(fv *Float64Field) *float64 {
 	return &f
 }
 
+// Last returns the last value of the field, which is the most recent value of a series.
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	v := fv.GetValue(fv.Len() - 1)
+	if v == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *v
+	return &f
+}
+
 func Count(fv *Float64Field) *float64 {
 	f := float64(fv.Len())
 	return &f
@@ -90,6 +105,8 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "last":
+		f = Last(&floatField)
 	default:
 		return number, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
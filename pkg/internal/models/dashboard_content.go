@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+)
+
+// Fields of the dashboard content that is stored for content search.
+const (
+	DashboardContentTitle       = "title"
+	DashboardContentDescription = "description"
+	DashboardContentQuery       = "query"
+	DashboardContentDatasource  = "datasource"
+	DashboardContentVariable    = "variable"
+)
+
+// DashboardContentFields are all fields of the dashboard content that is stored for content search.
+var DashboardContentFields = []string{
+	DashboardContentTitle,
+	DashboardContentDescription,
+	DashboardContentQuery,
+	DashboardContentDatasource,
+	DashboardContentVariable,
+}
+
+// dashboardContentQueryKeys are the keys of panel targets that hold the query expressions of the core data sources.
+var dashboardContentQueryKeys = []string{
+	"expr", "query", "rawSql", "target", "expression", "queryText", "measurement", "metric", "metricName",
+}
+
+// DashboardContentItem is a piece of text from the dashboard JSON that is stored for content search.
+type DashboardContentItem struct {
+	// PanelId is the ID of the panel the text belongs to, or 0 for the dashboard itself.
+	PanelId int64
+	Field   string
+	Term    string
+}
+
+// GetContent returns the text of the dashboard JSON that can be searched for: the titles and descriptions
+// of the dashboard and its panels, the queries and data sources of the panels and the template variables.
+func (d *Dashboard) GetContent() []*DashboardContentItem {
+	c := dashboardContent{seen: map[DashboardContentItem]bool{}}
+
+	c.add(0, DashboardContentTitle, d.Data.Get("title").MustString())
+	c.add(0, DashboardContentDescription, d.Data.Get("description").MustString())
+
+	variables := d.Data.Get("templating").Get("list")
+	for i := range variables.MustArray() {
+		variable := variables.GetIndex(i)
+		c.add(0, DashboardContentVariable, variable.Get("name").MustString())
+		c.addDatasource(0, variable.Get("datasource"))
+		c.add(0, DashboardContentQuery, variable.Get("query").MustString())
+	}
+
+	c.addPanels(d.Data.Get("panels"))
+
+	// dashboards that have not been migrated to the current schema still keep their panels in rows
+	rows := d.Data.Get("rows")
+	for i := range rows.MustArray() {
+		c.addPanels(rows.GetIndex(i).Get("panels"))
+	}
+
+	return c.items
+}
+
+type dashboardContent struct {
+	items []*DashboardContentItem
+	seen  map[DashboardContentItem]bool
+}
+
+func (c *dashboardContent) add(panelID int64, field, term string) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return
+	}
+
+	item := DashboardContentItem{PanelId: panelID, Field: field, Term: term}
+	if c.seen[item] {
+		return
+	}
+
+	c.seen[item] = true
+	c.items = append(c.items, &item)
+}
+
+// addDatasource adds a data source reference, which is either the name of the data source
+// or an object with its UID and type.
+func (c *dashboardContent) addDatasource(panelID int64, ref *simplejson.Json) {
+	if name, err := ref.String(); err == nil {
+		c.add(panelID, DashboardContentDatasource, name)
+		return
+	}
+
+	c.add(panelID, DashboardContentDatasource, ref.Get("uid").MustString())
+	c.add(panelID, DashboardContentDatasource, ref.Get("type").MustString())
+}
+
+func (c *dashboardContent) addPanels(panels *simplejson.Json) {
+	for i := range panels.MustArray() {
+		panel := panels.GetIndex(i)
+		panelID := panel.Get("id").MustInt64()
+
+		c.add(panelID, DashboardContentTitle, panel.Get("title").MustString())
+		c.add(panelID, DashboardContentDescription, panel.Get("description").MustString())
+		c.addDatasource(panelID, panel.Get("datasource"))
+
+		targets := panel.Get("targets")
+		for j := range targets.MustArray() {
+			target := targets.GetIndex(j)
+			c.addDatasource(panelID, target.Get("datasource"))
+			for _, key := range dashboardContentQueryKeys {
+				c.add(panelID, DashboardContentQuery, target.Get(key).MustString())
+			}
+		}
+
+		// collapsed rows keep their panels nested
+		c.addPanels(panel.Get("panels"))
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard_GetContent(t *testing.T) {
+	json, err := simplejson.NewJson([]byte(`{
+		"title": "Service overview",
+		"description": "Golden signals",
+		"templating": {
+			"list": [
+				{"name": "instance", "datasource": "Prometheus", "query": "label_values(up, instance)"}
+			]
+		},
+		"panels": [
+			{
+				"id": 1,
+				"title": "Requests",
+				"datasource": {"uid": "prom-uid", "type": "prometheus"},
+				"targets": [{"refId": "A", "expr": "rate(http_requests_total[5m])"}]
+			},
+			{
+				"id": 2,
+				"type": "row",
+				"title": "Details",
+				"collapsed": true,
+				"panels": [
+					{"id": 3, "title": "Logs", "targets": [{"datasource": "Loki", "expr": "{app=\"api\"}"}]}
+				]
+			}
+		],
+		"rows": [
+			{"panels": [{"id": 4, "description": "Legacy panel", "targets": [{"target": "stats.counters.hits"}]}]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	dash := NewDashboardFromJson(json)
+	content := dash.GetContent()
+
+	expected := []*DashboardContentItem{
+		{PanelId: 0, Field: DashboardContentTitle, Term: "Service overview"},
+		{PanelId: 0, Field: DashboardContentDescription, Term: "Golden signals"},
+		{PanelId: 0, Field: DashboardContentVariable, Term: "instance"},
+		{PanelId: 0, Field: DashboardContentDatasource, Term: "Prometheus"},
+		{PanelId: 0, Field: DashboardContentQuery, Term: "label_values(up, instance)"},
+		{PanelId: 1, Field: DashboardContentTitle, Term: "Requests"},
+		{PanelId: 1, Field: DashboardContentDatasource, Term: "prom-uid"},
+		{PanelId: 1, Field: DashboardContentDatasource, Term: "prometheus"},
+		{PanelId: 1, Field: DashboardContentQuery, Term: "rate(http_requests_total[5m])"},
+		{PanelId: 2, Field: DashboardContentTitle, Term: "Details"},
+		{PanelId: 3, Field: DashboardContentTitle, Term: "Logs"},
+		{PanelId: 3, Field: DashboardContentDatasource, Term: "Loki"},
+		{PanelId: 3, Field: DashboardContentQuery, Term: `{app="api"}`},
+		{PanelId: 4, Field: DashboardContentDescription, Term: "Legacy panel"},
+		{PanelId: 4, Field: DashboardContentQuery, Term: "stats.counters.hits"},
+	}
+	assert.Equal(t, expected, content)
+}
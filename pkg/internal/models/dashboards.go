@@ -51,6 +51,18 @@ var (
 		Reason:     "A Dashboard Folder cannot be added to another folder",
 		StatusCode: 400,
 	}
+	ErrDashboardFolderCannotBeMovedIntoItself = DashboardErr{
+		Reason:     "A Dashboard Folder cannot be moved into itself or one of its subfolders",
+		StatusCode: 400,
+	}
+	ErrDashboardFolderMaxDepthExceeded = DashboardErr{
+		Reason:     fmt.Sprintf("Dashboard Folders cannot be nested more than %d levels deep", MaxFolderDepth),
+		StatusCode: 400,
+	}
+	ErrDashboardFolderHasAlertRules = DashboardErr{
+		Reason:     "A Dashboard Folder that contains alert rules cannot be moved",
+		StatusCode: 400,
+	}
 	ErrDashboardsWithSameSlugExists = DashboardErr{
 		Reason:     "Multiple dashboards with the same slug exists",
 		StatusCode: 412,
@@ -134,6 +146,29 @@ func (e DashboardErr) Body() util.DynMap {
 	return util.DynMap{"status": e.Status, "message": e.Error()}
 }
 
+// DashboardSchemaError is returned when a dashboard is not valid with respect to the dashboard schema.
+type DashboardSchemaError struct {
+	Errors []DashboardSchemaErrorItem
+}
+
+// DashboardSchemaErrorItem is a single reason why a dashboard is not valid with respect to the dashboard schema.
+type DashboardSchemaErrorItem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e DashboardSchemaError) Error() string {
+	reasons := make([]string, 0, len(e.Errors))
+	for _, item := range e.Errors {
+		if item.Path == "" {
+			reasons = append(reasons, item.Message)
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", item.Path, item.Message))
+	}
+	return fmt.Sprintf("Dashboard is not valid with respect to the dashboard schema: %s", strings.Join(reasons, "; "))
+}
+
 type UpdatePluginDashboardError struct {
 	PluginId string
 }
@@ -346,6 +381,13 @@ type SaveDashboardCommand struct {
 	Result *Dashboard
 }
 
+// ValidateDashboardSchemaCommand validates a dashboard against the dashboard schema and migrates it
+// to the latest schema version, depending on the configuration. Result is the migrated dashboard.
+type ValidateDashboardSchemaCommand struct {
+	Dashboard *simplejson.Json
+	Result    *simplejson.Json
+}
+
 type TrimDashboardCommand struct {
 	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
 	Meta      *simplejson.Json `json:"meta"`
@@ -375,10 +417,11 @@ type DeleteOrphanedProvisionedDashboardsCommand struct {
 //
 
 type GetDashboardQuery struct {
-	Slug  string // required if no Id or Uid is specified
-	Id    int64  // optional if slug is set
-	Uid   string // optional if slug is set
-	OrgId int64
+	Slug     string // required if no Id or Uid is specified
+	Id       int64  // optional if slug is set
+	Uid      string // optional if slug is set
+	FolderId *int64 // optional, limits the query to the children of a folder, or to the top level if 0
+	OrgId    int64
 
 	Result *Dashboard
 }
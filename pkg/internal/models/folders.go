@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// MaxFolderDepth is the maximum number of nested folder levels, counting the top level folders as the first level.
+const MaxFolderDepth = 8
+
 // Typed errors
 var (
 	ErrFolderNotFound                = errors.New("folder not found")
@@ -18,11 +21,12 @@ var (
 )
 
 type Folder struct {
-	Id      int64
-	Uid     string
-	Title   string
-	Url     string
-	Version int
+	Id       int64
+	Uid      string
+	Title    string
+	Url      string
+	Version  int
+	ParentId int64
 
 	Created time.Time
 	Updated time.Time
@@ -58,8 +62,9 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder
 }
@@ -73,6 +78,16 @@ type UpdateFolderCommand struct {
 	Result *Folder
 }
 
+// MoveFolderCommand moves a folder, including its dashboards and subfolders, into another folder.
+// An empty ParentUid moves the folder to the top level.
+type MoveFolderCommand struct {
+	ParentUid string `json:"parentUid"`
+	Version   int    `json:"version"`
+	Overwrite bool   `json:"overwrite"`
+
+	Result *Folder
+}
+
 //
 // QUERIES
 //
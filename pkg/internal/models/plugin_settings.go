@@ -73,3 +73,6 @@ type PluginStateChangedEvent struct {
 	OrgId    int64
 	Enabled  bool
 }
+
+// PluginsChangedEvent is published when plugins are installed, uninstalled or reloaded.
+type PluginsChangedEvent struct{}
@@ -85,6 +85,7 @@ func (app *AppPlugin) InitApp(panels map[string]*PanelPlugin, dataSources map[st
 	staticRoutes := app.InitFrontendPlugin(cfg)
 
 	// check if we have child panels
+	app.FoundChildPlugins = nil
 	for _, panel := range panels {
 		if strings.HasPrefix(panel.PluginDir, app.PluginDir) {
 			panel.setPathsBasedOnApp(app, cfg)
@@ -1,6 +1,7 @@
 package grpcplugin
 
 import (
+	"io"
 	"os/exec"
 
 	datasourceV1 "github.com/grafana/grafana-plugin-model/go/datasource"
@@ -31,7 +32,7 @@ var handshake = goplugin.HandshakeConfig{
 	MagicCookieValue: grpcplugin.MagicCookieValue,
 }
 
-func newClientConfig(executablePath string, env []string, logger log.Logger,
+func newClientConfig(executablePath string, env []string, logger log.Logger, stderr io.Writer,
 	versionedPlugins map[int]goplugin.PluginSet) *goplugin.ClientConfig {
 	// We can ignore gosec G201 here, since the dynamic part of executablePath comes from the plugin definition
 	// nolint:gosec
@@ -43,6 +44,7 @@ func newClientConfig(executablePath string, env []string, logger log.Logger,
 		HandshakeConfig:  handshake,
 		VersionedPlugins: versionedPlugins,
 		Logger:           logWrapper{Logger: logger},
+		Stderr:           stderr,
 		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
 	}
 }
@@ -26,16 +26,20 @@ type grpcPlugin struct {
 	logger         log.Logger
 	mutex          sync.RWMutex
 	decommissioned bool
+	stderr         *stderrBuffer
 }
 
 // newPlugin allocates and returns a new gRPC (external) backendplugin.Plugin.
 func newPlugin(descriptor PluginDescriptor) backendplugin.PluginFactoryFunc {
 	return func(pluginID string, logger log.Logger, env []string) (backendplugin.Plugin, error) {
+		stderr := &stderrBuffer{}
 		return &grpcPlugin{
 			descriptor: descriptor,
 			logger:     logger,
+			stderr:     stderr,
 			clientFactory: func() *plugin.Client {
-				return plugin.NewClient(newClientConfig(descriptor.executablePath, env, logger, descriptor.versionedPlugins))
+				return plugin.NewClient(newClientConfig(descriptor.executablePath, env, logger, stderr,
+					descriptor.versionedPlugins))
 			},
 		}, nil
 	}
@@ -114,6 +118,11 @@ func (p *grpcPlugin) IsDecommissioned() bool {
 	return p.decommissioned
 }
 
+// Stderr returns the last lines written by the plugin process to stderr.
+func (p *grpcPlugin) Stderr() []string {
+	return p.stderr.Lines()
+}
+
 func (p *grpcPlugin) getPluginClient() (pluginClient, bool) {
 	p.mutex.RLock()
 	if p.client == nil || p.client.Exited() || p.pluginClient == nil {
@@ -0,0 +1,57 @@
+package grpcplugin
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// stderrBufferLines is the number of lines written by a plugin process to stderr that are kept.
+const stderrBufferLines = 20
+
+// stderrBuffer is an io.Writer keeping the last lines written by a plugin process to stderr.
+type stderrBuffer struct {
+	mutex   sync.Mutex
+	lines   []string
+	partial []byte
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	data := append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		b.add(string(data[:i]))
+		data = data[i+1:]
+	}
+	b.partial = append([]byte(nil), data...)
+
+	return len(p), nil
+}
+
+func (b *stderrBuffer) add(line string) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return
+	}
+
+	b.lines = append(b.lines, line)
+	if len(b.lines) > stderrBufferLines {
+		b.lines = b.lines[len(b.lines)-stderrBufferLines:]
+	}
+}
+
+// Lines returns the last lines written to the buffer, oldest first.
+func (b *stderrBuffer) Lines() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	lines := make([]string, len(b.lines))
+	copy(lines, b.lines)
+	return lines
+}
@@ -0,0 +1,34 @@
+package grpcplugin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStderrBuffer(t *testing.T) {
+	t.Run("Keeps complete lines", func(t *testing.T) {
+		b := &stderrBuffer{}
+		_, err := b.Write([]byte("first line\nsecond "))
+		require.NoError(t, err)
+		require.Equal(t, []string{"first line"}, b.Lines())
+
+		_, err = b.Write([]byte("line\r\n\nthird"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"first line", "second line"}, b.Lines())
+	})
+
+	t.Run("Keeps only the last lines", func(t *testing.T) {
+		b := &stderrBuffer{}
+		for i := 0; i < stderrBufferLines+5; i++ {
+			_, err := fmt.Fprintf(b, "line %d\n", i)
+			require.NoError(t, err)
+		}
+
+		lines := b.Lines()
+		require.Len(t, lines, stderrBufferLines)
+		require.Equal(t, "line 5", lines[0])
+		require.Equal(t, fmt.Sprintf("line %d", stderrBufferLines+4), lines[len(lines)-1])
+	})
+}
@@ -22,6 +22,8 @@ type Manager interface {
 	StartPlugin(ctx context.Context, pluginID string) error
 	// CollectMetrics collects metrics from a registered backend plugin.
 	CollectMetrics(ctx context.Context, pluginID string) (*backend.CollectMetricsResult, error)
+	// ProcessStatus returns the status of the process of a started backend plugin.
+	ProcessStatus(pluginID string) (*ProcessStatus, bool)
 	// CheckHealth checks the health of a registered backend plugin.
 	CheckHealth(ctx context.Context, pCtx backend.PluginContext) (*backend.CheckHealthResult, error)
 	// CallResource calls a plugin resource.
@@ -12,6 +12,8 @@ import (
 var (
 	pluginRequestCounter  *prometheus.CounterVec
 	pluginRequestDuration *prometheus.SummaryVec
+	pluginRestartCounter  *prometheus.CounterVec
+	pluginCrashLoopGauge  *prometheus.GaugeVec
 )
 
 func init() {
@@ -28,7 +30,33 @@ func init() {
 		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 	}, []string{"plugin_id", "endpoint"})
 
-	prometheus.MustRegister(pluginRequestCounter, pluginRequestDuration)
+	pluginRestartCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_restarts_total",
+		Help:      "The total amount of backend plugin process restarts",
+	}, []string{"plugin_id"})
+
+	pluginCrashLoopGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "plugin_crash_looping",
+		Help:      "Whether a backend plugin process is crash looping (1) or not (0)",
+	}, []string{"plugin_id"})
+
+	prometheus.MustRegister(pluginRequestCounter, pluginRequestDuration, pluginRestartCounter, pluginCrashLoopGauge)
+}
+
+// IncPluginRestarts counts a restart of the process of a backend plugin.
+func IncPluginRestarts(pluginID string) {
+	pluginRestartCounter.WithLabelValues(pluginID).Inc()
+}
+
+// SetPluginCrashLooping sets whether the process of a backend plugin is crash looping.
+func SetPluginCrashLooping(pluginID string, crashLooping bool) {
+	v := 0.0
+	if crashLooping {
+		v = 1
+	}
+	pluginCrashLoopGauge.WithLabelValues(pluginID).Set(v)
 }
 
 // instrumentPluginRequest instruments success rate and latency of `fn`
@@ -11,7 +11,6 @@ import (
 	"net/url"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -39,10 +38,15 @@ type manager struct {
 	PluginRequestValidator models.PluginRequestValidator `inject:""`
 	pluginsMu              sync.RWMutex
 	plugins                map[string]backendplugin.Plugin
+	processesMu            sync.RWMutex
+	processes              map[string]*pluginProcess
+	restartPolicy          restartPolicy
 	logger                 log.Logger
 }
 
 func (m *manager) Init() error {
+	m.processes = map[string]*pluginProcess{}
+	m.restartPolicy = defaultRestartPolicy
 	return nil
 }
 
@@ -134,6 +138,10 @@ func (m *manager) UnregisterAndStop(ctx context.Context, pluginID string) error
 
 	delete(m.plugins, pluginID)
 
+	m.processesMu.Lock()
+	delete(m.processes, pluginID)
+	m.processesMu.Unlock()
+
 	m.logger.Debug("Backend plugin unregistered", "pluginId", pluginID)
 	return nil
 }
@@ -185,7 +193,7 @@ func (m *manager) start(ctx context.Context, p backendplugin.Plugin) {
 		return
 	}
 
-	if err := startPluginAndRestartKilledProcesses(ctx, p); err != nil {
+	if err := m.startPluginAndRestartKilledProcesses(ctx, p); err != nil {
 		p.Logger().Error("Failed to start plugin", "error", err)
 	}
 }
@@ -203,7 +211,26 @@ func (m *manager) StartPlugin(ctx context.Context, pluginID string) error {
 		return errors.New("backend plugin is managed and cannot be manually started")
 	}
 
-	return startPluginAndRestartKilledProcesses(ctx, p)
+	return m.startPluginAndRestartKilledProcesses(ctx, p)
+}
+
+// ProcessStatus returns the status of the process of a started backend plugin.
+func (m *manager) ProcessStatus(pluginID string) (*backendplugin.ProcessStatus, bool) {
+	m.processesMu.RLock()
+	pp, exists := m.processes[pluginID]
+	m.processesMu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+
+	status := pp.status()
+	if p, ok := m.Get(pluginID); ok {
+		if sp, ok := p.(backendplugin.StderrProvider); ok {
+			status.Stderr = sp.Stderr()
+		}
+	}
+
+	return status, true
 }
 
 // stop stops all managed backend plugins
@@ -270,6 +297,19 @@ func (m *manager) CheckHealth(ctx context.Context, pluginContext backend.PluginC
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
 
+	if status, exists := m.ProcessStatus(pluginContext.PluginID); exists && status.State != backendplugin.ProcessStateRunning {
+		details, err := json.Marshal(status)
+		if err != nil {
+			return nil, err
+		}
+
+		return &backend.CheckHealthResult{
+			Status:      backend.HealthStatusError,
+			Message:     fmt.Sprintf("Plugin process is %s after %d restarts", status.State, status.Restarts),
+			JSONDetails: details,
+		}, nil
+	}
+
 	var resp *backend.CheckHealthResult
 	err = instrumentation.InstrumentCheckHealthRequest(p.PluginID(), func() (innerErr error) {
 		resp, innerErr = p.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pluginContext})
@@ -457,50 +497,6 @@ func flushStream(plugin backendplugin.Plugin, stream callResourceClientResponseS
 	}
 }
 
-func startPluginAndRestartKilledProcesses(ctx context.Context, p backendplugin.Plugin) error {
-	if err := p.Start(ctx); err != nil {
-		return err
-	}
-
-	go func(ctx context.Context, p backendplugin.Plugin) {
-		if err := restartKilledProcess(ctx, p); err != nil {
-			p.Logger().Error("Attempt to restart killed plugin process failed", "error", err)
-		}
-	}(ctx, p)
-
-	return nil
-}
-
-func restartKilledProcess(ctx context.Context, p backendplugin.Plugin) error {
-	ticker := time.NewTicker(time.Second * 1)
-
-	for {
-		select {
-		case <-ctx.Done():
-			if err := ctx.Err(); err != nil && !errors.Is(err, context.Canceled) {
-				return err
-			}
-			return nil
-		case <-ticker.C:
-			if p.IsDecommissioned() {
-				p.Logger().Debug("Plugin decommissioned")
-				return nil
-			}
-
-			if !p.Exited() {
-				continue
-			}
-
-			p.Logger().Debug("Restarting plugin")
-			if err := p.Start(ctx); err != nil {
-				p.Logger().Error("Failed to restart plugin", "error", err)
-				continue
-			}
-			p.Logger().Debug("Plugin restarted")
-		}
-	}
-}
-
 // callResourceClientResponseStream is used for receiving resource call responses.
 type callResourceClientResponseStream interface {
 	Recv() (*backend.CallResourceResponse, error)
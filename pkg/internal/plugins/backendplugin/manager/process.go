@@ -0,0 +1,225 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin/instrumentation"
+)
+
+// restartPolicy controls how the processes of backend plugins are restarted when they exit.
+type restartPolicy struct {
+	// checkInterval is the interval at which plugin processes are checked for having exited.
+	checkInterval time.Duration
+	// initialBackoff is the delay before the second restart of a plugin process within
+	// crashLoopWindow. The first restart is immediate, and the delay doubles for every further restart.
+	initialBackoff time.Duration
+	// maxBackoff is the maximum delay before restarting a plugin process, which is also the delay
+	// before restarting a crash looping plugin process.
+	maxBackoff time.Duration
+	// crashLoopRestarts is the number of restarts within crashLoopWindow after which a
+	// plugin process is considered to be crash looping.
+	crashLoopRestarts int
+	// crashLoopWindow is the period in which restarts are counted.
+	crashLoopWindow time.Duration
+}
+
+var defaultRestartPolicy = restartPolicy{
+	checkInterval:     time.Second,
+	initialBackoff:    time.Second,
+	maxBackoff:        5 * time.Minute,
+	crashLoopRestarts: 5,
+	crashLoopWindow:   10 * time.Minute,
+}
+
+// pluginProcess tracks the restarts of the process of a backend plugin.
+type pluginProcess struct {
+	policy restartPolicy
+	mutex  sync.RWMutex
+	state  backendplugin.ProcessState
+	// crashLooping is whether the process was restarted too many times within crashLoopWindow,
+	// independent of whether it's currently running.
+	crashLooping bool
+	restarts     []time.Time
+	total        int
+	lastExit     time.Time
+	lastErr      string
+}
+
+func newPluginProcess(policy restartPolicy) *pluginProcess {
+	return &pluginProcess{policy: policy, state: backendplugin.ProcessStateRunning}
+}
+
+// exited records that the process exited, and returns the delay before it should be restarted.
+func (pp *pluginProcess) exited(now time.Time) time.Duration {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	pp.lastExit = now
+	pp.pruneRestarts(now)
+	if pp.crashLooping {
+		pp.state = backendplugin.ProcessStateCrashLoop
+		return pp.policy.maxBackoff
+	}
+	pp.state = backendplugin.ProcessStateRestarting
+
+	n := len(pp.restarts)
+	if n == 0 {
+		return 0
+	}
+
+	backoff := pp.policy.initialBackoff
+	for i := 1; i < n && backoff < pp.policy.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > pp.policy.maxBackoff {
+		backoff = pp.policy.maxBackoff
+	}
+	return backoff
+}
+
+// restarted records an attempt to restart the process, and returns whether the process
+// entered the crash loop state.
+func (pp *pluginProcess) restarted(now time.Time, err error) bool {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	pp.restarts = append(pp.restarts, now)
+	pp.total++
+	if err != nil {
+		pp.lastErr = err.Error()
+	} else {
+		pp.lastErr = ""
+	}
+
+	entered := false
+	if len(pp.restarts) >= pp.policy.crashLoopRestarts {
+		entered = !pp.crashLooping
+		pp.crashLooping = true
+	}
+
+	switch {
+	case err == nil:
+		pp.state = backendplugin.ProcessStateRunning
+	case pp.crashLooping:
+		pp.state = backendplugin.ProcessStateCrashLoop
+	default:
+		pp.state = backendplugin.ProcessStateRestarting
+	}
+	return entered
+}
+
+// running records that the process is running, which ends the crash loop once the restarts
+// that caused it are older than crashLoopWindow. It returns whether the process left the crash loop.
+func (pp *pluginProcess) running(now time.Time) bool {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	pp.state = backendplugin.ProcessStateRunning
+	pp.pruneRestarts(now)
+	if !pp.crashLooping || len(pp.restarts) >= pp.policy.crashLoopRestarts {
+		return false
+	}
+
+	pp.crashLooping = false
+	return true
+}
+
+func (pp *pluginProcess) pruneRestarts(now time.Time) {
+	i := 0
+	for i < len(pp.restarts) && now.Sub(pp.restarts[i]) > pp.policy.crashLoopWindow {
+		i++
+	}
+	pp.restarts = pp.restarts[i:]
+}
+
+func (pp *pluginProcess) status() *backendplugin.ProcessStatus {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	return &backendplugin.ProcessStatus{
+		State:        pp.state,
+		CrashLooping: pp.crashLooping,
+		Restarts:     pp.total,
+		LastExit:     pp.lastExit,
+		LastError:    pp.lastErr,
+	}
+}
+
+func (m *manager) startPluginAndRestartKilledProcesses(ctx context.Context, p backendplugin.Plugin) error {
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+
+	pp := newPluginProcess(m.restartPolicy)
+	m.processesMu.Lock()
+	m.processes[p.PluginID()] = pp
+	m.processesMu.Unlock()
+	instrumentation.SetPluginCrashLooping(p.PluginID(), false)
+
+	go func(ctx context.Context, p backendplugin.Plugin) {
+		if err := restartKilledProcess(ctx, p, pp); err != nil {
+			p.Logger().Error("Attempt to restart killed plugin process failed", "error", err)
+		}
+	}(ctx, p)
+
+	return nil
+}
+
+func restartKilledProcess(ctx context.Context, p backendplugin.Plugin, pp *pluginProcess) error {
+	ticker := time.NewTicker(pp.policy.checkInterval)
+	defer ticker.Stop()
+
+	var restartAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			if err := ctx.Err(); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		case now := <-ticker.C:
+			if p.IsDecommissioned() {
+				p.Logger().Debug("Plugin decommissioned")
+				return nil
+			}
+
+			if !p.Exited() {
+				if pp.running(now) {
+					instrumentation.SetPluginCrashLooping(p.PluginID(), false)
+					p.Logger().Info("Plugin recovered from crash loop")
+				}
+				continue
+			}
+
+			if restartAt.IsZero() {
+				backoff := pp.exited(now)
+				restartAt = now.Add(backoff)
+				if backoff > 0 {
+					p.Logger().Debug("Plugin exited, delaying restart", "backoff", backoff)
+				}
+			}
+			if now.Before(restartAt) {
+				continue
+			}
+			restartAt = time.Time{}
+
+			p.Logger().Debug("Restarting plugin")
+			err := p.Start(ctx)
+			instrumentation.IncPluginRestarts(p.PluginID())
+			if pp.restarted(time.Now(), err) {
+				instrumentation.SetPluginCrashLooping(p.PluginID(), true)
+				p.Logger().Warn("Plugin is crash looping", "restarts", pp.policy.crashLoopRestarts,
+					"window", pp.policy.crashLoopWindow)
+			}
+			if err != nil {
+				p.Logger().Error("Failed to restart plugin", "error", err)
+				continue
+			}
+			p.Logger().Debug("Plugin restarted")
+		}
+	}
+}
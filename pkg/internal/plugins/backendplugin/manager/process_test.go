@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginProcess(t *testing.T) {
+	now := time.Now()
+	policy := defaultRestartPolicy
+	restartInitialBackoff := policy.initialBackoff
+	crashLoopRestarts := policy.crashLoopRestarts
+	crashLoopWindow := policy.crashLoopWindow
+
+	t.Run("Restarts are delayed with exponential backoff", func(t *testing.T) {
+		pp := newPluginProcess(policy)
+
+		require.Equal(t, time.Duration(0), pp.exited(now))
+		require.Equal(t, backendplugin.ProcessStateRestarting, pp.status().State)
+		pp.restarted(now, nil)
+		require.Equal(t, backendplugin.ProcessStateRunning, pp.status().State)
+
+		require.Equal(t, restartInitialBackoff, pp.exited(now))
+		pp.restarted(now, nil)
+		require.Equal(t, 2*restartInitialBackoff, pp.exited(now))
+		pp.restarted(now, nil)
+		require.Equal(t, 4*restartInitialBackoff, pp.exited(now))
+	})
+
+	t.Run("Backoff is capped", func(t *testing.T) {
+		pp := newPluginProcess(policy)
+		for i := 0; i < 30; i++ {
+			pp.exited(now)
+			pp.restarted(now, nil)
+		}
+
+		require.Equal(t, policy.maxBackoff, pp.exited(now))
+	})
+
+	t.Run("Too many restarts within the window is a crash loop", func(t *testing.T) {
+		pp := newPluginProcess(policy)
+		for i := 1; i < crashLoopRestarts; i++ {
+			pp.exited(now)
+			require.False(t, pp.restarted(now, nil))
+		}
+
+		pp.exited(now)
+		require.True(t, pp.restarted(now, errors.New("exec format error")))
+
+		status := pp.status()
+		require.Equal(t, backendplugin.ProcessStateCrashLoop, status.State)
+		require.True(t, status.CrashLooping)
+		require.Equal(t, crashLoopRestarts, status.Restarts)
+		require.Equal(t, "exec format error", status.LastError)
+
+		t.Run("Crash looping process is restarted with the maximum backoff", func(t *testing.T) {
+			require.Equal(t, policy.maxBackoff, pp.exited(now))
+			require.False(t, pp.restarted(now, nil))
+		})
+
+		t.Run("Running crash looping process is in the running state", func(t *testing.T) {
+			require.False(t, pp.running(now))
+			status := pp.status()
+			require.Equal(t, backendplugin.ProcessStateRunning, status.State)
+			require.True(t, status.CrashLooping)
+		})
+
+		t.Run("Crash loop ends once the restarts are outside the window", func(t *testing.T) {
+			require.True(t, pp.running(now.Add(crashLoopWindow+time.Second)))
+			status := pp.status()
+			require.Equal(t, backendplugin.ProcessStateRunning, status.State)
+			require.False(t, status.CrashLooping)
+			require.Equal(t, time.Duration(0), pp.exited(now.Add(crashLoopWindow+time.Second)))
+		})
+	})
+}
+
+func TestManager_CrashLoop(t *testing.T) {
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		ctx.manager.restartPolicy = restartPolicy{
+			checkInterval:     time.Millisecond,
+			initialBackoff:    time.Millisecond,
+			maxBackoff:        time.Minute,
+			crashLoopRestarts: 3,
+			crashLoopWindow:   time.Minute,
+		}
+
+		cCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := ctx.manager.RegisterAndStart(cCtx, testPluginID, ctx.factory)
+		require.NoError(t, err)
+
+		status, exists := ctx.manager.ProcessStatus(testPluginID)
+		require.True(t, exists)
+		require.Equal(t, backendplugin.ProcessStateRunning, status.State)
+
+		for i := 0; i < 3; i++ {
+			ctx.plugin.kill()
+			require.Eventually(t, func() bool { return !ctx.plugin.Exited() }, time.Second, time.Millisecond)
+		}
+
+		require.Eventually(t, func() bool {
+			status, _ := ctx.manager.ProcessStatus(testPluginID)
+			return status.CrashLooping && status.State == backendplugin.ProcessStateRunning
+		}, time.Second, time.Millisecond)
+
+		t.Run("Check health calls the running plugin", func(t *testing.T) {
+			ctx.plugin.CheckHealthHandlerFunc = func(ctx context.Context,
+				req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+				return &backend.CheckHealthResult{Status: backend.HealthStatusOk}, nil
+			}
+			res, err := ctx.manager.CheckHealth(context.Background(), backend.PluginContext{PluginID: testPluginID})
+			require.NoError(t, err)
+			require.Equal(t, backend.HealthStatusOk, res.Status)
+		})
+
+		ctx.plugin.kill()
+		require.Eventually(t, func() bool {
+			status, _ := ctx.manager.ProcessStatus(testPluginID)
+			return status.State == backendplugin.ProcessStateCrashLoop
+		}, time.Second, time.Millisecond)
+
+		t.Run("Check health reports the crash loop while the process is down", func(t *testing.T) {
+			res, err := ctx.manager.CheckHealth(context.Background(), backend.PluginContext{PluginID: testPluginID})
+			require.NoError(t, err)
+			require.Equal(t, backend.HealthStatusError, res.Status)
+			require.Equal(t, "Plugin process is crash-loop after 3 restarts", res.Message)
+
+			var details backendplugin.ProcessStatus
+			require.NoError(t, json.Unmarshal(res.JSONDetails, &details))
+			require.Equal(t, backendplugin.ProcessStateCrashLoop, details.State)
+			require.Equal(t, 3, details.Restarts)
+		})
+	})
+}
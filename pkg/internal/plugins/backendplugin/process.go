@@ -0,0 +1,37 @@
+package backendplugin
+
+import "time"
+
+// ProcessState is the state of the process of a backend plugin.
+type ProcessState string
+
+const (
+	// ProcessStateRunning is the state of a plugin process that is running.
+	ProcessStateRunning ProcessState = "running"
+	// ProcessStateRestarting is the state of a plugin process that exited and is waiting to be restarted.
+	ProcessStateRestarting ProcessState = "restarting"
+	// ProcessStateCrashLoop is the state of a plugin process that exited after being restarted too many
+	// times within a short period. It's still restarted, but with the maximum backoff.
+	ProcessStateCrashLoop ProcessState = "crash-loop"
+)
+
+// ProcessStatus describes the process of a backend plugin that is restarted by the manager when it exits.
+type ProcessStatus struct {
+	State ProcessState `json:"state"`
+	// CrashLooping is whether the process has been restarted too many times within a short period,
+	// even if it's currently running.
+	CrashLooping bool `json:"crashLooping"`
+	// Restarts is the total number of times the process has been restarted.
+	Restarts int `json:"restarts"`
+	// LastExit is the last time the process was found to have exited.
+	LastExit time.Time `json:"lastExit,omitempty"`
+	// LastError is the error of the last failed attempt to restart the process.
+	LastError string `json:"lastError,omitempty"`
+	// Stderr holds the last lines written by the process to stderr.
+	Stderr []string `json:"stderr,omitempty"`
+}
+
+// StderrProvider is implemented by plugins that keep the last lines written by their process to stderr.
+type StderrProvider interface {
+	Stderr() []string
+}
@@ -25,6 +25,8 @@ type Manager interface {
 	DataSources() []*DataSourcePlugin
 	// Apps gets all app plugins.
 	Apps() []*AppPlugin
+	// Panels gets all panel plugins.
+	Panels() []*PanelPlugin
 	// PanelCount gets the number of panels.
 	PanelCount() int
 	// AppCount gets the number of apps.
@@ -0,0 +1,247 @@
+package installer
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+const bundleManifestFile = "bundle.json"
+
+// bundledPlugin is a plugin archive of an offline bundle, resolved and verified for installation.
+type bundledPlugin struct {
+	id      string
+	version string
+	archive string
+}
+
+// InstallFromBundle installs a plugin and its dependencies from an offline bundle, which is a zip
+// archive with a bundle.json manifest and the archives of the plugins. The whole dependency tree is
+// resolved and the checksums of all archives verified before any plugin is installed.
+func (i *Installer) InstallFromBundle(ctx context.Context, bundlePath, pluginsDir string) error {
+	bundleDir, err := ioutil.TempDir("", "grafana-plugin-bundle")
+	if err != nil {
+		return errutil.Wrap("failed to create temporary directory", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(bundleDir); err != nil {
+			i.log.Warn("Failed to remove temporary directory", "dir", bundleDir, "err", err)
+		}
+	}()
+
+	if err := extractBundle(bundlePath, bundleDir); err != nil {
+		return errutil.Wrap("failed to extract plugin bundle", err)
+	}
+
+	// It's safe to ignore gosec warning G304 since the file name is hardcoded
+	// nolint:gosec
+	data, err := ioutil.ReadFile(filepath.Join(bundleDir, bundleManifestFile))
+	if err != nil {
+		return errutil.Wrap("failed to read plugin bundle manifest", err)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return errutil.Wrap("failed to parse plugin bundle manifest", err)
+	}
+	if bundle.Plugin == "" {
+		return errors.New("plugin bundle manifest doesn't declare the plugin to install")
+	}
+
+	resolved, err := i.resolveBundle(&bundle, bundleDir)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range resolved {
+		i.log.Debugf("Installing plugin %s v%s from bundle into %s", p.id, p.version, pluginsDir)
+		if err := i.extractFiles(p.archive, p.id, pluginsDir, false); err != nil {
+			return errutil.Wrapf(err, "failed to extract archive of plugin %s", p.id)
+		}
+		i.log.Successf("Installed %s v%s from bundle successfully", p.id, p.version)
+	}
+
+	return nil
+}
+
+// resolveBundle resolves the plugin of a bundle and its dependencies to the archives in the bundle,
+// verifying their checksums. The plugin of the bundle comes first.
+func (i *Installer) resolveBundle(bundle *Bundle, bundleDir string) ([]bundledPlugin, error) {
+	var resolved []bundledPlugin
+	visited := map[string]bool{}
+
+	var resolve func(pluginID, version string) error
+	resolve = func(pluginID, version string) error {
+		if visited[pluginID] {
+			return nil
+		}
+		visited[pluginID] = true
+
+		var plugin *Plugin
+		for idx := range bundle.Plugins {
+			if bundle.Plugins[idx].ID == pluginID {
+				plugin = &bundle.Plugins[idx]
+				break
+			}
+		}
+		if plugin == nil {
+			return fmt.Errorf("plugin %s is not included in the bundle: %w", pluginID, ErrPluginNotFound)
+		}
+
+		v, err := selectVersion(plugin, version)
+		if err != nil {
+			return err
+		}
+
+		meta, exists := archMeta(v)
+		if !exists || meta.SHA256 == "" {
+			return fmt.Errorf("plugin %s v%s has no checksum in the bundle", pluginID, v.Version)
+		}
+
+		archive, err := archiveURL(filepath.Join(bundleDir, bundleManifestFile), v)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to find archive of plugin %s", pluginID)
+		}
+		if !strings.HasPrefix(archive, bundleDir+string(filepath.Separator)) {
+			return fmt.Errorf("archive of plugin %s is outside of the bundle", pluginID)
+		}
+
+		if err := verifyChecksum(archive, meta.SHA256); err != nil {
+			return errutil.Wrapf(err, "failed to verify archive of plugin %s", pluginID)
+		}
+
+		res, err := readArchivedPlugin(archive, pluginID)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to read archive of plugin %s", pluginID)
+		}
+		if res.ID != pluginID {
+			return fmt.Errorf("archive of plugin %s contains plugin %s", pluginID, res.ID)
+		}
+
+		i.log.Debugf("Resolved plugin %s v%s in bundle", pluginID, v.Version)
+		resolved = append(resolved, bundledPlugin{id: pluginID, version: v.Version, archive: archive})
+
+		for _, dep := range res.Dependencies.Plugins {
+			if err := resolve(dep.ID, normalizeVersion(dep.Version)); err != nil {
+				return errutil.Wrapf(err, "failed to resolve dependency %s of plugin %s", dep.ID, pluginID)
+			}
+		}
+		return nil
+	}
+
+	if err := resolve(bundle.Plugin, bundle.Version); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// verifyChecksum verifies the SHA256 checksum of a file.
+func verifyChecksum(path, checksum string) error {
+	// We can ignore the gosec G304 warning since the path is within the extracted bundle.
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if fmt.Sprintf("%x", h.Sum(nil)) != checksum {
+		return errors.New("expected SHA256 checksum does not match the plugin archive")
+	}
+	return nil
+}
+
+// readArchivedPlugin reads the plugin.json, or dist/plugin.json, of a plugin archive.
+func readArchivedPlugin(archive, pluginID string) (InstalledPlugin, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return InstalledPlugin{}, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var pluginJSON *zip.File
+	for _, zf := range r.File {
+		switch removeGitBuildFromName(zf.Name, pluginID) {
+		case pluginID + "/dist/plugin.json":
+			pluginJSON = zf
+		case pluginID + "/plugin.json":
+			if pluginJSON == nil {
+				pluginJSON = zf
+			}
+		}
+	}
+	if pluginJSON == nil {
+		return InstalledPlugin{}, errors.New("could not find dist/plugin.json or plugin.json")
+	}
+
+	rc, err := pluginJSON.Open()
+	if err != nil {
+		return InstalledPlugin{}, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	var res InstalledPlugin
+	if err := json.NewDecoder(rc).Decode(&res); err != nil {
+		return InstalledPlugin{}, err
+	}
+	return res, nil
+}
+
+// extractBundle extracts the files of an offline bundle into dest.
+func extractBundle(bundlePath, dest string) error {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	for _, zf := range r.File {
+		name := filepath.FromSlash(zf.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) ||
+			strings.Contains(name, string(filepath.Separator)+".."+string(filepath.Separator)) {
+			return fmt.Errorf("bundle member %q tries to write outside of the bundle, this can be a security risk", zf.Name)
+		}
+
+		dstPath := filepath.Join(dest, name)
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(dstPath, 0750); err != nil {
+				return err
+			}
+			continue
+		}
+		if isSymlink(zf) {
+			return fmt.Errorf("bundle member %q is a symlink, which is not allowed", zf.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0750); err != nil {
+			return err
+		}
+		if err := extractFile(zf, dstPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
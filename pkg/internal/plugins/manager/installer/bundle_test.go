@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallFromBundle(t *testing.T) {
+	app := pluginArchive(t, "test-app", `{"id": "test-app", "type": "app", "info": {"version": "1.0.0"},
+		"dependencies": {"plugins": [{"id": "test-panel", "type": "panel", "version": "^2.0.0"}]}}`)
+	panel := pluginArchive(t, "test-panel", `{"id": "test-panel", "type": "panel", "info": {"version": "2.0.0"}}`)
+
+	t.Run("Installs the plugin and its dependencies", func(t *testing.T) {
+		bundle := writeBundle(t, Bundle{
+			Plugin: "test-app",
+			Plugins: []Plugin{
+				bundlePlugin("test-app", "1.0.0", "test-app.zip", checksum(app)),
+				bundlePlugin("test-panel", "2.0.0", "plugins/test-panel.zip", checksum(panel)),
+			},
+		}, map[string][]byte{"test-app.zip": app, "plugins/test-panel.zip": panel})
+
+		pluginsDir := t.TempDir()
+		err := New(false, "8.0.0", &fakeLogger{}).InstallFromBundle(context.Background(), bundle, pluginsDir)
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(pluginsDir, "test-app", "plugin.json"))
+		assert.FileExists(t, filepath.Join(pluginsDir, "test-panel", "plugin.json"))
+	})
+
+	t.Run("Nothing is installed if a dependency is missing", func(t *testing.T) {
+		bundle := writeBundle(t, Bundle{
+			Plugin:  "test-app",
+			Plugins: []Plugin{bundlePlugin("test-app", "1.0.0", "test-app.zip", checksum(app))},
+		}, map[string][]byte{"test-app.zip": app})
+
+		pluginsDir := t.TempDir()
+		err := New(false, "8.0.0", &fakeLogger{}).InstallFromBundle(context.Background(), bundle, pluginsDir)
+		require.ErrorIs(t, err, ErrPluginNotFound)
+		assert.NoDirExists(t, filepath.Join(pluginsDir, "test-app"))
+	})
+
+	t.Run("Nothing is installed if a checksum doesn't match", func(t *testing.T) {
+		bundle := writeBundle(t, Bundle{
+			Plugin: "test-app",
+			Plugins: []Plugin{
+				bundlePlugin("test-app", "1.0.0", "test-app.zip", checksum(app)),
+				bundlePlugin("test-panel", "2.0.0", "test-panel.zip", checksum(app)),
+			},
+		}, map[string][]byte{"test-app.zip": app, "test-panel.zip": panel})
+
+		pluginsDir := t.TempDir()
+		err := New(false, "8.0.0", &fakeLogger{}).InstallFromBundle(context.Background(), bundle, pluginsDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum")
+		assert.NoDirExists(t, filepath.Join(pluginsDir, "test-app"))
+	})
+
+	t.Run("Archives outside of the bundle are rejected", func(t *testing.T) {
+		bundle := writeBundle(t, Bundle{
+			Plugin:  "test-panel",
+			Plugins: []Plugin{bundlePlugin("test-panel", "2.0.0", "../test-panel.zip", checksum(panel))},
+		}, nil)
+
+		err := New(false, "8.0.0", &fakeLogger{}).InstallFromBundle(context.Background(), bundle, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outside of the bundle")
+	})
+}
+
+func TestInstall_StaticIndex(t *testing.T) {
+	panel := pluginArchive(t, "test-panel", `{"id": "test-panel", "type": "panel", "info": {"version": "2.0.0"}}`)
+	index, err := json.Marshal(PluginRepo{Plugins: []Plugin{
+		bundlePlugin("test-panel", "2.0.0", "zips/test-panel-2.0.0.zip", checksum(panel)),
+	}})
+	require.NoError(t, err)
+
+	t.Run("Local directory", func(t *testing.T) {
+		repoDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "zips"), 0750))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "index.json"), index, 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "zips", "test-panel-2.0.0.zip"), panel, 0600))
+
+		pluginsDir := t.TempDir()
+		err := New(false, "8.0.0", &fakeLogger{}).Install(context.Background(), "test-panel", "", pluginsDir, "",
+			filepath.Join(repoDir, "index.json"))
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(pluginsDir, "test-panel", "plugin.json"))
+	})
+
+	t.Run("HTTP server", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repo/index.json":
+				_, _ = w.Write(index)
+			case "/repo/zips/test-panel-2.0.0.zip":
+				_, _ = w.Write(panel)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		pluginsDir := t.TempDir()
+		err := New(false, "8.0.0", &fakeLogger{}).Install(context.Background(), "test-panel", "2.0.0", pluginsDir, "",
+			server.URL+"/repo/index.json")
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(pluginsDir, "test-panel", "plugin.json"))
+
+		err = New(false, "8.0.0", &fakeLogger{}).Install(context.Background(), "unknown-panel", "", pluginsDir, "",
+			server.URL+"/repo/index.json")
+		require.ErrorIs(t, err, ErrPluginNotFound)
+	})
+}
+
+func bundlePlugin(id, version, url, sha256 string) Plugin {
+	return Plugin{
+		ID: id,
+		Versions: []Version{{
+			Version: version,
+			URL:     url,
+			Arch:    map[string]ArchMeta{"any": {SHA256: sha256}},
+		}},
+	}
+}
+
+func pluginArchive(t *testing.T, pluginID, pluginJSON string) []byte {
+	t.Helper()
+	return zipFiles(t, map[string][]byte{pluginID + "/plugin.json": []byte(pluginJSON)})
+}
+
+func writeBundle(t *testing.T, bundle Bundle, files map[string][]byte) string {
+	t.Helper()
+
+	manifest, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	all := map[string][]byte{bundleManifestFile: manifest}
+	for name, data := range files {
+		all[name] = data
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, ioutil.WriteFile(path, zipFiles(t, all), 0600))
+	return path
+}
+
+func zipFiles(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func checksum(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+type fakeLogger struct{}
+
+func (*fakeLogger) Successf(format string, args ...interface{}) {}
+func (*fakeLogger) Failuref(format string, args ...interface{}) {}
+func (*fakeLogger) Info(args ...interface{})                    {}
+func (*fakeLogger) Infof(format string, args ...interface{})    {}
+func (*fakeLogger) Debug(args ...interface{})                   {}
+func (*fakeLogger) Debugf(format string, args ...interface{})   {}
+func (*fakeLogger) Warn(args ...interface{})                    {}
+func (*fakeLogger) Warnf(format string, args ...interface{})    {}
+func (*fakeLogger) Error(args ...interface{})                   {}
+func (*fakeLogger) Errorf(format string, args ...interface{})   {}
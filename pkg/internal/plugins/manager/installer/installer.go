@@ -102,7 +102,8 @@ func (i *Installer) Install(ctx context.Context, pluginID, version, pluginsDir,
 
 	var checksum string
 	if pluginZipURL == "" {
-		if strings.HasPrefix(pluginID, "grafana-") {
+		staticIndex := isStaticIndex(pluginRepoURL)
+		if strings.HasPrefix(pluginID, "grafana-") && !staticIndex {
 			// At this point the plugin download is going through grafana.com API and thus the name is validated.
 			// Checking for grafana prefix is how it is done there so no 3rd party plugin should have that prefix.
 			// You can supply custom plugin name and then set custom download url to 3rd party plugin but then that
@@ -127,14 +128,15 @@ func (i *Installer) Install(ctx context.Context, pluginID, version, pluginsDir,
 			pluginID,
 			version,
 		)
+		if staticIndex {
+			if pluginZipURL, err = archiveURL(pluginRepoURL, v); err != nil {
+				return errutil.Wrapf(err, "failed to find archive of plugin %s", pluginID)
+			}
+		}
 
 		// Plugins which are downloaded just as sourcecode zipball from github do not have checksum
-		if v.Arch != nil {
-			archMeta, exists := v.Arch[osAndArchString()]
-			if !exists {
-				archMeta = v.Arch["any"]
-			}
-			checksum = archMeta.SHA256
+		if meta, exists := archMeta(v); exists {
+			checksum = meta.SHA256
 		}
 	}
 
@@ -218,10 +220,14 @@ func (i *Installer) DownloadFile(pluginID string, tmpFile *os.File, url string,
 				i.log.Warn("Failed to close file", "err", err)
 			}
 		}()
-		_, err = io.Copy(tmpFile, f)
+		h := sha256.New()
+		_, err = io.Copy(tmpFile, io.TeeReader(f, h))
 		if err != nil {
 			return errutil.Wrap("Failed to copy plugin archive", err)
 		}
+		if len(checksum) > 0 && checksum != fmt.Sprintf("%x", h.Sum(nil)) {
+			return fmt.Errorf("expected SHA256 checksum does not match the plugin archive %q", url)
+		}
 		return nil
 	}
 
@@ -280,6 +286,10 @@ func (i *Installer) DownloadFile(pluginID string, tmpFile *os.File, url string,
 }
 
 func (i *Installer) getPluginMetadataFromPluginRepo(pluginID, pluginRepoURL string) (Plugin, error) {
+	if isStaticIndex(pluginRepoURL) {
+		return i.getPluginMetadataFromStaticIndex(pluginID, pluginRepoURL)
+	}
+
 	i.log.Debugf("Fetching metadata for plugin \"%s\" from repo %s", pluginID, pluginRepoURL)
 	body, err := i.sendRequestGetBytes(pluginRepoURL, "repo", pluginID)
 	if err != nil {
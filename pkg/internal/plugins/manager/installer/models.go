@@ -40,9 +40,20 @@ type Version struct {
 
 type ArchMeta struct {
 	SHA256 string `json:"sha256"`
+	// URL is the URL of the archive of the version for the architecture, in a static repository index.
+	URL string `json:"url,omitempty"`
 }
 
 type PluginRepo struct {
 	Plugins []Plugin `json:"plugins"`
 	Version string   `json:"version"`
 }
+
+// Bundle is the manifest of an offline plugin bundle, stored as bundle.json at the root of the bundle.
+// Plugins lists the versions of the plugin and of its dependencies that are included in the bundle,
+// with the URLs of their archives relative to the root of the bundle.
+type Bundle struct {
+	Plugin  string   `json:"plugin"`
+	Version string   `json:"version"`
+	Plugins []Plugin `json:"plugins"`
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// isStaticIndex returns whether the plugin repository is a static index, that is a JSON file listing
+// the plugins of a private repository, rather than a grafana.com-style API.
+func isStaticIndex(pluginRepoURL string) bool {
+	u, err := url.Parse(pluginRepoURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(u.Path, ".json")
+}
+
+// isRemote returns whether the location is an HTTP URL rather than a local path.
+func isRemote(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// localPath returns the local path of a location given as a path or a file:// URL.
+func localPath(location string) string {
+	if u, err := url.Parse(location); err == nil && u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	return location
+}
+
+// getPluginMetadataFromStaticIndex looks up a plugin in the static index of a private repository.
+// The index is a PluginRepo, whose plugin versions are sorted with the newest first.
+func (i *Installer) getPluginMetadataFromStaticIndex(pluginID, indexURL string) (Plugin, error) {
+	i.log.Debugf("Fetching metadata for plugin \"%s\" from static index %s", pluginID, indexURL)
+
+	var data []byte
+	var err error
+	if isRemote(indexURL) {
+		data, err = i.sendRequestGetBytes(indexURL)
+	} else {
+		// We can ignore the gosec G304 warning since the index stems from the command line flag "repo".
+		// nolint:gosec
+		data, err = ioutil.ReadFile(localPath(indexURL))
+	}
+	if err != nil {
+		return Plugin{}, fmt.Errorf("failed to read plugin repository index %q: %w", indexURL, err)
+	}
+
+	var repo PluginRepo
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return Plugin{}, fmt.Errorf("failed to parse plugin repository index %q: %w", indexURL, err)
+	}
+
+	for _, p := range repo.Plugins {
+		if p.ID == pluginID {
+			return p, nil
+		}
+	}
+
+	i.log.Errorf("failed to find plugin '%s' in plugin repository. Please check if plugin ID is correct", pluginID)
+	return Plugin{}, ErrPluginNotFound
+}
+
+// archMeta returns the metadata of a version for the current OS and architecture, if any.
+func archMeta(v *Version) (ArchMeta, bool) {
+	if v.Arch == nil {
+		return ArchMeta{}, false
+	}
+
+	meta, exists := v.Arch[osAndArchString()]
+	if !exists {
+		meta, exists = v.Arch["any"]
+	}
+	return meta, exists
+}
+
+// archiveURL returns the URL of the archive of a version listed in a static index, resolving
+// relative URLs against the location of the index.
+func archiveURL(indexURL string, v *Version) (string, error) {
+	archive := v.URL
+	if meta, exists := archMeta(v); exists && meta.URL != "" {
+		archive = meta.URL
+	}
+	if archive == "" {
+		return "", fmt.Errorf("no archive URL for version %s", v.Version)
+	}
+
+	ref, err := url.Parse(archive)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() {
+		return localPathOrURL(archive), nil
+	}
+	if filepath.IsAbs(archive) {
+		return archive, nil
+	}
+
+	if isRemote(indexURL) {
+		base, err := url.Parse(indexURL)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	return filepath.Join(filepath.Dir(localPath(indexURL)), filepath.FromSlash(archive)), nil
+}
+
+func localPathOrURL(location string) string {
+	if isRemote(location) {
+		return location
+	}
+	return localPath(location)
+}
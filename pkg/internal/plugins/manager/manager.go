@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/internal/bus"
 	"github.com/grafana/grafana/pkg/internal/infra/fs"
 	"github.com/grafana/grafana/pkg/internal/infra/log"
 	"github.com/grafana/grafana/pkg/internal/infra/metrics"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/internal/plugins/manager/installer"
 	"github.com/grafana/grafana/pkg/internal/registry"
+	"github.com/grafana/grafana/pkg/internal/schema/load"
 	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
 	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/grafana/grafana/pkg/internal/util"
@@ -49,6 +51,7 @@ type PluginScanner struct {
 	log                           log.Logger
 	plugins                       map[string]*plugins.PluginBase
 	allowUnsignedPluginsCondition unsignedPluginConditionFunc
+	signingKeys                   []*signingKey
 }
 
 type PluginManager struct {
@@ -64,7 +67,9 @@ type PluginManager struct {
 	AllowUnsignedPluginsCondition unsignedPluginConditionFunc
 	grafanaLatestVersion          string
 	grafanaHasUpdate              bool
-	pluginScanningErrors          map[string]plugins.PluginError
+	// pluginScanningErrors maps the directories of the plugins rejected by signature validation to their errors.
+	pluginScanningErrors map[string]plugins.PluginError
+	signingKeys          []*signingKey
 
 	renderer     *plugins.RendererPlugin
 	dataSources  map[string]*plugins.DataSourcePlugin
@@ -73,6 +78,9 @@ type PluginManager struct {
 	apps         map[string]*plugins.AppPlugin
 	staticRoutes []*plugins.PluginStaticRoute
 	pluginsMu    sync.RWMutex
+
+	// pluginFingerprints maps the directories of external plugins to a fingerprint of their files, when hot reload is enabled.
+	pluginFingerprints map[string]string
 }
 
 func init() {
@@ -99,6 +107,12 @@ func (pm *PluginManager) Init() error {
 	pm.pluginScanningErrors = map[string]plugins.PluginError{}
 	pm.pluginInstaller = installer.New(false, pm.Cfg.BuildVersion, installerLog)
 
+	signingKeys, err := loadSigningKeys(pm.Cfg.PluginSigningKeys)
+	if err != nil {
+		return err
+	}
+	pm.signingKeys = signingKeys
+
 	pm.log.Info("Starting plugin search")
 
 	plugDir := filepath.Join(pm.Cfg.StaticRootPath, "app/plugins")
@@ -152,29 +166,52 @@ func (pm *PluginManager) initExternalPlugins() error {
 		return err
 	}
 
+	staticRoutes := pm.initFrontendPlugins(nil)
+	pm.pluginsMu.Lock()
+	pm.staticRoutes = staticRoutes
+	pm.pluginsMu.Unlock()
+
+	return nil
+}
+
+// initFrontendPlugins initializes the frontend of the plugins matching filter, or of all plugins if filter is nil,
+// and returns their static routes.
+func (pm *PluginManager) initFrontendPlugins(filter func(p *plugins.PluginBase) bool) []*plugins.PluginStaticRoute {
+	matches := func(p *plugins.PluginBase) bool {
+		return filter == nil || filter(p)
+	}
+
 	var staticRoutesList []*plugins.PluginStaticRoute
 	for _, panel := range pm.Panels() {
-		staticRoutes := panel.InitFrontendPlugin(pm.Cfg)
-		staticRoutesList = append(staticRoutesList, staticRoutes...)
+		if matches(&panel.PluginBase) {
+			staticRoutes := panel.InitFrontendPlugin(pm.Cfg)
+			staticRoutesList = append(staticRoutesList, staticRoutes...)
+		}
 	}
 
 	for _, ds := range pm.DataSources() {
-		staticRoutes := ds.InitFrontendPlugin(pm.Cfg)
-		staticRoutesList = append(staticRoutesList, staticRoutes...)
+		if matches(&ds.PluginBase) {
+			staticRoutes := ds.InitFrontendPlugin(pm.Cfg)
+			staticRoutesList = append(staticRoutesList, staticRoutes...)
+		}
 	}
 
 	for _, app := range pm.Apps() {
-		staticRoutes := app.InitApp(pm.panels, pm.dataSources, pm.Cfg)
-		staticRoutesList = append(staticRoutesList, staticRoutes...)
+		if matches(&app.PluginBase) {
+			staticRoutes := app.InitApp(pm.panels, pm.dataSources, pm.Cfg)
+			staticRoutesList = append(staticRoutesList, staticRoutes...)
+		}
 	}
 
-	if pm.Renderer() != nil {
-		staticRoutes := pm.renderer.InitFrontendPlugin(pm.Cfg)
+	if renderer := pm.Renderer(); renderer != nil && matches(&renderer.PluginBase) {
+		staticRoutes := renderer.InitFrontendPlugin(pm.Cfg)
 		staticRoutesList = append(staticRoutesList, staticRoutes...)
 	}
-	pm.staticRoutes = staticRoutesList
 
 	for _, p := range pm.Plugins() {
+		if !matches(p) {
+			continue
+		}
 		if p.IsCorePlugin {
 			p.Signature = plugins.PluginSignatureInternal
 		} else {
@@ -182,10 +219,14 @@ func (pm *PluginManager) initExternalPlugins() error {
 		}
 	}
 
-	return nil
+	return staticRoutesList
 }
 
 func (pm *PluginManager) Run(ctx context.Context) error {
+	if pm.Cfg.PluginsHotReload {
+		go pm.watchPlugins(ctx)
+	}
+
 	pm.checkForUpdates()
 
 	ticker := time.NewTicker(time.Minute * 10)
@@ -334,6 +375,7 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 		log:                           pm.log,
 		plugins:                       map[string]*plugins.PluginBase{},
 		allowUnsignedPluginsCondition: pm.AllowUnsignedPluginsCondition,
+		signingKeys:                   pm.signingKeys,
 	}
 
 	// 1st pass: Scan plugins, also mapping plugins to their respective directories
@@ -402,7 +444,9 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 		if signingError != nil {
 			pm.log.Debug("Failed to validate plugin signature. Will skip loading", "id", plugin.Id,
 				"signature", plugin.Signature, "status", signingError.ErrorCode)
-			pm.pluginScanningErrors[plugin.Id] = *signingError
+			pm.pluginsMu.Lock()
+			pm.pluginScanningErrors[plugin.PluginDir] = plugins.PluginError{ErrorCode: signingError.ErrorCode, PluginID: plugin.Id}
+			pm.pluginsMu.Unlock()
 			continue
 		}
 
@@ -468,6 +512,10 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 		return err
 	}
 
+	if p, ok := plug.(*plugins.PanelPlugin); ok {
+		pm.loadPanelSchema(p, pluginBase.PluginDir, scanner)
+	}
+
 	pm.pluginsMu.Lock()
 	defer pm.pluginsMu.Unlock()
 
@@ -512,12 +560,37 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 	pb.Signature = pluginBase.Signature
 	pb.SignatureType = pluginBase.SignatureType
 	pb.SignatureOrg = pluginBase.SignatureOrg
+	pb.SignatureKey = pluginBase.SignatureKey
 
 	pm.plugins[pb.Id] = pb
 	pm.log.Debug("Successfully added plugin", "id", pb.Id)
 	return nil
 }
 
+// loadPanelSchema loads the family of schema declared in the models.cue file of a non-core panel plugin, if any.
+// Core panel plugins are part of the dashboard schema already. Plugins with invalid models are loaded without
+// a schema.
+func (pm *PluginManager) loadPanelSchema(p *plugins.PanelPlugin, pluginDir string, scanner *PluginScanner) {
+	if strings.HasPrefix(pluginDir, pm.Cfg.StaticRootPath) {
+		return
+	}
+
+	exists, err := fs.Exists(filepath.Join(pluginDir, "models.cue"))
+	if err != nil || !exists {
+		return
+	}
+
+	fam, err := load.PanelPluginFamily(load.GetDefaultLoadPaths(), os.DirFS(pluginDir))
+	if err != nil {
+		pm.log.Warn("Failed to load panel plugin schema", "id", p.Id, "err", err)
+		scanner.errors = append(scanner.errors, fmt.Errorf("failed to load schema of plugin %q: %w", p.Id, err))
+		return
+	}
+
+	pm.log.Debug("Loaded panel plugin schema", "id", p.Id)
+	p.Schema = fam
+}
+
 func (s *PluginScanner) walker(currentPath string, f os.FileInfo, err error) error {
 	// We scan all the subfolders for plugin.json (with some exceptions) so that we also load embedded plugins, for
 	// example https://github.com/raintank/worldping-app/tree/master/dist/grafana-worldmap-panel worldmap panel plugin
@@ -579,7 +652,7 @@ func (s *PluginScanner) loadPlugin(pluginJSONFilePath string) error {
 		return err
 	}
 
-	signatureState, err := getPluginSignatureState(s.log, &pluginCommon)
+	signatureState, err := getPluginSignatureState(s.log, &pluginCommon, s.signingKeys...)
 	if err != nil {
 		s.log.Warn("Could not get plugin signature state", "pluginID", pluginCommon.Id, "err", err)
 		return err
@@ -587,6 +660,7 @@ func (s *PluginScanner) loadPlugin(pluginJSONFilePath string) error {
 	pluginCommon.Signature = signatureState.Status
 	pluginCommon.SignatureType = signatureState.Type
 	pluginCommon.SignatureOrg = signatureState.SigningOrg
+	pluginCommon.SignatureKey = signatureState.SigningKey
 
 	s.plugins[currentDir] = &pluginCommon
 
@@ -678,12 +752,11 @@ func (s *PluginScanner) allowUnsigned(plugin *plugins.PluginBase) bool {
 
 // ScanningErrors returns plugin scanning errors encountered.
 func (pm *PluginManager) ScanningErrors() []plugins.PluginError {
-	scanningErrs := make([]plugins.PluginError, 0)
-	for id, e := range pm.pluginScanningErrors {
-		scanningErrs = append(scanningErrs, plugins.PluginError{
-			ErrorCode: e.ErrorCode,
-			PluginID:  id,
-		})
+	pm.pluginsMu.RLock()
+	defer pm.pluginsMu.RUnlock()
+	scanningErrs := make([]plugins.PluginError, 0, len(pm.pluginScanningErrors))
+	for _, e := range pm.pluginScanningErrors {
+		scanningErrs = append(scanningErrs, e)
 	}
 	return scanningErrs
 }
@@ -764,6 +837,9 @@ func (pm *PluginManager) GetDataPlugin(id string) plugins.DataPlugin {
 }
 
 func (pm *PluginManager) StaticRoutes() []*plugins.PluginStaticRoute {
+	pm.pluginsMu.RLock()
+	defer pm.pluginsMu.RUnlock()
+
 	return pm.staticRoutes
 }
 
@@ -798,6 +874,7 @@ func (pm *PluginManager) Install(ctx context.Context, pluginID, version string)
 		return err
 	}
 
+	pm.publishPluginsChanged()
 	return nil
 }
 
@@ -828,10 +905,19 @@ func (pm *PluginManager) Uninstall(ctx context.Context, pluginID string) error {
 	if err != nil {
 		return err
 	}
+	pm.publishPluginsChanged()
 
 	return pm.pluginInstaller.Uninstall(ctx, pluginID, pm.Cfg.PluginsPath)
 }
 
+// publishPluginsChanged notifies the listeners, such as the dashboard schema loader, that plugins were installed,
+// uninstalled or reloaded.
+func (pm *PluginManager) publishPluginsChanged() {
+	if err := bus.Publish(&models.PluginsChangedEvent{}); err != nil {
+		pm.log.Warn("Failed to publish plugins changed event", "error", err)
+	}
+}
+
 func (pm *PluginManager) unregister(plugin *plugins.PluginBase) error {
 	pm.pluginsMu.Lock()
 	defer pm.pluginsMu.Unlock()
@@ -854,11 +940,14 @@ func (pm *PluginManager) unregister(plugin *plugins.PluginBase) error {
 	return nil
 }
 
+// removeStaticRoute removes the static routes of a plugin. The routes are copied since the callers of StaticRoutes
+// may still use the previous routes.
 func (pm *PluginManager) removeStaticRoute(pluginID string) {
-	for i, route := range pm.staticRoutes {
-		if pluginID == route.PluginId {
-			pm.staticRoutes = append(pm.staticRoutes[:i], pm.staticRoutes[i+1:]...)
-			return
+	staticRoutes := make([]*plugins.PluginStaticRoute, 0, len(pm.staticRoutes))
+	for _, route := range pm.staticRoutes {
+		if pluginID != route.PluginId {
+			staticRoutes = append(staticRoutes, route)
 		}
 	}
+	pm.staticRoutes = staticRoutes
 }
@@ -126,6 +126,23 @@ func TestPluginManager_Init(t *testing.T) {
 		assert.True(t, errors.Is(pm.scanningErrors[0], plugins.DuplicatePluginError{}))
 	})
 
+	t.Run("With external panel plugins shipping models", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = "testdata/panel-with-schema"
+			pm.Cfg.PluginsAllowUnsigned = []string{"test-schema-panel", "test-invalid-schema-panel"}
+		})
+		err := pm.Init()
+		require.NoError(t, err)
+
+		require.NotNil(t, pm.panels["test-schema-panel"])
+		assert.NotNil(t, pm.panels["test-schema-panel"].Schema)
+
+		require.NotNil(t, pm.panels["test-invalid-schema-panel"], "plugins with invalid models should still be loaded")
+		assert.Nil(t, pm.panels["test-invalid-schema-panel"].Schema)
+		require.Len(t, pm.scanningErrors, 1)
+		assert.Contains(t, pm.scanningErrors[0].Error(), `failed to load schema of plugin "test-invalid-schema-panel"`)
+	})
+
 	t.Run("With external back-end plugin with valid v2 signature", func(t *testing.T) {
 		const pluginsDir = "testdata/valid-v2-signature"
 		const pluginFolder = pluginsDir + "/plugin"
@@ -172,6 +189,7 @@ func TestPluginManager_Init(t *testing.T) {
 				Signature:     plugins.PluginSignatureValid,
 				SignatureType: plugins.GrafanaType,
 				SignatureOrg:  "Grafana Labs",
+				SignatureKey:  "grafana",
 				Dependencies: plugins.PluginDependencies{
 					GrafanaVersion: "*",
 					Plugins:        []plugins.PluginDependencyItem{},
@@ -361,6 +379,7 @@ func TestPluginManager_Installer(t *testing.T) {
 			Signature:     plugins.PluginSignatureValid,
 			SignatureType: plugins.GrafanaType,
 			SignatureOrg:  "Grafana Labs",
+			SignatureKey:  "grafana",
 			Dependencies: plugins.PluginDependencies{
 				GrafanaVersion: "*",
 				Plugins:        []plugins.PluginDependencyItem{},
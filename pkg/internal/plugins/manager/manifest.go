@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
@@ -48,6 +49,51 @@ N1c5v9v/4h6qeA==
 -----END PGP PUBLIC KEY BLOCK-----
 `
 
+// grafanaSigningKeyName is the name of the key Grafana signs plugins with.
+const grafanaSigningKeyName = "grafana"
+
+// signingKey is a public key trusted to sign plugins, besides Grafana's own key.
+type signingKey struct {
+	name    string
+	keyring openpgp.EntityList
+	// signatureTypes restricts the signature types of the plugins the key may sign, if not empty.
+	signatureTypes []plugins.PluginSignatureType
+	// rootURLs restricts the key to Grafana instances running at one of the URLs, if not empty.
+	rootURLs []string
+}
+
+// loadSigningKeys reads the public keys configured to be trusted to sign plugins.
+func loadSigningKeys(cfgKeys []setting.PluginSigningKey) ([]*signingKey, error) {
+	keys := make([]*signingKey, 0, len(cfgKeys))
+	for _, k := range cfgKeys {
+		if k.Name == grafanaSigningKeyName {
+			return nil, fmt.Errorf("plugin signing key name %q is reserved", k.Name)
+		}
+
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path comes from the configuration.
+		f, err := os.Open(k.PublicKeyPath)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to open plugin signing key %q", k.Name)
+		}
+		keyring, err := openpgp.ReadArmoredKeyRing(f)
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to read plugin signing key %q", k.Name)
+		}
+
+		key := &signingKey{name: k.Name, keyring: keyring, rootURLs: k.RootURLs}
+		for _, t := range k.SignatureTypes {
+			key.signatureTypes = append(key.signatureTypes, plugins.PluginSignatureType(t))
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
 // pluginManifest holds details for the file manifest
 type pluginManifest struct {
 	Plugin  string            `json:"plugin"`
@@ -62,15 +108,18 @@ type pluginManifest struct {
 	SignedByOrg     string                      `json:"signedByOrg"`
 	SignedByOrgName string                      `json:"signedByOrgName"`
 	RootURLs        []string                    `json:"rootUrls"`
+
+	// signingKey is the trusted key the manifest was signed with, or nil for Grafana's key.
+	signingKey *signingKey
 }
 
 func (m *pluginManifest) isV2() bool {
 	return strings.HasPrefix(m.ManifestVersion, "2.")
 }
 
-// readPluginManifest attempts to read and verify the plugin manifest
-// if any error occurs or the manifest is not valid, this will return an error
-func readPluginManifest(body []byte) (*pluginManifest, error) {
+// readPluginManifest attempts to read and verify the plugin manifest, against Grafana's key
+// and then the trusted keys. If any error occurs or the manifest is not valid, this will return an error
+func readPluginManifest(body []byte, trustedKeys ...*signingKey) (*pluginManifest, error) {
 	block, _ := clearsign.Decode(body)
 	if block == nil {
 		return nil, errors.New("unable to decode manifest")
@@ -88,17 +137,89 @@ func readPluginManifest(body []byte) (*pluginManifest, error) {
 		return nil, errutil.Wrap("failed to parse public key", err)
 	}
 
-	if _, err := openpgp.CheckDetachedSignature(keyring,
-		bytes.NewBuffer(block.Bytes),
-		block.ArmoredSignature.Body); err != nil {
+	if err := checkManifestSignature(body, keyring); err != nil {
+		for _, key := range trustedKeys {
+			if checkManifestSignature(body, key.keyring) == nil {
+				manifest.signingKey = key
+				return manifest, nil
+			}
+		}
 		return nil, errutil.Wrap("failed to check signature", err)
 	}
 
 	return manifest, nil
 }
 
+// checkManifestSignature checks the signature of a clearsigned manifest against a keyring.
+func checkManifestSignature(body []byte, keyring openpgp.KeyRing) error {
+	block, _ := clearsign.Decode(body)
+	if block == nil {
+		return errors.New("unable to decode manifest")
+	}
+
+	_, err := openpgp.CheckDetachedSignature(keyring, bytes.NewBuffer(block.Bytes), block.ArmoredSignature.Body)
+	return err
+}
+
+// matchesAppURL returns whether one of the root URLs is the URL Grafana is running at.
+func matchesAppURL(log log.Logger, pluginID string, rootURLs []string) (bool, error) {
+	appURL, err := url.Parse(setting.AppUrl)
+	if err != nil {
+		return false, err
+	}
+
+	for _, u := range rootURLs {
+		rootURL, err := url.Parse(u)
+		if err != nil {
+			log.Warn("Could not parse plugin root URL", "plugin", pluginID, "rootUrl", rootURL)
+			return false, err
+		}
+		if rootURL.Scheme == appURL.Scheme &&
+			rootURL.Host == appURL.Host &&
+			rootURL.RequestURI() == appURL.RequestURI() {
+			return true, nil
+		}
+	}
+
+	log.Warn("Could not find root URL that matches running application URL", "plugin", pluginID,
+		"appUrl", appURL, "rootUrls", rootURLs)
+	return false, nil
+}
+
+// isTrusted returns whether the trusted key a manifest was signed with may sign the plugin. A key trusted for all
+// signature types isn't trusted for the grafana type, which only Grafana's own key signs unless configured otherwise.
+func (k *signingKey) isTrusted(log log.Logger, manifest *pluginManifest) (bool, error) {
+	if len(k.signatureTypes) == 0 && manifest.SignatureType == plugins.GrafanaType {
+		log.Warn("Plugin signing key isn't trusted for the grafana signature type", "plugin", manifest.Plugin,
+			"key", k.name)
+		return false, nil
+	}
+
+	if len(k.signatureTypes) > 0 {
+		allowed := false
+		for _, t := range k.signatureTypes {
+			if t == manifest.SignatureType {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			log.Warn("Plugin signing key isn't trusted for the signature type", "plugin", manifest.Plugin,
+				"key", k.name, "signatureType", manifest.SignatureType)
+			return false, nil
+		}
+	}
+
+	if len(k.rootURLs) > 0 {
+		return matchesAppURL(log, manifest.Plugin, k.rootURLs)
+	}
+
+	return true, nil
+}
+
 // getPluginSignatureState returns the signature state for a plugin.
-func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugins.PluginSignatureState, error) {
+func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase, trustedKeys ...*signingKey) (
+	plugins.PluginSignatureState, error) {
 	log.Debug("Getting signature state of plugin", "plugin", plugin.Id, "isBackend", plugin.Backend)
 	manifestPath := filepath.Join(plugin.PluginDir, "MANIFEST.txt")
 
@@ -113,7 +234,7 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		}, nil
 	}
 
-	manifest, err := readPluginManifest(byteValue)
+	manifest, err := readPluginManifest(byteValue, trustedKeys...)
 	if err != nil {
 		log.Debug("Plugin signature invalid", "id", plugin.Id)
 		return plugins.PluginSignatureState{
@@ -128,31 +249,27 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		}, nil
 	}
 
-	// Validate that private is running within defined root URLs
-	if manifest.SignatureType == plugins.PrivateType {
-		appURL, err := url.Parse(setting.AppUrl)
+	// Validate that the trusted key the plugin was signed with is trusted for the plugin
+	if manifest.signingKey != nil {
+		trusted, err := manifest.signingKey.isTrusted(log, manifest)
 		if err != nil {
 			return plugins.PluginSignatureState{}, err
 		}
+		if !trusted {
+			return plugins.PluginSignatureState{
+				Status: plugins.PluginSignatureInvalid,
+			}, nil
+		}
+	}
 
-		foundMatch := false
-		for _, u := range manifest.RootURLs {
-			rootURL, err := url.Parse(u)
-			if err != nil {
-				log.Warn("Could not parse plugin root URL", "plugin", plugin.Id, "rootUrl", rootURL)
-				return plugins.PluginSignatureState{}, err
-			}
-			if rootURL.Scheme == appURL.Scheme &&
-				rootURL.Host == appURL.Host &&
-				rootURL.RequestURI() == appURL.RequestURI() {
-				foundMatch = true
-				break
-			}
+	// Validate that private is running within defined root URLs
+	if manifest.SignatureType == plugins.PrivateType {
+		foundMatch, err := matchesAppURL(log, plugin.Id, manifest.RootURLs)
+		if err != nil {
+			return plugins.PluginSignatureState{}, err
 		}
 
 		if !foundMatch {
-			log.Warn("Could not find root URL that matches running application URL", "plugin", plugin.Id,
-				"appUrl", appURL, "rootUrls", manifest.RootURLs)
 			return plugins.PluginSignatureState{
 				Status: plugins.PluginSignatureInvalid,
 			}, nil
@@ -217,11 +334,17 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		}
 	}
 
+	signingKeyName := grafanaSigningKeyName
+	if manifest.signingKey != nil {
+		signingKeyName = manifest.signingKey.name
+	}
+
 	// Everything OK
-	log.Debug("Plugin signature valid", "id", plugin.Id)
+	log.Debug("Plugin signature valid", "id", plugin.Id, "key", signingKeyName)
 	return plugins.PluginSignatureState{
 		Status:     plugins.PluginSignatureValid,
 		Type:       manifest.SignatureType,
 		SigningOrg: manifest.SignedByOrgName,
+		SigningKey: signingKeyName,
 	}, nil
 }
@@ -1,13 +1,25 @@
 package manager
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/internal/infra/log"
 	"github.com/grafana/grafana/pkg/internal/plugins"
+	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
 )
 
 func TestReadPluginManifest(t *testing.T) {
@@ -121,3 +133,117 @@ func fileList(manifest *pluginManifest) []string {
 	sort.Strings(keys)
 	return keys
 }
+
+func TestGetPluginSignatureState_TrustedKeys(t *testing.T) {
+	entity, err := openpgp.NewEntity("Internal", "", "plugins@example.com", nil)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "internal.asc")
+	keyFile, err := os.Create(keyPath)
+	require.NoError(t, err)
+	armorWriter, err := armor.Encode(keyFile, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(armorWriter))
+	require.NoError(t, armorWriter.Close())
+	require.NoError(t, keyFile.Close())
+
+	origAppURL := setting.AppUrl
+	setting.AppUrl = "http://localhost:3000/"
+	t.Cleanup(func() { setting.AppUrl = origAppURL })
+
+	signedPlugin := func(t *testing.T, signatureType plugins.PluginSignatureType) *plugins.PluginBase {
+		t.Helper()
+
+		pluginDir := t.TempDir()
+		pluginJSON := []byte(`{"id": "test", "type": "datasource", "info": {"version": "1.0.0"}}`)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "plugin.json"), pluginJSON, 0600))
+
+		manifest, err := json.Marshal(pluginManifest{
+			Plugin:          "test",
+			Version:         "1.0.0",
+			ManifestVersion: "2.0.0",
+			SignatureType:   signatureType,
+			SignedByOrgName: "Internal",
+			RootURLs:        []string{"http://localhost:3000/"},
+			Files:           map[string]string{"plugin.json": fmt.Sprintf("%x", sha256.Sum256(pluginJSON))},
+		})
+		require.NoError(t, err)
+
+		var signed bytes.Buffer
+		w, err := clearsign.Encode(&signed, entity.PrivateKey, nil)
+		require.NoError(t, err)
+		_, err = w.Write(manifest)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "MANIFEST.txt"), signed.Bytes(), 0600))
+
+		return &plugins.PluginBase{
+			Id:        "test",
+			Info:      plugins.PluginInfo{Version: "1.0.0"},
+			PluginDir: pluginDir,
+			Files:     []string{"plugin.json"},
+		}
+	}
+
+	loadKeys := func(t *testing.T, key setting.PluginSigningKey) []*signingKey {
+		t.Helper()
+		key.Name = "internal"
+		key.PublicKeyPath = keyPath
+		keys, err := loadSigningKeys([]setting.PluginSigningKey{key})
+		require.NoError(t, err)
+		return keys
+	}
+
+	t.Run("Plugin signed with an untrusted key is invalid", func(t *testing.T) {
+		state, err := getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.PrivateType))
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureInvalid, state.Status)
+	})
+
+	t.Run("Plugin signed with a trusted key is valid", func(t *testing.T) {
+		state, err := getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.PrivateType),
+			loadKeys(t, setting.PluginSigningKey{})...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureValid, state.Status)
+		assert.Equal(t, plugins.PrivateType, state.Type)
+		assert.Equal(t, "Internal", state.SigningOrg)
+		assert.Equal(t, "internal", state.SigningKey)
+	})
+
+	t.Run("Trusted key without signature types isn't trusted for the grafana type", func(t *testing.T) {
+		state, err := getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.GrafanaType),
+			loadKeys(t, setting.PluginSigningKey{})...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureInvalid, state.Status)
+
+		state, err = getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.GrafanaType),
+			loadKeys(t, setting.PluginSigningKey{SignatureTypes: []string{"grafana"}})...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureValid, state.Status)
+	})
+
+	t.Run("Trusted key is restricted to signature types", func(t *testing.T) {
+		keys := loadKeys(t, setting.PluginSigningKey{SignatureTypes: []string{"private"}})
+
+		state, err := getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.PrivateType), keys...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureValid, state.Status)
+
+		state, err = getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.GrafanaType), keys...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureInvalid, state.Status)
+	})
+
+	t.Run("Trusted key is restricted to root URLs", func(t *testing.T) {
+		keys := loadKeys(t, setting.PluginSigningKey{RootURLs: []string{"https://grafana.example.com/"}})
+
+		state, err := getPluginSignatureState(log.New("test"), signedPlugin(t, plugins.PrivateType), keys...)
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureInvalid, state.Status)
+	})
+
+	t.Run("Grafana key name is reserved", func(t *testing.T) {
+		_, err := loadSigningKeys([]setting.PluginSigningKey{{Name: grafanaSigningKeyName, PublicKeyPath: keyPath}})
+		require.Error(t, err)
+	})
+}
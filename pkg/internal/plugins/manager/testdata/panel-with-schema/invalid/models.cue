@@ -0,0 +1,5 @@
+package grafanaschema
+
+Family: {
+    lineages: "none"
+}
@@ -0,0 +1,14 @@
+package grafanaschema
+
+Family: {
+    lineages: [
+        [
+            {
+                PanelOptions: {
+                    showLegend: bool | *true
+                }
+            }
+        ]
+    ]
+    migrations: []
+}
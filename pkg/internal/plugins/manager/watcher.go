@@ -0,0 +1,208 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/infra/fs"
+	"github.com/grafana/grafana/pkg/internal/plugins"
+	"github.com/grafana/grafana/pkg/internal/util"
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// watchedPaths returns the external plugin directories that are watched for changes when hot reload is enabled.
+func (pm *PluginManager) watchedPaths() []string {
+	paths := []string{pm.Cfg.PluginsPath}
+	for _, settings := range pm.Cfg.PluginSettings {
+		if path := settings["path"]; path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// watchPlugins checks the external plugin directories for added, updated or removed plugins
+// at the configured interval until the context is done.
+func (pm *PluginManager) watchPlugins(ctx context.Context) {
+	pm.log.Info("Watching plugin directories for changes", "interval", pm.Cfg.PluginsHotReloadInterval)
+
+	pm.pluginFingerprints = pm.fingerprintPlugins()
+	ticker := time.NewTicker(pm.Cfg.PluginsHotReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := pm.reloadChangedPlugins(ctx); err != nil {
+				pm.log.Error("Failed to reload changed plugins", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadChangedPlugins unloads the plugins whose directory was updated or removed since the
+// last check, and scans the updated and added plugin directories to load their plugins.
+func (pm *PluginManager) reloadChangedPlugins(ctx context.Context) error {
+	fingerprints := pm.fingerprintPlugins()
+
+	var changedDirs []string
+	for dir, fingerprint := range pm.pluginFingerprints {
+		if fingerprints[dir] != fingerprint {
+			changedDirs = append(changedDirs, dir)
+		}
+	}
+	for dir := range fingerprints {
+		if _, exists := pm.pluginFingerprints[dir]; !exists {
+			changedDirs = append(changedDirs, dir)
+		}
+	}
+	if len(changedDirs) == 0 {
+		return nil
+	}
+	sort.Strings(changedDirs)
+
+	for _, dir := range changedDirs {
+		// the plugin is validated again when its directory is scanned
+		pm.pluginsMu.Lock()
+		delete(pm.pluginScanningErrors, dir)
+		pm.pluginsMu.Unlock()
+
+		plugin := pm.pluginByDir(dir)
+		if plugin == nil {
+			continue
+		}
+
+		if plugin.Type == "renderer" {
+			pm.log.Warn("Renderer plugin changed, restart Grafana to reload it", "id", plugin.Id, "dir", dir)
+			fingerprints[dir] = pm.pluginFingerprints[dir]
+			continue
+		}
+
+		pm.log.Info("Unloading changed plugin", "id", plugin.Id, "dir", dir)
+		if err := pm.unload(ctx, plugin); err != nil {
+			return err
+		}
+	}
+
+	pm.pluginFingerprints = fingerprints
+
+	pm.log.Info("Loading changed plugins", "dirs", changedDirs)
+	if err := pm.loadPluginDirs(changedDirs); err != nil {
+		return err
+	}
+
+	pm.publishPluginsChanged()
+	return nil
+}
+
+// loadPluginDirs scans the given plugin directories, sorted so that parent directories come first, and initializes
+// the plugins found in them. Directories that no longer exist are skipped.
+func (pm *PluginManager) loadPluginDirs(dirs []string) error {
+	loaded := map[string]bool{}
+	for _, dir := range dirs {
+		exists, err := fs.Exists(dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		if err := pm.scan(dir, true); err != nil {
+			return errutil.Wrapf(err, "failed to scan plugin directory '%s'", dir)
+		}
+		loaded[dir] = true
+	}
+
+	staticRoutes := pm.initFrontendPlugins(func(p *plugins.PluginBase) bool {
+		return loaded[p.PluginDir]
+	})
+
+	pm.pluginsMu.Lock()
+	defer pm.pluginsMu.Unlock()
+	pm.staticRoutes = append(append([]*plugins.PluginStaticRoute{}, pm.staticRoutes...), staticRoutes...)
+	return nil
+}
+
+// unload unregisters a plugin and stops its backend process, if any, leaving its directory in place.
+func (pm *PluginManager) unload(ctx context.Context, plugin *plugins.PluginBase) error {
+	if pm.BackendPluginManager.IsRegistered(plugin.Id) {
+		if err := pm.BackendPluginManager.UnregisterAndStop(ctx, plugin.Id); err != nil {
+			return err
+		}
+	}
+
+	return pm.unregister(plugin)
+}
+
+func (pm *PluginManager) pluginByDir(dir string) *plugins.PluginBase {
+	for _, p := range pm.Plugins() {
+		if p.PluginDir == dir {
+			return p
+		}
+	}
+	return nil
+}
+
+// fingerprintPlugins returns a fingerprint of the files of every plugin directory found in the watched paths.
+func (pm *PluginManager) fingerprintPlugins() map[string]string {
+	fingerprints := map[string]string{}
+	for _, path := range pm.watchedPaths() {
+		err := util.Walk(path, true, true, func(currentPath string, f os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if f.Name() == "node_modules" {
+				return util.ErrWalkSkipDir
+			}
+			if f.IsDir() || f.Name() != "plugin.json" {
+				return nil
+			}
+
+			dir := filepath.Dir(currentPath)
+			fingerprint, err := fingerprintDir(dir)
+			if err != nil {
+				pm.log.Warn("Failed to fingerprint plugin directory", "dir", dir, "error", err)
+				return nil
+			}
+			fingerprints[dir] = fingerprint
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			pm.log.Debug("Couldn't watch plugin directory", "dir", path, "error", err)
+		}
+	}
+	return fingerprints
+}
+
+// fingerprintDir hashes the path, size and modification time of every file within dir.
+func fingerprintDir(dir string) (string, error) {
+	h := fnv.New64a()
+	err := util.Walk(dir, true, true, func(currentPath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.Name() == "node_modules" {
+			return util.ErrWalkSkipDir
+		}
+		if f.IsDir() {
+			return nil
+		}
+
+		_, err = fmt.Fprintf(h, "%s\x00%d\x00%d\n", currentPath, f.Size(), f.ModTime().UnixNano())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum64()), nil
+}
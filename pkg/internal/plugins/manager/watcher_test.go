@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/internal/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_HotReload(t *testing.T) {
+	pluginsPath := t.TempDir()
+	fm := &fakeBackendPluginManager{}
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.BackendPluginManager = fm
+		pm.Cfg.PluginsPath = pluginsPath
+	})
+	require.NoError(t, pm.Init())
+	pm.pluginFingerprints = pm.fingerprintPlugins()
+
+	writePlugin := func(t *testing.T, dir, pluginJSON string) {
+		t.Helper()
+		dir = filepath.Join(pluginsPath, dir)
+		require.NoError(t, os.MkdirAll(dir, 0750))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "plugin.json"), []byte(pluginJSON), 0600))
+	}
+
+	datasourceJSON := func(name string) string {
+		return fmt.Sprintf(`{"type": "datasource", "id": "test-datasource", "name": %q}`, name)
+	}
+
+	t.Run("Added plugins are loaded", func(t *testing.T) {
+		writePlugin(t, "test-datasource", datasourceJSON("Test"))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+
+		ds := pm.GetDataSource("test-datasource")
+		require.NotNil(t, ds)
+		assert.Equal(t, "Test", ds.Name)
+		assert.Len(t, pm.StaticRoutes(), 1)
+	})
+
+	t.Run("Updated plugins are reloaded", func(t *testing.T) {
+		writePlugin(t, "test-datasource", datasourceJSON("Updated"))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+
+		ds := pm.GetDataSource("test-datasource")
+		require.NotNil(t, ds)
+		assert.Equal(t, "Updated", ds.Name)
+		assert.Len(t, pm.StaticRoutes(), 1)
+	})
+
+	t.Run("Only changed plugins are reloaded", func(t *testing.T) {
+		writePlugin(t, "other-datasource", `{"type": "datasource", "id": "other-datasource", "name": "Other"}`)
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		otherRoute := staticRouteOf(t, pm, "other-datasource")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				_ = pm.StaticRoutes()
+			}
+		}()
+		writePlugin(t, "test-datasource", datasourceJSON("Reloaded"))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		<-done
+
+		assert.Equal(t, "Reloaded", pm.GetDataSource("test-datasource").Name)
+		assert.Same(t, otherRoute, staticRouteOf(t, pm, "other-datasource"))
+
+		require.NoError(t, os.RemoveAll(filepath.Join(pluginsPath, "other-datasource")))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		assert.Len(t, pm.StaticRoutes(), 1)
+	})
+
+	t.Run("Signatures of added backend plugins are validated", func(t *testing.T) {
+		writePlugin(t, "test-backend",
+			`{"type": "datasource", "id": "test-backend", "name": "Backend", "backend": true, "executable": "test"}`)
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+
+		assert.Nil(t, pm.GetPlugin("test-backend"))
+		assert.Empty(t, fm.registeredPlugins)
+		assert.Contains(t, pm.ScanningErrors(), plugins.PluginError{PluginID: "test-backend", ErrorCode: signatureMissing})
+	})
+
+	t.Run("Scanning errors are cleared when the directory of the rejected plugin changes", func(t *testing.T) {
+		// a frontend plugin doesn't require a signature
+		writePlugin(t, "test-backend", `{"type": "datasource", "id": "test-backend", "name": "Frontend"}`)
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+
+		assert.NotNil(t, pm.GetPlugin("test-backend"))
+		assert.Empty(t, pm.ScanningErrors())
+
+		require.NoError(t, os.RemoveAll(filepath.Join(pluginsPath, "test-backend")))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		assert.Nil(t, pm.GetPlugin("test-backend"))
+	})
+
+	t.Run("Scanning errors are cleared when the directory of the rejected plugin is removed", func(t *testing.T) {
+		writePlugin(t, "unsigned-backend",
+			`{"type": "datasource", "id": "unsigned-backend", "name": "Backend", "backend": true, "executable": "test"}`)
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		assert.Contains(t, pm.ScanningErrors(), plugins.PluginError{PluginID: "unsigned-backend", ErrorCode: signatureMissing})
+
+		require.NoError(t, os.RemoveAll(filepath.Join(pluginsPath, "unsigned-backend")))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+		assert.Empty(t, pm.ScanningErrors())
+	})
+
+	t.Run("Removed plugins are unloaded", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(pluginsPath, "test-datasource")))
+		require.NoError(t, pm.reloadChangedPlugins(context.Background()))
+
+		assert.Nil(t, pm.GetPlugin("test-datasource"))
+		assert.Nil(t, pm.GetDataSource("test-datasource"))
+		assert.Empty(t, pm.StaticRoutes())
+	})
+}
+
+func staticRouteOf(t *testing.T, pm *PluginManager, pluginID string) *plugins.PluginStaticRoute {
+	t.Helper()
+
+	for _, route := range pm.StaticRoutes() {
+		if route.PluginId == pluginID {
+			return route
+		}
+	}
+	require.Fail(t, "no static route", "plugin %s", pluginID)
+	return nil
+}
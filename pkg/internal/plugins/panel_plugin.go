@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/internal/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/internal/schema"
 )
 
 type PanelPlugin struct {
 	FrontendPluginBase
 	SkipDataQuery bool `json:"skipDataQuery"`
+
+	// Schema is the family of schema declared in the models.cue file of a non-core panel plugin, if any.
+	Schema schema.VersionedCueSchema `json:"-"`
 }
 
 func (p *PanelPlugin) Load(decoder *json.Decoder, base *PluginBase, backendPluginManager backendplugin.Manager) (
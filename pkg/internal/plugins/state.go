@@ -35,4 +35,6 @@ type PluginSignatureState struct {
 	Status     PluginSignatureStatus
 	Type       PluginSignatureType
 	SigningOrg string
+	// SigningKey is the name of the key a valid signature was made with.
+	SigningKey string
 }
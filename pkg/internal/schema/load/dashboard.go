@@ -71,15 +71,47 @@ func BaseDashboardFamily(p BaseLoadPaths) (schema.VersionedCueSchema, error) {
 // family: the 0.0 schema. schema.Find() provides easy traversal to newer schema
 // versions.
 func DistDashboardFamily(p BaseLoadPaths) (schema.VersionedCueSchema, error) {
-	head, err := BaseDashboardFamily(p)
+	scuemap, err := readPanelModels(p)
 	if err != nil {
 		return nil, err
 	}
+
+	return composeDashboardFamily(p, scuemap)
+}
+
+// InstanceDashboardFamily loads the family of schema representing the
+// "Instance" variant of a Grafana dashboard: the "Dist" variant (see
+// DistDashboardFamily()), but additionally allowing panels to be valid with
+// respect to the provided schemas of the non-core panel plugins available in a
+// running Grafana instance, keyed by plugin ID. Schemas of core plugins take
+// precedence over any provided schema with the same plugin ID.
+//
+// The returned VersionedCueSchema will always be the oldest schema in the
+// family: the 0.0 schema. schema.Find() provides easy traversal to newer schema
+// versions.
+func InstanceDashboardFamily(p BaseLoadPaths, panels map[string]schema.VersionedCueSchema) (schema.VersionedCueSchema, error) {
 	scuemap, err := readPanelModels(p)
 	if err != nil {
 		return nil, err
 	}
 
+	for id, sch := range panels {
+		if _, exists := scuemap[id]; !exists {
+			scuemap[id] = sch
+		}
+	}
+
+	return composeDashboardFamily(p, scuemap)
+}
+
+// composeDashboardFamily composes the "Base" variant of the dashboard family
+// with the provided panel plugin schemas.
+func composeDashboardFamily(p BaseLoadPaths, scuemap map[string]schema.VersionedCueSchema) (schema.VersionedCueSchema, error) {
+	head, err := BaseDashboardFamily(p)
+	if err != nil {
+		return nil, err
+	}
+
 	dj, err := disjunctPanelScuemata(scuemap)
 	if err != nil {
 		return nil, err
@@ -144,3 +144,33 @@ func TestCueErrorWrapper(t *testing.T) {
 		require.Contains(t, err.Error(), "on line")
 	})
 }
+
+func TestPanelPluginFamily(t *testing.T) {
+	t.Run("Loading panel plugin models", func(t *testing.T) {
+		fam, err := PanelPluginFamily(p, os.DirFS(filepath.Join("testdata", "plugins", "panel", "with-lineage")))
+		require.NoError(t, err)
+
+		maj, min := schema.Find(fam, schema.Latest()).Version()
+		require.Equal(t, [2]int{1, 0}, [2]int{maj, min})
+	})
+
+	t.Run("Loading panel plugin models not correct with respect to #PanelFamily", func(t *testing.T) {
+		_, err := PanelPluginFamily(p, os.DirFS(filepath.Join("testdata", "plugins", "panel", "invalid-models")))
+		require.Error(t, err)
+	})
+
+	t.Run("Composing panel plugin models into the dashboard family", func(t *testing.T) {
+		fam, err := PanelPluginFamily(p, os.DirFS(filepath.Join("testdata", "plugins", "panel", "with-lineage")))
+		require.NoError(t, err)
+
+		idash, err := InstanceDashboardFamily(p, map[string]schema.VersionedCueSchema{"with-lineage": fam})
+		require.NoError(t, err)
+
+		dpan, err := idash.(CompositeDashboardSchema).LatestPanelSchemaFor("with-lineage")
+		require.NoError(t, err)
+		require.True(t, dpan.CUE().Exists())
+
+		_, err = idash.(CompositeDashboardSchema).LatestPanelSchemaFor("table")
+		require.NoError(t, err, "core panel schemas should still be composed")
+	})
+}
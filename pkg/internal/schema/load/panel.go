@@ -70,24 +70,16 @@ func mapPanelModel(id string, vcs schema.VersionedCueSchema) cue.Value {
 }
 
 func readPanelModels(p BaseLoadPaths) (map[string]schema.VersionedCueSchema, error) {
-	overlay := make(map[string]load.Source)
-	if err := toOverlay("/", p.BaseCueFS, overlay); err != nil {
-		return nil, err
-	}
-	if err := toOverlay("/", p.DistPluginCueFS, overlay); err != nil {
+	overlay, err := defaultOverlay(p)
+	if err != nil {
 		return nil, err
 	}
 
-	base, err := getBaseScuemata(p)
+	pmf, err := panelFamilyDefinition(p)
 	if err != nil {
 		return nil, err
 	}
 
-	pmf := base.Value().LookupPath(cue.MakePath(cue.Def("#PanelFamily")))
-	if !pmf.Exists() {
-		return nil, errors.New("could not locate #PanelFamily definition")
-	}
-
 	all := make(map[string]schema.VersionedCueSchema)
 	err = fs.WalkDir(p.DistPluginCueFS, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -125,31 +117,7 @@ func readPanelModels(p BaseLoadPaths) (map[string]schema.VersionedCueSchema, err
 		}
 		id := iid.(string)
 
-		cfg := &load.Config{
-			Package: "grafanaschema",
-			Overlay: overlay,
-		}
-
-		li := load.Instances([]string{filepath.Join("/", dpath, "models.cue")}, cfg)
-		imod, err := rt.Build(li[0])
-		if err != nil {
-			return err
-		}
-
-		// Get the Family declaration in the models.cue file...
-		pmod := imod.Value().LookupPath(cue.MakePath(cue.Str("Family")))
-		if !pmod.Exists() {
-			return fmt.Errorf("%s does not contain a declaration of its models at path 'Family'", path)
-		}
-
-		// Ensure the declared value is subsumed by/correct wrt #PanelFamily
-		// TODO not actually sure that Final is what we want here.
-		if err := pmf.Subsume(pmod, cue.Final()); err != nil {
-			return err
-		}
-
-		// Create a generic schema family to represent the whole of the
-		fam, err := buildGenericScuemata(pmod)
+		fam, err := loadPanelModel(overlay, pmf, filepath.Join("/", dpath, "models.cue"))
 		if err != nil {
 			return err
 		}
@@ -163,3 +131,75 @@ func readPanelModels(p BaseLoadPaths) (map[string]schema.VersionedCueSchema, err
 
 	return all, nil
 }
+
+// PanelPluginFamily loads the family of schema declared in the models.cue file
+// at the root of a panel plugin that does not ship with Grafana, such as a
+// plugin installed into the plugins directory of a running Grafana instance.
+// The declared family must be correct with respect to #PanelFamily.
+func PanelPluginFamily(p BaseLoadPaths, pluginFS fs.FS) (schema.VersionedCueSchema, error) {
+	overlay, err := defaultOverlay(p)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := fs.ReadFile(pluginFS, "models.cue")
+	if err != nil {
+		return nil, err
+	}
+	// Place the plugin's models.cue where it can't collide with the files of
+	// core plugins, which are mounted at the root of the overlay.
+	modelsPath := "/instance/models.cue"
+	overlay[modelsPath] = load.FromBytes(b)
+
+	pmf, err := panelFamilyDefinition(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadPanelModel(overlay, pmf, modelsPath)
+}
+
+// panelFamilyDefinition returns the #PanelFamily definition that all panel
+// plugin models must be correct with respect to.
+func panelFamilyDefinition(p BaseLoadPaths) (cue.Value, error) {
+	base, err := getBaseScuemata(p)
+	if err != nil {
+		return cue.Value{}, err
+	}
+
+	pmf := base.Value().LookupPath(cue.MakePath(cue.Def("#PanelFamily")))
+	if !pmf.Exists() {
+		return cue.Value{}, errors.New("could not locate #PanelFamily definition")
+	}
+	return pmf, nil
+}
+
+// loadPanelModel builds the models.cue file at the provided path in the
+// overlay into a schema family.
+func loadPanelModel(overlay map[string]load.Source, pmf cue.Value, path string) (schema.VersionedCueSchema, error) {
+	cfg := &load.Config{
+		Package: "grafanaschema",
+		Overlay: overlay,
+	}
+
+	li := load.Instances([]string{path}, cfg)
+	imod, err := rt.Build(li[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the Family declaration in the models.cue file...
+	pmod := imod.Value().LookupPath(cue.MakePath(cue.Str("Family")))
+	if !pmod.Exists() {
+		return nil, fmt.Errorf("%s does not contain a declaration of its models at path 'Family'", path)
+	}
+
+	// Ensure the declared value is subsumed by/correct wrt #PanelFamily
+	// TODO not actually sure that Final is what we want here.
+	if err := pmf.Subsume(pmod, cue.Final()); err != nil {
+		return nil, err
+	}
+
+	// Create a generic schema family to represent the whole of the
+	return buildGenericScuemata(pmod)
+}
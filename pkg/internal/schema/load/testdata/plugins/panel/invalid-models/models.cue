@@ -0,0 +1,7 @@
+package grafanaschema
+
+// Family is not correct with respect to #PanelFamily, as lineages must be a
+// list of lists of schemas.
+Family: {
+    lineages: "none"
+}
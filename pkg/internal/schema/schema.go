@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"cuelang.org/go/cue"
+	errs "cuelang.org/go/cue/errors"
 	cuejson "cuelang.org/go/pkg/encoding/json"
 )
 
@@ -402,4 +403,85 @@ type Resource struct {
 	Value interface{}
 }
 
-// TODO add migrator with SearchOption for stopping criteria
+// MigrateToLatest migrates the Resource, which must be valid with respect to
+// the provided VersionedCueSchema, through all of its successors until the
+// latest schema in the family is reached. It returns the migrated Resource as a
+// JSON string, along with the schema to which the resource now conforms.
+//
+// If the provided schema is already the latest, the input Resource is returned
+// unchanged.
+func MigrateToLatest(from VersionedCueSchema, r Resource) (Resource, VersionedCueSchema, error) {
+	if from.Successor() == nil {
+		return r, from, nil
+	}
+
+	rv, err := rt.Compile("resource", r.Value)
+	if err != nil {
+		return r, from, err
+	}
+
+	var sch CueSchema = from
+	migrated := Resource{Value: rv.Value()}
+	for {
+		next, nextSch, err := sch.Migrate(migrated)
+		if err != nil {
+			return r, from, err
+		}
+		if nextSch == nil {
+			break
+		}
+		migrated, from, sch = next, nextSch, nextSch
+	}
+
+	out, err := convertCUEValueToString(migrated.Value.(cue.Value))
+	if err != nil {
+		return r, from, err
+	}
+	return Resource{Value: out}, from, nil
+}
+
+// ValidationError describes a single reason why a Resource is not valid with
+// respect to a schema.
+type ValidationError struct {
+	// Path is the dot-separated path of the invalid value in the Resource.
+	Path    string
+	Message string
+}
+
+// ValidationErrors splits an error returned by Validate on the provided
+// schema into the individual ValidationErrors, deduplicating errors reported
+// more than once. Paths are relative to the root of the Resource.
+func ValidationErrors(err error, sch CueSchema) []ValidationError {
+	var prefix []string
+	for _, sel := range sch.CUE().Path().Selectors() {
+		prefix = append(prefix, sel.String())
+	}
+
+	var result []ValidationError
+	seen := make(map[ValidationError]bool)
+	for _, e := range errs.Errors(err) {
+		path := e.Path()
+		if len(path) >= len(prefix) && strings.Join(path[:len(prefix)], ".") == strings.Join(prefix, ".") {
+			path = path[len(prefix):]
+		}
+
+		format, args := e.Msg()
+		ve := ValidationError{
+			Path:    strings.Join(path, "."),
+			Message: fmt.Sprintf(format, args...),
+		}
+		// skip summaries like "2 errors in empty disjunction:", the errors they summarize follow
+		if strings.HasSuffix(ve.Message, ":") {
+			continue
+		}
+		if !seen[ve] {
+			seen[ve] = true
+			result = append(result, ve)
+		}
+	}
+
+	if len(result) == 0 && err != nil {
+		result = append(result, ValidationError{Message: err.Error()})
+	}
+	return result
+}
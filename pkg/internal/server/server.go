@@ -32,10 +32,12 @@ import (
 	_ "github.com/grafana/grafana/pkg/internal/plugins/manager"
 	"github.com/grafana/grafana/pkg/internal/registry"
 	_ "github.com/grafana/grafana/pkg/internal/services/alerting"
+	_ "github.com/grafana/grafana/pkg/internal/services/annotations/loki"
 	_ "github.com/grafana/grafana/pkg/internal/services/auth"
 	_ "github.com/grafana/grafana/pkg/internal/services/auth/jwt"
 	_ "github.com/grafana/grafana/pkg/internal/services/cleanup"
 	_ "github.com/grafana/grafana/pkg/internal/services/librarypanels"
+	_ "github.com/grafana/grafana/pkg/internal/services/libraryvariables"
 	_ "github.com/grafana/grafana/pkg/internal/services/login/loginservice"
 	_ "github.com/grafana/grafana/pkg/internal/services/ngalert"
 	_ "github.com/grafana/grafana/pkg/internal/services/notifications"
@@ -0,0 +1,443 @@
+package loki
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/annotations"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/logging"
+	"github.com/grafana/grafana/pkg/internal/setting"
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	promtail "github.com/grafana/loki/clients/pkg/promtail/client"
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	KindAlert     = "alert"
+	KindAPI       = "api"
+	KindDashboard = "dashboard"
+
+	pushPath = "/loki/api/v1/push"
+
+	// defaultLookback is the time range queried when the query has none, it matches the default
+	// maximum query length of Loki.
+	defaultLookback = 30 * 24 * time.Hour
+
+	// queueTimeout is how long Save waits for the push client to accept an annotation, which it doesn't
+	// while it retries a failed push, before saving the annotation in the fallback repository.
+	queueTimeout = time.Second
+
+	// minID is the smallest ID of an annotation pushed to Loki, the time of 2001-09-09 in microseconds. IDs of
+	// annotations stored in the database are far smaller.
+	minID = int64(1e15)
+)
+
+// ErrImmutableAnnotation is returned when updating or deleting an annotation stored in Loki.
+var ErrImmutableAnnotation = errors.New("annotations stored in Loki cannot be edited or deleted")
+
+// entry is the log line of an annotation.
+type entry struct {
+	ID          int64            `json:"id"`
+	DashboardID int64            `json:"dashboard_id"`
+	PanelID     int64            `json:"panel_id"`
+	AlertID     int64            `json:"alert_id"`
+	UserID      int64            `json:"user_id"`
+	PrevState   string           `json:"prev_state,omitempty"`
+	NewState    string           `json:"new_state,omitempty"`
+	Text        string           `json:"text"`
+	Time        int64            `json:"time"`
+	TimeEnd     int64            `json:"time_end"`
+	Tags        []string         `json:"tags,omitempty"`
+	Data        *simplejson.Json `json:"data,omitempty"`
+	Created     int64            `json:"created"`
+}
+
+// Repository stores annotations as log lines in Loki, in streams labelled by organization, kind and dashboard.
+// Annotations of the kinds not stored in Loki, which include API annotations since they can be edited, are
+// stored in the fallback repository. Annotations are pushed in batches by the promtail client, which retries
+// failed pushes; annotations are stored in the fallback repository when the client is busy retrying. Annotations
+// stored in Loki are immutable, updating or deleting them returns ErrImmutableAnnotation, and they cannot be
+// found by ID.
+type Repository struct {
+	client   *client.DefaultClient
+	pusher   promtail.Client
+	kinds    map[string]bool
+	fallback annotations.Repository
+	log      log.Logger
+	// lastID is the ID of the last annotation pushed to Loki.
+	lastID int64
+}
+
+func NewRepository(settings setting.AnnotationsLokiSettings, fallback annotations.Repository) (*Repository, error) {
+	kinds := map[string]bool{}
+	for _, kind := range settings.Types {
+		kinds[kind] = true
+	}
+
+	address := strings.TrimSuffix(settings.URL, "/")
+	pushURL, err := url.Parse(address + pushPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Loki url %q: %w", settings.URL, err)
+	}
+	clientConfig := config.HTTPClientConfig{}
+	if settings.BasicAuthUser != "" {
+		clientConfig.BasicAuth = &config.BasicAuth{
+			Username: settings.BasicAuthUser,
+			Password: config.Secret(settings.BasicAuthPassword),
+		}
+	}
+
+	logger := log.New("annotations.loki")
+	pusher, err := promtail.New(prometheus.DefaultRegisterer, promtail.Config{
+		URL:       flagext.URLValue{URL: pushURL},
+		BatchWait: promtail.BatchWait,
+		BatchSize: promtail.BatchSize,
+		Client:    clientConfig,
+		BackoffConfig: util.BackoffConfig{
+			MinBackoff: promtail.MinBackoff,
+			MaxBackoff: promtail.MaxBackoff,
+			MaxRetries: promtail.MaxRetries,
+		},
+		Timeout:  promtail.Timeout,
+		TenantID: settings.TenantID,
+	}, gokit_log.NewLogfmtLogger(logging.NewWrapper(logger)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{
+		client: &client.DefaultClient{
+			Address:  address,
+			Username: settings.BasicAuthUser,
+			Password: settings.BasicAuthPassword,
+			OrgID:    settings.TenantID,
+		},
+		pusher:   pusher,
+		kinds:    kinds,
+		fallback: fallback,
+		log:      logger,
+	}, nil
+}
+
+// Stop pushes the pending annotations to Loki and stops the push client.
+func (r *Repository) Stop() {
+	r.pusher.Stop()
+}
+
+// itemKind returns the kind of an annotation: alert annotations have an alert, dashboard annotations a
+// dashboard, and API annotations neither.
+func itemKind(item *annotations.Item) string {
+	switch {
+	case item.AlertId != 0:
+		return KindAlert
+	case item.DashboardId != 0:
+		return KindDashboard
+	default:
+		return KindAPI
+	}
+}
+
+func (r *Repository) Save(item *annotations.Item) error {
+	kind := itemKind(item)
+	if !r.kinds[kind] {
+		return r.fallback.Save(item)
+	}
+
+	created := time.Now().UnixNano() / int64(time.Millisecond)
+	if item.Epoch == 0 {
+		item.Epoch = created
+	}
+	if item.EpochEnd == 0 {
+		item.EpochEnd = item.Epoch
+	}
+	if item.EpochEnd < item.Epoch {
+		item.Epoch, item.EpochEnd = item.EpochEnd, item.Epoch
+	}
+	tags := models.JoinTagPairs(models.ParseTagPairs(item.Tags))
+
+	// Loki has no identifiers, the ID of the annotation is stored in the log line.
+	id := r.nextID()
+	line, err := json.Marshal(entry{
+		ID:          id,
+		DashboardID: item.DashboardId,
+		PanelID:     item.PanelId,
+		AlertID:     item.AlertId,
+		UserID:      item.UserId,
+		PrevState:   item.PrevState,
+		NewState:    item.NewState,
+		Text:        item.Text,
+		Time:        item.Epoch,
+		TimeEnd:     item.EpochEnd,
+		Tags:        tags,
+		Data:        item.Data,
+		Created:     created,
+	})
+	if err != nil {
+		return err
+	}
+
+	labels := model.LabelSet{
+		"source": "grafana",
+		"org_id": model.LabelValue(strconv.FormatInt(item.OrgId, 10)),
+		"kind":   model.LabelValue(kind),
+	}
+	if item.DashboardId != 0 {
+		labels["dashboard_id"] = model.LabelValue(strconv.FormatInt(item.DashboardId, 10))
+	}
+
+	select {
+	case r.pusher.Chan() <- api.Entry{
+		Labels: labels,
+		Entry:  logproto.Entry{Timestamp: time.Unix(0, item.Epoch*int64(time.Millisecond)), Line: string(line)},
+	}:
+	case <-time.After(queueTimeout):
+		r.log.Warn("Loki push client is busy, saving annotation in the database", "kind", kind)
+		return r.fallback.Save(item)
+	}
+
+	item.Id = id
+	item.Tags = tags
+	item.Created = created
+	item.Updated = created
+	return nil
+}
+
+// nextID returns a unique ID for an annotation pushed to Loki: the current time in microseconds, or the last ID
+// plus one if that isn't greater. Unlike nanoseconds, microseconds fit in the 53 bits of a JavaScript number.
+func (r *Repository) nextID() int64 {
+	for {
+		last := atomic.LoadInt64(&r.lastID)
+		id := time.Now().UnixNano() / int64(time.Microsecond)
+		if id <= last {
+			id = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&r.lastID, last, id) {
+			return id
+		}
+	}
+}
+
+// isLokiID returns whether id is the ID of an annotation pushed to Loki.
+func isLokiID(id int64) bool {
+	return id >= minID
+}
+
+func (r *Repository) Update(item *annotations.Item) error {
+	if isLokiID(item.Id) {
+		return ErrImmutableAnnotation
+	}
+	return r.fallback.Update(item)
+}
+
+func (r *Repository) Delete(params *annotations.DeleteParams) error {
+	if isLokiID(params.Id) {
+		return ErrImmutableAnnotation
+	}
+	return r.fallback.Delete(params)
+}
+
+// Find returns the annotations of the fallback repository merged with the annotations stored in Loki, most
+// recent first. Annotations stored in Loki are matched if they overlap the queried time range.
+func (r *Repository) Find(query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	items, err := r.fallback.Find(query)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := r.queriedKinds(query)
+	if query.AnnotationId != 0 || len(kinds) == 0 {
+		return items, nil
+	}
+
+	lokiItems, err := r.find(query, kinds)
+	if err != nil {
+		r.log.Error("Failed to query annotations in Loki", "error", err)
+		return items, nil
+	}
+
+	items = append(items, lokiItems...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].TimeEnd != items[j].TimeEnd {
+			return items[i].TimeEnd > items[j].TimeEnd
+		}
+		return items[i].Time > items[j].Time
+	})
+	if query.Limit > 0 && int64(len(items)) > query.Limit {
+		items = items[:query.Limit]
+	}
+	return items, nil
+}
+
+// queriedKinds returns the kinds of annotations stored in Loki the query can match.
+func (r *Repository) queriedKinds(query *annotations.ItemQuery) []string {
+	var kinds []string
+	for _, kind := range []string{KindAlert, KindDashboard} {
+		switch {
+		case !r.kinds[kind]:
+		case (query.AlertId != 0 || query.Type == "alert") && kind != KindAlert:
+		case query.Type == "annotation" && kind == KindAlert:
+		default:
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+func (r *Repository) find(query *annotations.ItemQuery, kinds []string) ([]*annotations.ItemDTO, error) {
+	var logQL strings.Builder
+	fmt.Fprintf(&logQL, `{source="grafana", org_id="%d", kind=~"%s"`, query.OrgId, strings.Join(kinds, "|"))
+	if query.DashboardId != 0 {
+		fmt.Fprintf(&logQL, `, dashboard_id="%d"`, query.DashboardId)
+	}
+	logQL.WriteString("}")
+
+	// The tags are matched exactly once the lines are parsed, the line filters only narrow down the lines.
+	tags := models.ParseTagPairs(query.Tags)
+	if query.MatchAny && len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			keys = append(keys, regexp.QuoteMeta(tag.Key))
+		}
+		fmt.Fprintf(&logQL, " |~ %s", strconv.Quote(strings.Join(keys, "|")))
+	} else {
+		for _, tag := range tags {
+			fmt.Fprintf(&logQL, " |= %s", strconv.Quote(tag.Key))
+		}
+	}
+
+	logQL.WriteString(" | json")
+	for _, filter := range []struct {
+		label string
+		value int64
+	}{{"alert_id", query.AlertId}, {"panel_id", query.PanelId}, {"user_id", query.UserId}} {
+		if filter.value != 0 {
+			fmt.Fprintf(&logQL, ` | %s="%d"`, filter.label, filter.value)
+		}
+	}
+
+	// Annotations are stored at their start time, so regions starting before the queried range are found by
+	// querying as far back as Loki allows, and filtering the annotations ending before the range afterwards.
+	end := time.Now()
+	if query.To > 0 {
+		end = time.Unix(0, query.To*int64(time.Millisecond))
+	}
+	start := end.Add(-defaultLookback)
+	if from := time.Unix(0, query.From*int64(time.Millisecond)); query.From > 0 && from.Before(start) {
+		start = from
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	resp, err := r.client.QueryRange(logQL.String(), int(limit), start, end, logproto.BACKWARD, 0, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	streams, ok := resp.Data.Result.(loghttp.Streams)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %q", resp.Data.ResultType)
+	}
+
+	users := map[int64]*models.User{}
+	alertNames := map[int64]string{}
+	items := make([]*annotations.ItemDTO, 0)
+	for _, stream := range streams {
+		for _, logEntry := range stream.Entries {
+			var e entry
+			if err := json.Unmarshal([]byte(logEntry.Line), &e); err != nil {
+				r.log.Warn("Skipping invalid annotation", "line", logEntry.Line, "error", err)
+				continue
+			}
+			if e.TimeEnd < query.From || !matchTags(e.Tags, tags, query.MatchAny) {
+				continue
+			}
+
+			item := &annotations.ItemDTO{
+				Id:          e.ID,
+				AlertId:     e.AlertID,
+				DashboardId: e.DashboardID,
+				PanelId:     e.PanelID,
+				UserId:      e.UserID,
+				NewState:    e.NewState,
+				PrevState:   e.PrevState,
+				Created:     e.Created,
+				Updated:     e.Created,
+				Time:        e.Time,
+				TimeEnd:     e.TimeEnd,
+				Text:        e.Text,
+				Tags:        e.Tags,
+				Data:        e.Data,
+			}
+			if item.UserId != 0 {
+				if _, ok := users[item.UserId]; !ok {
+					userQuery := models.GetUserByIdQuery{Id: item.UserId}
+					if err := bus.Dispatch(&userQuery); err == nil {
+						users[item.UserId] = userQuery.Result
+					} else {
+						users[item.UserId] = nil
+					}
+				}
+				if user := users[item.UserId]; user != nil {
+					item.Login = user.Login
+					item.Email = user.Email
+				}
+			}
+			if item.AlertId != 0 {
+				if _, ok := alertNames[item.AlertId]; !ok {
+					alertQuery := models.GetAlertByIdQuery{Id: item.AlertId}
+					if err := bus.Dispatch(&alertQuery); err == nil {
+						alertNames[item.AlertId] = alertQuery.Result.Name
+					} else {
+						alertNames[item.AlertId] = ""
+					}
+				}
+				item.AlertName = alertNames[item.AlertId]
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// matchTags matches tags the way the SQL repository does: a tag without value matches any value of its key.
+func matchTags(itemTags []string, tags []*models.Tag, matchAny bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	matched := 0
+	parsed := models.ParseTagPairs(itemTags)
+	for _, tag := range tags {
+		for _, itemTag := range parsed {
+			if itemTag.Key == tag.Key && (tag.Value == "" || itemTag.Value == tag.Value) {
+				matched++
+				break
+			}
+		}
+	}
+	if matchAny {
+		return matched > 0
+	}
+	return matched == len(tags)
+}
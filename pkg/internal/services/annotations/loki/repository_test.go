@@ -0,0 +1,254 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/annotations"
+	"github.com/grafana/grafana/pkg/internal/setting"
+)
+
+type fakeRepository struct {
+	saved   []*annotations.Item
+	updated []*annotations.Item
+	deleted []*annotations.DeleteParams
+	found   []*annotations.ItemDTO
+}
+
+func (f *fakeRepository) Save(item *annotations.Item) error {
+	item.Id = int64(len(f.saved) + 1)
+	f.saved = append(f.saved, item)
+	return nil
+}
+
+func (f *fakeRepository) Update(item *annotations.Item) error {
+	f.updated = append(f.updated, item)
+	return nil
+}
+
+func (f *fakeRepository) Find(query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	return f.found, nil
+}
+
+func (f *fakeRepository) Delete(params *annotations.DeleteParams) error {
+	f.deleted = append(f.deleted, params)
+	return nil
+}
+
+// fakeLoki stores the pushed streams and returns them to every query.
+type fakeLoki struct {
+	mu      sync.Mutex
+	streams []lokiStream
+	queries []string
+	tenants []string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tenants = append(f.tenants, r.Header.Get("X-Scope-OrgID"))
+
+	switch r.URL.Path {
+	case pushPath:
+		var push logproto.PushRequest
+		body, _ := ioutil.ReadAll(r.Body)
+		decoded, err := snappy.Decode(nil, body)
+		if err == nil {
+			err = push.Unmarshal(decoded)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, stream := range push.Streams {
+			lbls, err := logql.ParseLabels(stream.Labels)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			pushed := lokiStream{Stream: lbls.Map()}
+			for _, e := range stream.Entries {
+				pushed.Values = append(pushed.Values, [2]string{strconv.FormatInt(e.Timestamp.UnixNano(), 10), e.Line})
+			}
+			f.streams = append(f.streams, pushed)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "/loki/api/v1/query_range":
+		f.queries = append(f.queries, r.URL.Query().Get("query"))
+		result := make([]map[string]interface{}, 0, len(f.streams))
+		for _, stream := range f.streams {
+			result = append(result, map[string]interface{}{"stream": stream.Stream, "values": stream.Values})
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "streams", "result": result},
+		})
+		_, _ = w.Write(body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// pushedStreams waits for count streams to be pushed and returns them sorted by kind.
+func (f *fakeLoki) pushedStreams(t *testing.T, count int) []lokiStream {
+	t.Helper()
+
+	var streams []lokiStream
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		streams = append([]lokiStream(nil), f.streams...)
+		return len(streams) >= count
+	}, 5*time.Second, 50*time.Millisecond)
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Stream["kind"] < streams[j].Stream["kind"] })
+	return streams
+}
+
+func (f *fakeLoki) lastQuery() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[len(f.queries)-1]
+}
+
+func TestRepository(t *testing.T) {
+	loki := &fakeLoki{}
+	server := httptest.NewServer(loki)
+	t.Cleanup(server.Close)
+
+	fallback := &fakeRepository{}
+	repo, err := NewRepository(setting.AnnotationsLokiSettings{
+		URL:      server.URL,
+		TenantID: "grafana",
+		Types:    []string{KindAlert, KindDashboard},
+	}, fallback)
+	require.NoError(t, err)
+	t.Cleanup(repo.Stop)
+
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandler("test", func(query *models.GetAlertByIdQuery) error {
+		query.Result = &models.Alert{Id: query.Id, Name: fmt.Sprintf("Alert %d", query.Id)}
+		return nil
+	})
+
+	t.Run("Alert and dashboard annotations are pushed to Loki", func(t *testing.T) {
+		alert := &annotations.Item{OrgId: 1, DashboardId: 2, PanelId: 3, AlertId: 4, NewState: "alerting", Epoch: 1000}
+		require.NoError(t, repo.Save(alert))
+		deploy := &annotations.Item{OrgId: 1, DashboardId: 5, Text: "deploy", Tags: []string{"deploy", "app:grafana"}, Epoch: 2000}
+		require.NoError(t, repo.Save(deploy))
+
+		assert.Empty(t, fallback.saved)
+		assert.Equal(t, int64(1000), alert.EpochEnd)
+		assert.Less(t, alert.Id, deploy.Id)
+		assert.Less(t, deploy.Id, int64(1)<<53, "IDs must fit in a JavaScript number")
+
+		streams := loki.pushedStreams(t, 2)
+		require.Len(t, streams, 2)
+		assert.Equal(t, map[string]string{"source": "grafana", "org_id": "1", "kind": KindAlert, "dashboard_id": "2"}, streams[0].Stream)
+		assert.Equal(t, map[string]string{"source": "grafana", "org_id": "1", "kind": KindDashboard, "dashboard_id": "5"}, streams[1].Stream)
+		assert.Equal(t, strconv.FormatInt(2000*1e6, 10), streams[1].Values[0][0])
+		assert.Contains(t, loki.tenants, "grafana")
+	})
+
+	t.Run("API annotations are saved in the fallback repository", func(t *testing.T) {
+		item := &annotations.Item{OrgId: 1, Text: "release"}
+		require.NoError(t, repo.Save(item))
+
+		require.Len(t, fallback.saved, 1)
+		assert.Same(t, item, fallback.saved[0])
+	})
+
+	t.Run("Find merges the annotations of Loki and the fallback repository", func(t *testing.T) {
+		fallback.found = []*annotations.ItemDTO{{Id: 1, Time: 1500, TimeEnd: 1500, Text: "release"}}
+
+		items, err := repo.Find(&annotations.ItemQuery{OrgId: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		assert.Equal(t, "deploy", items[0].Text)
+		assert.Equal(t, []string{"deploy", "app:grafana"}, items[0].Tags)
+		assert.Equal(t, "release", items[1].Text)
+		assert.Equal(t, "Alert 4", items[2].AlertName)
+		assert.Equal(t, "alerting", items[2].NewState)
+		assert.Equal(t, int64(3), items[2].PanelId)
+		assert.Equal(t, `{source="grafana", org_id="1", kind=~"alert|dashboard"} | json`, loki.lastQuery())
+
+		items, err = repo.Find(&annotations.ItemQuery{OrgId: 1, Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, items, 2)
+	})
+
+	t.Run("Find filters annotations stored in Loki", func(t *testing.T) {
+		fallback.found = nil
+
+		items, err := repo.Find(&annotations.ItemQuery{OrgId: 1, Tags: []string{"app:grafana", "deploy"}})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "deploy", items[0].Text)
+		assert.Equal(t, `{source="grafana", org_id="1", kind=~"alert|dashboard"} |= "app" |= "deploy" | json`, loki.lastQuery())
+
+		items, err = repo.Find(&annotations.ItemQuery{OrgId: 1, Tags: []string{"app:prometheus"}})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+
+		_, err = repo.Find(&annotations.ItemQuery{OrgId: 1, DashboardId: 2, PanelId: 3, Type: "alert"})
+		require.NoError(t, err)
+		assert.Equal(t, `{source="grafana", org_id="1", kind=~"alert", dashboard_id="2"} | json | panel_id="3"`, loki.lastQuery())
+
+		_, err = repo.Find(&annotations.ItemQuery{OrgId: 1, DashboardId: 2, Type: "annotation"})
+		require.NoError(t, err)
+		assert.Equal(t, `{source="grafana", org_id="1", kind=~"dashboard", dashboard_id="2"} | json`, loki.lastQuery())
+
+		queries := len(loki.queries)
+		_, err = repo.Find(&annotations.ItemQuery{OrgId: 1, AnnotationId: 1})
+		require.NoError(t, err)
+		assert.Len(t, loki.queries, queries, "annotations by ID are only in the fallback repository")
+	})
+
+	t.Run("Find returns regions starting before the queried range", func(t *testing.T) {
+		region := &annotations.Item{OrgId: 1, DashboardId: 5, Text: "maintenance", Epoch: 100, EpochEnd: 2500}
+		require.NoError(t, repo.Save(region))
+		loki.pushedStreams(t, 3)
+
+		items, err := repo.Find(&annotations.ItemQuery{OrgId: 1, From: 1500, To: 3000})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, "maintenance", items[0].Text)
+		assert.Equal(t, "deploy", items[1].Text)
+	})
+
+	t.Run("Annotations stored in Loki cannot be updated or deleted", func(t *testing.T) {
+		item := &annotations.Item{OrgId: 1, DashboardId: 2, Text: "deploy", Epoch: 3000}
+		require.NoError(t, repo.Save(item))
+		require.Len(t, fallback.saved, 1)
+
+		assert.ErrorIs(t, repo.Update(&annotations.Item{OrgId: 1, Id: item.Id, Text: "rollback"}), ErrImmutableAnnotation)
+		assert.ErrorIs(t, repo.Delete(&annotations.DeleteParams{OrgId: 1, Id: item.Id}), ErrImmutableAnnotation)
+		assert.Empty(t, fallback.updated)
+		assert.Empty(t, fallback.deleted)
+
+		require.NoError(t, repo.Update(&annotations.Item{OrgId: 1, Id: 1, Text: "rollback"}))
+		require.NoError(t, repo.Delete(&annotations.DeleteParams{OrgId: 1, DashboardId: 2, PanelId: 3}))
+		assert.Len(t, fallback.updated, 1)
+		assert.Len(t, fallback.deleted, 1)
+	})
+}
@@ -0,0 +1,58 @@
+package loki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/internal/registry"
+	"github.com/grafana/grafana/pkg/internal/services/annotations"
+	"github.com/grafana/grafana/pkg/internal/setting"
+)
+
+func init() {
+	registry.Register(&registry.Descriptor{
+		Name:     "LokiAnnotationService",
+		Instance: &Service{},
+		// Initialized after the SQL store, which sets the SQL repository used as fallback.
+		InitPriority: registry.Low,
+	})
+}
+
+// Service replaces the annotation repository with a Loki repository when the annotations backend is loki.
+type Service struct {
+	Cfg *setting.Cfg `inject:""`
+
+	repository *Repository
+}
+
+func (s *Service) Init() error {
+	if s.Cfg.AnnotationsBackend != "loki" {
+		return nil
+	}
+	if s.Cfg.AnnotationsLoki.URL == "" {
+		return errors.New("the url of [annotations.loki] is required for the loki annotations backend")
+	}
+	for _, kind := range s.Cfg.AnnotationsLoki.Types {
+		if kind != KindAlert && kind != KindDashboard {
+			return fmt.Errorf("invalid type %q in [annotations.loki], only alert and dashboard annotations can be stored in Loki", kind)
+		}
+	}
+
+	repository, err := NewRepository(s.Cfg.AnnotationsLoki, annotations.GetRepository())
+	if err != nil {
+		return err
+	}
+	s.repository = repository
+	annotations.SetRepository(repository)
+	return nil
+}
+
+// Run pushes the pending annotations to Loki on shutdown.
+func (s *Service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	if s.repository != nil {
+		s.repository.Stop()
+	}
+	return nil
+}
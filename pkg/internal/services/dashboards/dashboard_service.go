@@ -1,6 +1,7 @@
 package dashboards
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -93,10 +94,6 @@ func (dr *dashboardServiceImpl) buildSaveDashboardCommand(dto *SaveDashboardDTO,
 		return nil, models.ErrDashboardTitleEmpty
 	}
 
-	if dash.IsFolder && dash.FolderId > 0 {
-		return nil, models.ErrDashboardFolderCannotHaveParent
-	}
-
 	if dash.IsFolder && strings.EqualFold(dash.Title, models.RootFolderName) {
 		return nil, models.ErrDashboardFolderNameExists
 	}
@@ -111,6 +108,10 @@ func (dr *dashboardServiceImpl) buildSaveDashboardCommand(dto *SaveDashboardDTO,
 		return nil, err
 	}
 
+	if err := validateDashboardSchema(dash); err != nil {
+		return nil, err
+	}
+
 	if shouldValidateAlerts {
 		if err := validateAlerts(dash, dto.User); err != nil {
 			return nil, err
@@ -174,6 +175,25 @@ var validateAlerts = func(dash *models.Dashboard, user *models.SignedInUser) err
 	return extractor.ValidateAlerts()
 }
 
+// validateDashboardSchema validates the dashboard against the dashboard schema and migrates it to
+// the latest schema version, if enabled in the configuration.
+func validateDashboardSchema(dash *models.Dashboard) error {
+	if dash.IsFolder {
+		return nil
+	}
+
+	cmd := models.ValidateDashboardSchemaCommand{Dashboard: dash.Data}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, bus.ErrHandlerNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	dash.Data = cmd.Result
+	return nil
+}
+
 func validateDashboardRefreshInterval(dash *models.Dashboard) error {
 	if setting.MinRefreshInterval == "" {
 		return nil
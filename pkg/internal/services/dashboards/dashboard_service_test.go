@@ -40,11 +40,13 @@ func TestDashboardService(t *testing.T) {
 				}
 			})
 
-			Convey("Should return validation error if it's a folder and have a folder id", func() {
+			Convey("Should allow saving a folder in another folder", func() {
 				dto.Dashboard = models.NewDashboardFolder("Folder")
 				dto.Dashboard.FolderId = 1
-				_, err := service.SaveDashboard(dto, false)
-				So(err, ShouldEqual, models.ErrDashboardFolderCannotHaveParent)
+				dto.User = &models.SignedInUser{UserId: 1}
+				dash, err := service.SaveDashboard(dto, false)
+				So(err, ShouldBeNil)
+				So(dash.FolderId, ShouldEqual, 1)
 			})
 
 			Convey("Should return validation error if folder is named General", func() {
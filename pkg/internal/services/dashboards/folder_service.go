@@ -18,7 +18,10 @@ type FolderService interface {
 	GetFolderByUID(uid string) (*models.Folder, error)
 	GetFolderByTitle(title string) (*models.Folder, error)
 	CreateFolder(title, uid string) (*models.Folder, error)
+	CreateSubfolder(parentUID, title, uid string) (*models.Folder, error)
 	UpdateFolder(uid string, cmd *models.UpdateFolderCommand) error
+	MoveFolder(uid string, cmd *models.MoveFolderCommand) error
+	GetFolderParents(uid string) ([]*models.Folder, error)
 	DeleteFolder(uid string) (*models.Folder, error)
 	MakeUserAdmin(orgID int64, userID, folderID int64, setViewAndEditPermissions bool) error
 }
@@ -51,9 +54,10 @@ func (dr *dashboardServiceImpl) GetFolders(limit int64) ([]*models.Folder, error
 
 	for _, hit := range searchQuery.Result {
 		folders = append(folders, &models.Folder{
-			Id:    hit.ID,
-			Uid:   hit.UID,
-			Title: hit.Title,
+			Id:       hit.ID,
+			Uid:      hit.UID,
+			Title:    hit.Title,
+			ParentId: hit.FolderID,
 		})
 	}
 
@@ -115,8 +119,23 @@ func (dr *dashboardServiceImpl) GetFolderByTitle(title string) (*models.Folder,
 }
 
 func (dr *dashboardServiceImpl) CreateFolder(title, uid string) (*models.Folder, error) {
+	return dr.createFolder(0, title, uid)
+}
+
+// CreateSubfolder creates a folder inside the folder with the given UID.
+func (dr *dashboardServiceImpl) CreateSubfolder(parentUID, title, uid string) (*models.Folder, error) {
+	parent, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: parentUID})
+	if err != nil {
+		return nil, toFolderError(err)
+	}
+
+	return dr.createFolder(parent.Id, title, uid)
+}
+
+func (dr *dashboardServiceImpl) createFolder(parentID int64, title, uid string) (*models.Folder, error) {
 	dashFolder := models.NewDashboardFolder(title)
 	dashFolder.OrgId = dr.orgId
+	dashFolder.FolderId = parentID
 	dashFolder.SetUid(strings.TrimSpace(uid))
 	userID := dr.user.UserId
 	if userID == 0 {
@@ -188,6 +207,90 @@ func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.Upd
 	return nil
 }
 
+// MoveFolder moves a folder with its dashboards and subfolders into the folder with the UID
+// given by the command, or to the top level if it's empty.
+func (dr *dashboardServiceImpl) MoveFolder(uid string, cmd *models.MoveFolderCommand) error {
+	dashFolder, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	var parentID int64
+	if cmd.ParentUid != "" {
+		parent, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: cmd.ParentUid})
+		if err != nil {
+			return toFolderError(err)
+		}
+		parentID = parent.Id
+	}
+
+	userID := dr.user.UserId
+	if userID == 0 {
+		userID = -1
+	}
+	dashFolder.FolderId = parentID
+	dashFolder.UpdatedBy = userID
+	dashFolder.SetVersion(cmd.Version)
+
+	dto := &SaveDashboardDTO{
+		Dashboard: dashFolder,
+		OrgId:     dr.orgId,
+		User:      dr.user,
+		Overwrite: cmd.Overwrite,
+	}
+
+	saveDashboardCmd, err := dr.buildSaveDashboardCommand(dto, false, false)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dash, err := dr.dashboardStore.SaveDashboard(*saveDashboardCmd)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dashFolder, err = getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Id: dash.Id})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	cmd.Result = dashToFolder(dashFolder)
+
+	return nil
+}
+
+// GetFolderParents returns the folders above the folder with the given UID that the user can view,
+// starting with the top level folder.
+func (dr *dashboardServiceImpl) GetFolderParents(uid string) ([]*models.Folder, error) {
+	dashFolder, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid})
+	if err != nil {
+		return nil, toFolderError(err)
+	}
+
+	parents := make([]*models.Folder, 0)
+	if dashFolder.FolderId == 0 {
+		return parents, nil
+	}
+
+	ancestors, err := dr.dashboardStore.GetFolderAncestors(dr.orgId, dashFolder.FolderId)
+	if err != nil {
+		return nil, toFolderError(err)
+	}
+
+	for _, ancestor := range ancestors {
+		g := guardian.New(ancestor.Id, dr.orgId, dr.user)
+		canView, err := g.CanView()
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		if canView {
+			parents = append(parents, dashToFolder(ancestor))
+		}
+	}
+
+	return parents, nil
+}
+
 func (dr *dashboardServiceImpl) DeleteFolder(uid string) (*models.Folder, error) {
 	query := models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid}
 	dashFolder, err := getFolder(query)
@@ -231,6 +334,7 @@ func dashToFolder(dash *models.Dashboard) *models.Folder {
 		HasAcl:    dash.HasAcl,
 		Url:       dash.GetUrl(),
 		Version:   dash.Version,
+		ParentId:  dash.FolderId,
 		Created:   dash.Created,
 		CreatedBy: dash.CreatedBy,
 		Updated:   dash.Updated,
@@ -61,6 +61,16 @@ func TestFolderService(t *testing.T) {
 				So(err, ShouldEqual, models.ErrFolderAccessDenied)
 			})
 
+			Convey("When creating subfolder should return access denied error", func() {
+				_, err := service.CreateSubfolder("parent-uid", "Folder", "")
+				So(err, ShouldEqual, models.ErrFolderAccessDenied)
+			})
+
+			Convey("When moving folder should return access denied error", func() {
+				err := service.MoveFolder("uid", &models.MoveFolderCommand{ParentUid: "parent-uid"})
+				So(err, ShouldEqual, models.ErrFolderAccessDenied)
+			})
+
 			Convey("When deleting folder by uid should return access denied error", func() {
 				_, err := service.DeleteFolder("uid")
 				So(err, ShouldNotBeNil)
@@ -120,6 +130,18 @@ func TestFolderService(t *testing.T) {
 				So(err, ShouldBeNil)
 			})
 
+			Convey("When creating subfolder should not return access denied error", func() {
+				_, err := service.CreateSubfolder("parent-uid", "Subfolder", "")
+				So(err, ShouldBeNil)
+			})
+
+			Convey("When moving folder should not return access denied error", func() {
+				cmd := &models.MoveFolderCommand{ParentUid: "parent-uid"}
+				err := service.MoveFolder("uid", cmd)
+				So(err, ShouldBeNil)
+				So(cmd.Result.Id, ShouldEqual, dash.Id)
+			})
+
 			Reset(func() {
 				guardian.New = origNewGuardian
 			})
@@ -614,35 +614,63 @@ func (l *LibraryElementService) connectElementsToDashboardID(c *models.ReqContex
 		if err != nil {
 			return err
 		}
-		for _, elementUID := range elementUIDs {
-			element, err := getLibraryElement(l.SQLStore.Dialect, session, elementUID, c.SignedInUser.OrgId)
-			if err != nil {
-				return err
-			}
-			if err := l.requirePermissionsOnFolder(c.SignedInUser, element.FolderID); err != nil {
-				return err
-			}
+		return l.insertDashboardConnections(c, session, nil, elementUIDs, dashboardID)
+	})
 
-			connection := libraryElementConnection{
-				ElementID:    element.ID,
-				Kind:         1,
-				ConnectionID: dashboardID,
-				Created:      time.Now(),
-				CreatedBy:    c.SignedInUser.UserId,
-			}
-			if _, err := session.Insert(&connection); err != nil {
-				if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
-					return nil
-				}
-				return err
-			}
+	return err
+}
+
+// connectElementsOfKindToDashboardID adds connections for all Library Elements of a kind in a Dashboard.
+func (l *LibraryElementService) connectElementsOfKindToDashboardID(c *models.ReqContext, kind LibraryElementKind, elementUIDs []string, dashboardID int64) error {
+	err := l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		_, err := session.Exec("DELETE FROM "+connectionTableName+" WHERE kind=1 AND connection_id=? AND element_id IN (SELECT id FROM library_element WHERE kind=?)", dashboardID, int64(kind))
+		if err != nil {
+			return err
 		}
-		return nil
+		return l.insertDashboardConnections(c, session, &kind, elementUIDs, dashboardID)
 	})
 
 	return err
 }
 
+// insertDashboardConnections connects elements to a dashboard. If kind is set, all elements must be of that kind.
+// Elements that are already connected to the dashboard are skipped.
+func (l *LibraryElementService) insertDashboardConnections(c *models.ReqContext, session *sqlstore.DBSession, kind *LibraryElementKind, elementUIDs []string, dashboardID int64) error {
+	connected := make(map[string]bool, len(elementUIDs))
+	for _, elementUID := range elementUIDs {
+		if connected[elementUID] {
+			continue
+		}
+		connected[elementUID] = true
+
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, elementUID, c.SignedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		if kind != nil && LibraryElementKind(element.Kind) != *kind {
+			return errLibraryElementUnSupportedElementKind
+		}
+		if err := l.requirePermissionsOnFolder(c.SignedInUser, element.FolderID); err != nil {
+			return err
+		}
+
+		connection := libraryElementConnection{
+			ElementID:    element.ID,
+			Kind:         1,
+			ConnectionID: dashboardID,
+			Created:      time.Now(),
+			CreatedBy:    c.SignedInUser.UserId,
+		}
+		if _, err := session.Insert(&connection); err != nil {
+			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // disconnectElementsFromDashboardID deletes connections for all Library Elements in a Dashboard.
 func (l *LibraryElementService) disconnectElementsFromDashboardID(c *models.ReqContext, dashboardID int64) error {
 	return l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
@@ -672,13 +700,26 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c *models.ReqCo
 		if err := l.requirePermissionsOnFolder(c.SignedInUser, folderID); err != nil {
 			return err
 		}
+
+		// library elements in subfolders are deleted together with the folder
+		subfolders, err := l.SQLStore.GetFolderDescendants(c.SignedInUser.OrgId, folderID)
+		if err != nil {
+			return err
+		}
+		params := []interface{}{folderID}
+		for _, subfolder := range subfolders {
+			params = append(params, subfolder.Id)
+		}
+		folderIDsIn := "?" + strings.Repeat(",?", len(params)-1)
+		params = append(params, c.SignedInUser.OrgId)
+
 		var connectionIDs []struct {
 			ConnectionID int64 `xorm:"connection_id"`
 		}
 		sql := "SELECT lec.connection_id FROM library_element AS le"
 		sql += " INNER JOIN " + connectionTableName + " AS lec on le.id = lec.element_id"
-		sql += " WHERE le.folder_id=? AND le.org_id=?"
-		err = session.SQL(sql, folderID, c.SignedInUser.OrgId).Find(&connectionIDs)
+		sql += " WHERE le.folder_id IN (" + folderIDsIn + ") AND le.org_id=?"
+		err = session.SQL(sql, params...).Find(&connectionIDs)
 		if err != nil {
 			return err
 		}
@@ -689,7 +730,7 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c *models.ReqCo
 		var elementIDs []struct {
 			ID int64 `xorm:"id"`
 		}
-		err = session.SQL("SELECT id from library_element WHERE folder_id=? AND org_id=?", folderID, c.SignedInUser.OrgId).Find(&elementIDs)
+		err = session.SQL("SELECT id from library_element WHERE folder_id IN ("+folderIDsIn+") AND org_id=?", params...).Find(&elementIDs)
 		if err != nil {
 			return err
 		}
@@ -699,7 +740,8 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c *models.ReqCo
 				return err
 			}
 		}
-		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, c.SignedInUser.OrgId); err != nil {
+		deleteParams := append([]interface{}{"DELETE FROM library_element WHERE folder_id IN (" + folderIDsIn + ") AND org_id=?"}, params...)
+		if _, err := session.Exec(deleteParams...); err != nil {
 			return err
 		}
 
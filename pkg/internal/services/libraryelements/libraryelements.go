@@ -15,6 +15,7 @@ type Service interface {
 	CreateElement(c *models.ReqContext, cmd CreateLibraryElementCommand) (LibraryElementDTO, error)
 	GetElementsForDashboard(c *models.ReqContext, dashboardID int64) (map[string]LibraryElementDTO, error)
 	ConnectElementsToDashboard(c *models.ReqContext, elementUIDs []string, dashboardID int64) error
+	ConnectElementsOfKindToDashboard(c *models.ReqContext, kind LibraryElementKind, elementUIDs []string, dashboardID int64) error
 	DisconnectElementsFromDashboard(c *models.ReqContext, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c *models.ReqContext, folderUID string) error
 }
@@ -57,6 +58,12 @@ func (l *LibraryElementService) ConnectElementsToDashboard(c *models.ReqContext,
 	return l.connectElementsToDashboardID(c, elementUIDs, dashboardID)
 }
 
+// ConnectElementsOfKindToDashboard connects elements of a specific kind to a specific dashboard, leaving
+// connections to elements of other kinds untouched.
+func (l *LibraryElementService) ConnectElementsOfKindToDashboard(c *models.ReqContext, kind LibraryElementKind, elementUIDs []string, dashboardID int64) error {
+	return l.connectElementsOfKindToDashboardID(c, kind, elementUIDs, dashboardID)
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *LibraryElementService) DisconnectElementsFromDashboard(c *models.ReqContext, dashboardID int64) error {
 	return l.disconnectElementsFromDashboardID(c, dashboardID)
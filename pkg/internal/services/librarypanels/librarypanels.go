@@ -182,5 +182,5 @@ func (lps *LibraryPanelService) ConnectLibraryPanelsForDashboard(c *models.ReqCo
 		libraryPanels = append(libraryPanels, uid)
 	}
 
-	return lps.LibraryElementService.ConnectElementsToDashboard(c, libraryPanels, dash.Id)
+	return lps.LibraryElementService.ConnectElementsOfKindToDashboard(c, libraryelements.Panel, libraryPanels, dash.Id)
 }
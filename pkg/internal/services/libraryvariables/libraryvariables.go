@@ -0,0 +1,170 @@
+package libraryvariables
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/registry"
+	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
+)
+
+// Service is a service for operating on library variables.
+type Service interface {
+	LoadLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error
+	CleanLibraryVariablesForDashboard(dash *models.Dashboard) error
+	ConnectLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error
+}
+
+// LibraryVariableService is the service for template variables stored as library elements.
+type LibraryVariableService struct {
+	LibraryElementService libraryelements.Service `inject:""`
+}
+
+func init() {
+	registry.RegisterService(&LibraryVariableService{})
+}
+
+// Init initializes the LibraryVariable service
+func (lvs *LibraryVariableService) Init() error {
+	return nil
+}
+
+// LoadLibraryVariablesForDashboard loops through all template variables in dashboard JSON and replaces any library
+// variable JSON with JSON stored for the library variable in db. Since the definition is always read from the
+// library element, changes to the library variable show up in every connected dashboard.
+func (lvs *LibraryVariableService) LoadLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error {
+	elements, err := lvs.LibraryElementService.GetElementsForDashboard(c, dash.Id)
+	if err != nil {
+		return err
+	}
+
+	variables := dash.Data.Get("templating").Get("list").MustArray()
+	for i, variable := range variables {
+		variableAsJSON := simplejson.NewFromAny(variable)
+		libraryVariable := variableAsJSON.Get("libraryVariable")
+		if libraryVariable.Interface() == nil {
+			continue
+		}
+
+		// we have a library variable
+		uid := libraryVariable.Get("uid").MustString()
+		if len(uid) == 0 {
+			return errLibraryVariableHeaderUIDMissing
+		}
+
+		elementInDB, ok := elements[uid]
+		if !ok || libraryelements.LibraryElementKind(elementInDB.Kind) != libraryelements.Variable {
+			// keep the header so that the dashboard still shows which library variable is missing
+			continue
+		}
+
+		// we have a match between what is stored in db and in dashboard json
+		libraryVariableModel, err := elementInDB.Model.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("could not marshal library variable JSON: %w", err)
+		}
+
+		libraryVariableModelAsJSON, err := simplejson.NewJson(libraryVariableModel)
+		if err != nil {
+			return fmt.Errorf("could not convert library variable to simplejson model: %w", err)
+		}
+
+		// set the library variable json as the new variable json in dashboard json
+		dash.Data.Get("templating").Get("list").SetIndex(i, libraryVariableModelAsJSON.Interface())
+
+		// set dashboard specific props
+		elem := dash.Data.Get("templating").Get("list").GetIndex(i)
+		if current, ok := variableAsJSON.CheckGet("current"); ok {
+			elem.Set("current", current.Interface())
+		}
+		elem.Set("libraryVariable", map[string]interface{}{
+			"uid":         elementInDB.UID,
+			"name":        elementInDB.Name,
+			"type":        elementInDB.Type,
+			"description": elementInDB.Description,
+			"version":     elementInDB.Version,
+			"meta": map[string]interface{}{
+				"folderName":          elementInDB.Meta.FolderName,
+				"folderUid":           elementInDB.Meta.FolderUID,
+				"connectedDashboards": elementInDB.Meta.ConnectedDashboards,
+				"created":             elementInDB.Meta.Created,
+				"updated":             elementInDB.Meta.Updated,
+				"createdBy": map[string]interface{}{
+					"id":        elementInDB.Meta.CreatedBy.ID,
+					"name":      elementInDB.Meta.CreatedBy.Name,
+					"avatarUrl": elementInDB.Meta.CreatedBy.AvatarURL,
+				},
+				"updatedBy": map[string]interface{}{
+					"id":        elementInDB.Meta.UpdatedBy.ID,
+					"name":      elementInDB.Meta.UpdatedBy.Name,
+					"avatarUrl": elementInDB.Meta.UpdatedBy.AvatarURL,
+				},
+			},
+		})
+	}
+
+	return nil
+}
+
+// CleanLibraryVariablesForDashboard loops through all template variables in dashboard JSON and cleans up any library
+// variable JSON so that only the necessary JSON properties remain when storing the dashboard JSON.
+func (lvs *LibraryVariableService) CleanLibraryVariablesForDashboard(dash *models.Dashboard) error {
+	variables := dash.Data.Get("templating").Get("list").MustArray()
+	for i, variable := range variables {
+		variableAsJSON := simplejson.NewFromAny(variable)
+		libraryVariable := variableAsJSON.Get("libraryVariable")
+		if libraryVariable.Interface() == nil {
+			continue
+		}
+
+		// we have a library variable
+		uid := libraryVariable.Get("uid").MustString()
+		if len(uid) == 0 {
+			return errLibraryVariableHeaderUIDMissing
+		}
+		name := libraryVariable.Get("name").MustString()
+		if len(name) == 0 {
+			return errLibraryVariableHeaderNameMissing
+		}
+
+		// keep only the necessary JSON properties, the rest of the properties should be safely stored in library_element table
+		cleaned := map[string]interface{}{
+			"name": name,
+			"libraryVariable": map[string]interface{}{
+				"uid":  uid,
+				"name": name,
+			},
+		}
+		// the selected value is specific to the dashboard
+		if current, ok := variableAsJSON.CheckGet("current"); ok {
+			cleaned["current"] = current.Interface()
+		}
+		dash.Data.Get("templating").Get("list").SetIndex(i, cleaned)
+	}
+
+	return nil
+}
+
+// ConnectLibraryVariablesForDashboard loops through all template variables in dashboard JSON and connects any library
+// variables to the dashboard.
+func (lvs *LibraryVariableService) ConnectLibraryVariablesForDashboard(c *models.ReqContext, dash *models.Dashboard) error {
+	variables := dash.Data.Get("templating").Get("list").MustArray()
+	var libraryVariables []string
+	for _, variable := range variables {
+		variableAsJSON := simplejson.NewFromAny(variable)
+		libraryVariable := variableAsJSON.Get("libraryVariable")
+		if libraryVariable.Interface() == nil {
+			continue
+		}
+
+		// we have a library variable
+		uid := libraryVariable.Get("uid").MustString()
+		if len(uid) == 0 {
+			return errLibraryVariableHeaderUIDMissing
+		}
+		libraryVariables = append(libraryVariables, uid)
+	}
+
+	return lvs.LibraryElementService.ConnectElementsOfKindToDashboard(c, libraryelements.Variable, libraryVariables, dash.Id)
+}
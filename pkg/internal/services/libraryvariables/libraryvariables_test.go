@@ -0,0 +1,401 @@
+package libraryvariables
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	dboards "github.com/grafana/grafana/pkg/internal/dashboards"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/registry"
+	"github.com/grafana/grafana/pkg/internal/services/dashboards"
+	"github.com/grafana/grafana/pkg/internal/services/libraryelements"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+	"github.com/grafana/grafana/pkg/internal/setting"
+)
+
+func TestLoadLibraryVariablesForDashboard(t *testing.T) {
+	scenarioWithLibraryVariable(t, "When an admin tries to load a dashboard with a library variable, it should copy JSON properties from library variable",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"name": "interval",
+							"type": "interval",
+						},
+						map[string]interface{}{
+							"name":    "cluster",
+							"current": map[string]interface{}{"text": "eu-west", "value": "eu-west"},
+							"libraryVariable": map[string]interface{}{
+								"uid":  sc.initialResult.UID,
+								"name": sc.initialResult.Name,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing LoadLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.elementService.ConnectElementsOfKindToDashboard(sc.reqContext, libraryelements.Variable, []string{sc.initialResult.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			err = sc.service.LoadLibraryVariablesForDashboard(sc.reqContext, dashInDB)
+			require.NoError(t, err)
+
+			variables := dashInDB.Data.Get("templating").Get("list")
+			require.Equal(t, "interval", variables.GetIndex(0).Get("name").MustString())
+
+			variable := variables.GetIndex(1)
+			require.Equal(t, "cluster", variable.Get("name").MustString())
+			require.Equal(t, "query", variable.Get("type").MustString())
+			require.Equal(t, "label_values(up, cluster)", variable.Get("query").MustString())
+			require.Equal(t, "eu-west", variable.Get("current").Get("value").MustString())
+			require.Equal(t, sc.initialResult.UID, variable.Get("libraryVariable").Get("uid").MustString())
+			require.Equal(t, int64(1), variable.Get("libraryVariable").Get("version").MustInt64())
+			require.Equal(t, int64(1), variable.Get("libraryVariable").Get("meta").Get("connectedDashboards").MustInt64())
+		})
+
+	scenarioWithLibraryVariable(t, "When an admin tries to load a dashboard with a library variable that isn't connected, it should keep the header",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"name": "cluster",
+							"libraryVariable": map[string]interface{}{
+								"uid":  "unknown",
+								"name": "cluster",
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing LoadLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			err := sc.service.LoadLibraryVariablesForDashboard(sc.reqContext, dashInDB)
+			require.NoError(t, err)
+
+			variable := dashInDB.Data.Get("templating").Get("list").GetIndex(0)
+			require.Equal(t, "unknown", variable.Get("libraryVariable").Get("uid").MustString())
+			_, hasQuery := variable.CheckGet("query")
+			require.False(t, hasQuery)
+		})
+}
+
+func TestCleanLibraryVariablesForDashboard(t *testing.T) {
+	scenarioWithLibraryVariable(t, "When an admin tries to store a dashboard with a library variable, it should only keep the header and the current value",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"name":    "cluster",
+							"type":    "query",
+							"query":   "label_values(up, cluster)",
+							"current": map[string]interface{}{"text": "eu-west", "value": "eu-west"},
+							"libraryVariable": map[string]interface{}{
+								"uid":  sc.initialResult.UID,
+								"name": sc.initialResult.Name,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing CleanLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+
+			err := sc.service.CleanLibraryVariablesForDashboard(&dash)
+			require.NoError(t, err)
+
+			expected := map[string]interface{}{
+				"name":    "cluster",
+				"current": map[string]interface{}{"text": "eu-west", "value": "eu-west"},
+				"libraryVariable": map[string]interface{}{
+					"uid":  sc.initialResult.UID,
+					"name": sc.initialResult.Name,
+				},
+			}
+			require.Equal(t, expected, dash.Data.Get("templating").Get("list").GetIndex(0).MustMap())
+		})
+
+	scenarioWithLibraryVariable(t, "When an admin tries to store a dashboard with a library variable without name, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"libraryVariable": map[string]interface{}{
+								"uid": sc.initialResult.UID,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing CleanLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+
+			err := sc.service.CleanLibraryVariablesForDashboard(&dash)
+			require.EqualError(t, err, errLibraryVariableHeaderNameMissing.Error())
+		})
+}
+
+func TestConnectLibraryVariablesForDashboard(t *testing.T) {
+	scenarioWithLibraryVariable(t, "When an admin tries to store a dashboard with a library variable, it should connect the two and keep library panel connections",
+		func(t *testing.T, sc scenarioContext) {
+			panel, err := sc.elementService.CreateElement(sc.reqContext, libraryelements.CreateLibraryElementCommand{
+				FolderID: sc.folder.Id,
+				Name:     "Text - Library Panel",
+				Model:    []byte(`{"title": "Text - Library Panel", "type": "text"}`),
+				Kind:     int64(libraryelements.Panel),
+			})
+			require.NoError(t, err)
+
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"name": "cluster",
+							"libraryVariable": map[string]interface{}{
+								"uid":  sc.initialResult.UID,
+								"name": sc.initialResult.Name,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing ConnectLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err = sc.elementService.ConnectElementsOfKindToDashboard(sc.reqContext, libraryelements.Panel, []string{panel.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			err = sc.service.ConnectLibraryVariablesForDashboard(sc.reqContext, dashInDB)
+			require.NoError(t, err)
+
+			elements, err := sc.elementService.GetElementsForDashboard(sc.reqContext, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, elements, 2)
+			require.Contains(t, elements, sc.initialResult.UID)
+			require.Contains(t, elements, panel.UID)
+		})
+
+	scenarioWithLibraryVariable(t, "When an admin tries to store a dashboard using a library variable twice, it should connect the variables that follow",
+		func(t *testing.T, sc scenarioContext) {
+			other, err := sc.elementService.CreateElement(sc.reqContext, libraryelements.CreateLibraryElementCommand{
+				FolderID: sc.folder.Id,
+				Name:     "namespace",
+				Model:    []byte(`{"name": "namespace", "type": "query"}`),
+				Kind:     int64(libraryelements.Variable),
+			})
+			require.NoError(t, err)
+
+			libraryVariable := map[string]interface{}{
+				"name": "cluster",
+				"libraryVariable": map[string]interface{}{
+					"uid":  sc.initialResult.UID,
+					"name": sc.initialResult.Name,
+				},
+			}
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						libraryVariable,
+						libraryVariable,
+						map[string]interface{}{
+							"name": "namespace",
+							"libraryVariable": map[string]interface{}{
+								"uid":  other.UID,
+								"name": other.Name,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing ConnectLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			err = sc.service.ConnectLibraryVariablesForDashboard(sc.reqContext, dashInDB)
+			require.NoError(t, err)
+
+			elements, err := sc.elementService.GetElementsForDashboard(sc.reqContext, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, elements, 2)
+			require.Contains(t, elements, sc.initialResult.UID)
+			require.Contains(t, elements, other.UID)
+		})
+
+	scenarioWithLibraryVariable(t, "When an admin tries to connect a library panel as a library variable, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			panel, err := sc.elementService.CreateElement(sc.reqContext, libraryelements.CreateLibraryElementCommand{
+				FolderID: sc.folder.Id,
+				Name:     "Text - Library Panel",
+				Model:    []byte(`{"title": "Text - Library Panel", "type": "text"}`),
+				Kind:     int64(libraryelements.Panel),
+			})
+			require.NoError(t, err)
+
+			dashJSON := map[string]interface{}{
+				"templating": map[string]interface{}{
+					"list": []interface{}{
+						map[string]interface{}{
+							"name": "cluster",
+							"libraryVariable": map[string]interface{}{
+								"uid":  panel.UID,
+								"name": panel.Name,
+							},
+						},
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing ConnectLibraryVariablesForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			err = sc.service.ConnectLibraryVariablesForDashboard(sc.reqContext, dashInDB)
+			require.Error(t, err)
+		})
+}
+
+type scenarioContext struct {
+	ctx            *macaron.Context
+	service        Service
+	elementService libraryelements.Service
+	reqContext     *models.ReqContext
+	user           models.SignedInUser
+	folder         *models.Folder
+	initialResult  libraryelements.LibraryElementDTO
+	sqlStore       *sqlstore.SQLStore
+}
+
+func createDashboard(t *testing.T, sqlStore *sqlstore.SQLStore, user models.SignedInUser, dash *models.Dashboard, folderID int64) *models.Dashboard {
+	dash.FolderId = folderID
+	dashItem := &dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   "",
+		OrgId:     user.OrgId,
+		User:      &user,
+		Overwrite: false,
+	}
+	origUpdateAlerting := dashboards.UpdateAlerting
+	t.Cleanup(func() {
+		dashboards.UpdateAlerting = origUpdateAlerting
+	})
+	dashboards.UpdateAlerting = func(store dboards.Store, orgID int64, dashboard *models.Dashboard,
+		user *models.SignedInUser) error {
+		return nil
+	}
+
+	dashboard, err := dashboards.NewService(sqlStore).SaveDashboard(dashItem, true)
+	require.NoError(t, err)
+
+	return dashboard
+}
+
+func scenarioWithLibraryVariable(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	testScenario(t, desc, func(t *testing.T, sc scenarioContext) {
+		command := libraryelements.CreateLibraryElementCommand{
+			FolderID: sc.folder.Id,
+			Name:     "cluster",
+			Model: []byte(`
+			{
+			  "name": "cluster",
+			  "type": "query",
+			  "datasource": "${DS_PROMETHEUS}",
+			  "query": "label_values(up, cluster)",
+			  "description": "A description"
+			}
+		`),
+			Kind: int64(libraryelements.Variable),
+		}
+		resp, err := sc.elementService.CreateElement(sc.reqContext, command)
+		require.NoError(t, err)
+
+		sc.initialResult = resp
+
+		fn(t, sc)
+	})
+}
+
+// testScenario is a wrapper around t.Run performing common setup for library variable tests.
+// It takes your real test function as a callback.
+func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		t.Cleanup(registry.ClearOverrides)
+
+		ctx := macaron.Context{
+			Req: macaron.Request{Request: &http.Request{}},
+		}
+		cfg := setting.NewCfg()
+		sqlStore := sqlstore.InitTestDB(t)
+		elementService := libraryelements.LibraryElementService{
+			Cfg:      cfg,
+			SQLStore: sqlStore,
+		}
+		service := LibraryVariableService{
+			LibraryElementService: &elementService,
+		}
+
+		user := models.SignedInUser{
+			UserId:     1,
+			Name:       "Signed In User",
+			Login:      "signed_in_user",
+			Email:      "signed.in.user@test.com",
+			OrgId:      1,
+			OrgRole:    models.ROLE_ADMIN,
+			LastSeenAt: time.Now(),
+		}
+
+		_, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{
+			Email: "user.in.db@test.com",
+			Name:  "User In DB",
+			Login: "user_in_db",
+		})
+		require.NoError(t, err)
+
+		sc := scenarioContext{
+			user:           user,
+			ctx:            &ctx,
+			service:        &service,
+			elementService: &elementService,
+			sqlStore:       sqlStore,
+			reqContext: &models.ReqContext{
+				Context:      &ctx,
+				SignedInUser: &user,
+			},
+		}
+
+		folder, err := dashboards.NewFolderService(user.OrgId, &user, sqlStore).CreateFolder("ScenarioFolder", "ScenarioFolder")
+		require.NoError(t, err)
+		sc.folder = folder
+
+		fn(t, sc)
+	})
+}
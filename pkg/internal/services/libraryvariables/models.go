@@ -0,0 +1,12 @@
+package libraryvariables
+
+import (
+	"errors"
+)
+
+var (
+	// errLibraryVariableHeaderUIDMissing is an error for when a library variable header is missing the uid property.
+	errLibraryVariableHeaderUIDMissing = errors.New("library variable header is missing required property uid")
+	// errLibraryVariableHeaderNameMissing is an error for when a library variable header is missing the name property.
+	errLibraryVariableHeaderNameMissing = errors.New("library variable header is missing required property name")
+)
@@ -35,6 +35,9 @@ type Alertmanager interface {
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
 	GetAlertGroups(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.AlertGroups, error)
+
+	// Templates
+	PreviewTemplates(preview *apimodels.PostableTemplatePreview) (*apimodels.TemplatePreviewResults, error)
 }
 
 // API handlers.
@@ -48,6 +51,7 @@ type API struct {
 	RuleStore       store.RuleStore
 	InstanceStore   store.InstanceStore
 	AlertingStore   store.AlertingStore
+	RecordingStore  store.RecordingStore
 	DataProxy       *datasourceproxy.DatasourceProxyService
 	Alertmanager    Alertmanager
 	StateManager    *state.Manager
@@ -85,4 +89,15 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 		DatasourceCache: api.DatasourceCache,
 		log:             logger,
 	}, m)
+	api.RegisterSchedulerApiEndpoints(SchedulerSrv{schedule: api.Schedule, log: logger}, m)
+	api.RegisterRecordingApiEndpoints(RecordingSrv{store: api.RecordingStore, log: logger}, m)
+	api.RegisterImportApiEndpoints(ImportSrv{
+		DatasourceCache: api.DatasourceCache,
+		QuotaService:    api.QuotaService,
+		manager:         api.StateManager,
+		store:           api.RuleStore,
+		log:             logger,
+	}, m)
+	api.RegisterExportApiEndpoints(ExportSrv{store: api.RuleStore, log: logger}, m)
+	api.RegisterTemplatePreviewApiEndpoints(TemplatePreviewSrv{am: api.Alertmanager, log: logger}, m)
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/internal/api/response"
+	"github.com/grafana/grafana/pkg/internal/api/routing"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/middleware"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+)
+
+type ExportSrv struct {
+	store store.RuleStore
+	log   log.Logger
+}
+
+func (srv ExportSrv) RouteGetExportRules(c *models.ReqContext) response.Response {
+	format := c.Query("format")
+	if format != "" && format != "yaml" && format != "json" {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("unsupported format %s", format), nil)
+	}
+
+	rulesByNamespace := make(map[string][]*ngmodels.AlertRule)
+	if namespaceTitle := c.Query("namespace"); namespaceTitle != "" {
+		namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		q := ngmodels.ListNamespaceAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespace.Uid}
+		if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to get alert rules", err)
+		}
+		rulesByNamespace[namespace.Uid] = q.Result
+	} else {
+		q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+		if err := srv.store.GetOrgAlertRules(&q); err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to get alert rules", err)
+		}
+		canView := make(map[string]bool)
+		for _, r := range q.Result {
+			ok, checked := canView[r.NamespaceUID]
+			if !checked {
+				_, err := srv.store.GetNamespaceByUID(r.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser)
+				if err != nil && !errors.Is(err, models.ErrFolderAccessDenied) {
+					return toNamespaceErrorResponse(err)
+				}
+				// the folders the user cannot access are not exported
+				ok = err == nil
+				canView[r.NamespaceUID] = ok
+			}
+			if ok {
+				rulesByNamespace[r.NamespaceUID] = append(rulesByNamespace[r.NamespaceUID], r)
+			}
+		}
+	}
+
+	result := make(apimodels.RulesExport, len(rulesByNamespace))
+	for namespace, rules := range rulesByNamespace {
+		result[namespace] = toPostableRuleGroupConfigs(rules)
+	}
+
+	if format == "json" {
+		return response.JSON(http.StatusOK, result)
+	}
+	b, err := yaml.Marshal(result)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to marshal alert rules", err)
+	}
+	return response.Respond(http.StatusOK, b).SetHeader("Content-Type", "application/yaml")
+}
+
+// toPostableRuleGroupConfigs groups the rules of a namespace, ordered by rule group index, into rule groups
+// ordered by name.
+func toPostableRuleGroupConfigs(rules []*ngmodels.AlertRule) []apimodels.PostableRuleGroupConfig {
+	indexes := make(map[string]int)
+	configs := make([]apimodels.PostableRuleGroupConfig, 0)
+	for _, r := range rules {
+		i, ok := indexes[r.RuleGroup]
+		if !ok {
+			i = len(configs)
+			indexes[r.RuleGroup] = i
+			configs = append(configs, apimodels.PostableRuleGroupConfig{
+				Name:       r.RuleGroup,
+				Interval:   model.Duration(time.Duration(r.IntervalSeconds) * time.Second),
+				Paused:     r.RuleGroupPaused,
+				Sequential: r.RuleGroupSequential,
+			})
+		}
+		configs[i].Rules = append(configs[i].Rules, toPostableExtendedRuleNode(*r))
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+	return configs
+}
+
+func toPostableExtendedRuleNode(r ngmodels.AlertRule) apimodels.PostableExtendedRuleNode {
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:           model.Duration(r.For),
+			KeepFiringFor: model.Duration(r.KeepFiringFor),
+			Annotations:   r.Annotations,
+			Labels:        r.Labels,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:        r.Title,
+			Condition:    r.Condition,
+			Data:         r.Data,
+			UID:          r.UID,
+			NoDataState:  apimodels.NoDataState(r.NoDataState),
+			ExecErrState: apimodels.ExecutionErrorState(r.ExecErrState),
+			Record:       r.Record,
+			IsPaused:     r.IsPaused,
+		},
+	}
+}
+
+// RegisterExportApiEndpoints registers the endpoint exporting the Grafana managed rules, which requires the
+// permission to view their folders.
+func (api *API) RegisterExportApiEndpoints(srv ExportSrv, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/rules/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/export",
+				srv.RouteGetExportRules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+func TestToPostableRuleGroupConfigs(t *testing.T) {
+	data := []ngmodels.AlertQuery{
+		{
+			RefID:             "A",
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)},
+			DatasourceUID:     "prom",
+			Model:             json.RawMessage(`{"expr":"up","refId":"A"}`),
+		},
+		{
+			RefID:         "B",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"expression":"$A == 0","refId":"B","type":"math"}`),
+		},
+	}
+	rules := []*ngmodels.AlertRule{
+		{UID: "down", Title: "Service down", Condition: "B", Data: data, RuleGroup: "services", IntervalSeconds: 60,
+			For: 5 * time.Minute, Labels: map[string]string{"severity": "critical"}, NoDataState: ngmodels.Alerting,
+			ExecErrState: ngmodels.AlertingErrState, RuleGroupSequential: true},
+		{UID: "latency", Title: "Latency", Condition: "B", Data: data, RuleGroup: "api", IntervalSeconds: 30,
+			NoDataState: ngmodels.OK, ExecErrState: ngmodels.AlertingErrState, RuleGroupPaused: true},
+		{UID: "flapping", Title: "Service flapping", Condition: "B", Data: data, RuleGroup: "services", IntervalSeconds: 60,
+			Annotations: map[string]string{"summary": "{{ $labels.job }} is flapping"}, NoDataState: ngmodels.NoData,
+			ExecErrState: ngmodels.AlertingErrState, IsPaused: true, RuleGroupIndex: 1, RuleGroupSequential: true},
+	}
+
+	configs := toPostableRuleGroupConfigs(rules)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "api", configs[0].Name)
+	assert.True(t, configs[0].Paused)
+	assert.Equal(t, "services", configs[1].Name)
+	assert.True(t, configs[1].Sequential)
+	require.Len(t, configs[1].Rules, 2)
+	assert.Equal(t, "Service down", configs[1].Rules[0].GrafanaManagedAlert.Title)
+	assert.Equal(t, "Service flapping", configs[1].Rules[1].GrafanaManagedAlert.Title)
+
+	t.Run("the rule groups round trip through YAML and JSON", func(t *testing.T) {
+		export := apimodels.RulesExport{"services-folder-uid": configs}
+
+		b, err := yaml.Marshal(export)
+		require.NoError(t, err)
+		var fromYAML apimodels.RulesExport
+		require.NoError(t, yaml.Unmarshal(b, &fromYAML))
+		assert.Equal(t, export, fromYAML)
+
+		b, err = json.Marshal(export)
+		require.NoError(t, err)
+		var fromJSON apimodels.RulesExport
+		require.NoError(t, json.Unmarshal(b, &fromJSON))
+		assert.Equal(t, export, fromJSON)
+	})
+}
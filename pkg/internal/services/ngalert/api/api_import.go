@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/internal/api/response"
+	"github.com/grafana/grafana/pkg/internal/api/routing"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/middleware"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/datasources"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/internal/services/quota"
+	"github.com/grafana/grafana/pkg/internal/util"
+)
+
+type ImportSrv struct {
+	DatasourceCache datasources.CacheService
+	QuotaService    *quota.QuotaService
+	manager         *state.Manager
+	store           store.RuleStore
+	log             log.Logger
+}
+
+func (srv ImportSrv) RoutePostImportRules(c *models.ReqContext) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	limitReached, err := srv.QuotaService.QuotaReached(c, "alert_rule")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get quota", err)
+	}
+	if limitReached {
+		return response.Error(http.StatusForbidden, "quota reached", nil)
+	}
+
+	datasourceUID := c.Query("datasourceUid")
+	ds, err := srv.DatasourceCache.GetDatasourceByUID(datasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to get data source %s", datasourceUID), err)
+	}
+	converter, err := prom.NewConverter(ds)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	body, err := ioutil.ReadAll(c.Req.Request.Body)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "failed to read rule file", err)
+	}
+	file, err := prom.ParseRuleFile(body)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	configs, err := converter.ConvertRuleFile(file)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "failed to convert rule file", err)
+	}
+	for _, config := range configs {
+		for _, r := range config.Rules {
+			if err := validateTemplates(r.ApiRuleNode); err != nil {
+				return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate alert rule %s", r.GrafanaManagedAlert.Title), err)
+			}
+		}
+	}
+
+	replaced, err := prom.SaveRuleGroups(srv.store, c.SignedInUser.OrgId, namespace.Uid, configs)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return response.Error(http.StatusBadRequest, "failed to import rule groups", err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to import rule groups", err)
+	}
+	for _, uid := range replaced {
+		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": fmt.Sprintf("%d rule groups imported", len(configs))})
+}
+
+// RegisterImportApiEndpoints registers the endpoint importing the rule files of Prometheus and Loki, which requires
+// the permission to edit the folder of the rules.
+func (api *API) RegisterImportApiEndpoints(srv ImportSrv, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/rules/import/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/import/{Namespace}",
+				srv.RoutePostImportRules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -117,6 +117,19 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				LastEvaluation: time.Time{},
 			}
 
+			if rule.IsRecording() {
+				// recording rules have no alert states
+				newRule.Name = rule.Record.Metric
+				newRule.Query = queryStr
+				newRule.Type = apiv1.RuleTypeRecording
+				if rule.Paused() {
+					newRule.Health = "paused"
+				}
+				newGroup.Rules = append(newGroup.Rules, apimodels.AlertingRule{Name: newRule.Name, Query: queryStr, Rule: newRule})
+				newGroup.Interval = float64(rule.IntervalSeconds)
+				continue
+			}
+
 			for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
 				activeAt := alertState.StartsAt
 				valString := ""
@@ -160,6 +173,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				alertingRule.Alerts = append(alertingRule.Alerts, alert)
 			}
 
+			if rule.Paused() {
+				newRule.Health = "paused"
+			}
+
 			alertingRule.Rule = newRule
 			newGroup.Rules = append(newGroup.Rules, alertingRule)
 			newGroup.Interval = float64(rule.IntervalSeconds)
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/internal/api/response"
+	"github.com/grafana/grafana/pkg/internal/api/routing"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/middleware"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/internal/util"
+)
+
+type RecordingSrv struct {
+	store store.RecordingStore
+	log   log.Logger
+}
+
+func (srv RecordingSrv) RouteGetRecordingConfig(c *models.ReqContext) response.Response {
+	q := ngmodels.GetRecordingConfigQuery{OrgID: c.OrgId}
+	if err := srv.store.GetRecordingConfig(&q); err != nil {
+		if errors.Is(err, store.ErrNoRecordingConfiguration) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to get recording configuration", err)
+	}
+
+	return response.JSON(http.StatusOK, apimodels.GettableRecordingConfig{
+		RemoteWriteURL:       q.Result.RemoteWriteURL,
+		BasicAuthUser:        q.Result.BasicAuthUser,
+		BasicAuthPasswordSet: len(q.Result.SecureSettings["basicAuthPassword"]) > 0,
+	})
+}
+
+func (srv RecordingSrv) RoutePostRecordingConfig(c *models.ReqContext, body apimodels.PostableRecordingConfig) response.Response {
+	u, err := url.Parse(body.RemoteWriteURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return response.Error(http.StatusBadRequest, "remote write URL must be an absolute http or https URL", err)
+	}
+
+	cmd := ngmodels.SaveRecordingConfigCommand{
+		OrgID:             c.OrgId,
+		RemoteWriteURL:    body.RemoteWriteURL,
+		BasicAuthUser:     body.BasicAuthUser,
+		BasicAuthPassword: body.BasicAuthPassword,
+	}
+	if err := srv.store.SaveRecordingConfig(&cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to save recording configuration", err)
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "recording configuration saved"})
+}
+
+// RegisterRecordingApiEndpoints registers the endpoints configuring the recording rules of the organization,
+// which require an organization admin.
+func (api *API) RegisterRecordingApiEndpoints(srv RecordingSrv, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/recording/config"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/recording/config",
+				srv.RouteGetRecordingConfig,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/recording/config"),
+			binding.Bind(apimodels.PostableRecordingConfig{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/recording/config",
+				srv.RoutePostRecordingConfig,
+				m,
+			),
+		)
+	}, middleware.ReqOrgAdmin)
+}
@@ -93,8 +93,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 		if !ok {
 			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 			ruleGroupConfigs[r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
+				Name:       r.RuleGroup,
+				Interval:   ruleGroupInterval,
+				Paused:     r.RuleGroupPaused,
+				Sequential: r.RuleGroupSequential,
 				Rules: []apimodels.GettableExtendedRuleNode{
 					toGettableExtendedRuleNode(*r, namespace.Id),
 				},
@@ -130,17 +132,22 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	}
 
 	var ruleGroupInterval model.Duration
+	var ruleGroupPaused, ruleGroupSequential bool
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+		ruleGroupPaused = r.RuleGroupPaused
+		ruleGroupSequential = r.RuleGroupSequential
 		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
 		GettableRuleGroupConfig: apimodels.GettableRuleGroupConfig{
-			Name:     ruleGroup,
-			Interval: ruleGroupInterval,
-			Rules:    ruleNodes,
+			Name:       ruleGroup,
+			Interval:   ruleGroupInterval,
+			Paused:     ruleGroupPaused,
+			Sequential: ruleGroupSequential,
+			Rules:      ruleNodes,
 		},
 	}
 	return response.JSON(http.StatusAccepted, result)
@@ -171,8 +178,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 			configs[namespace] = make(map[string]apimodels.GettableRuleGroupConfig)
 			configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
+				Name:       r.RuleGroup,
+				Interval:   ruleGroupInterval,
+				Paused:     r.RuleGroupPaused,
+				Sequential: r.RuleGroupSequential,
 				Rules: []apimodels.GettableExtendedRuleNode{
 					toGettableExtendedRuleNode(*r, folder.Id),
 				},
@@ -182,8 +191,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			if !ok {
 				ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 				configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-					Name:     r.RuleGroup,
-					Interval: ruleGroupInterval,
+					Name:       r.RuleGroup,
+					Interval:   ruleGroupInterval,
+					Paused:     r.RuleGroupPaused,
+					Sequential: r.RuleGroupSequential,
 					Rules: []apimodels.GettableExtendedRuleNode{
 						toGettableExtendedRuleNode(*r, folder.Id),
 					},
@@ -230,6 +241,10 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return response.Error(http.StatusBadRequest, "rule group name is not valid", nil)
 	}
 
+	if err := validateRuleStateQueries(ruleGroupConfig); err != nil {
+		return response.Error(http.StatusBadRequest, "failed to validate rule group", err)
+	}
+
 	var alertRuleUIDs []string
 	for _, r := range ruleGroupConfig.Rules {
 		cond := ngmodels.Condition{
@@ -237,9 +252,20 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 			OrgID:     c.SignedInUser.OrgId,
 			Data:      r.GrafanaManagedAlert.Data,
 		}
+		if r.GrafanaManagedAlert.Record != nil {
+			// recording rules write the result of a query or expression, that must exist like a condition
+			cond.Condition = r.GrafanaManagedAlert.Record.From
+		}
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
 			return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate alert rule %s", r.GrafanaManagedAlert.Title), err)
 		}
+		if err := validateTemplates(r.ApiRuleNode); err != nil {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate alert rule %s", r.GrafanaManagedAlert.Title), err)
+		}
+		if ruleGroupConfig.Paused || r.GrafanaManagedAlert.IsPaused {
+			// the scheduler resolves the states of paused rules
+			continue
+		}
 		alertRuleUIDs = append(alertRuleUIDs, r.GrafanaManagedAlert.UID)
 	}
 
@@ -280,16 +306,65 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			Record:          r.Record,
+			IsPaused:        r.IsPaused,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
-		For:         model.Duration(r.For),
-		Annotations: r.Annotations,
-		Labels:      r.Labels,
+		For:           model.Duration(r.For),
+		KeepFiringFor: model.Duration(r.KeepFiringFor),
+		Annotations:   r.Annotations,
+		Labels:        r.Labels,
 	}
 	return gettableExtendedRuleNode
 }
 
+// validateTemplates validates the templates of the labels and annotations of a rule.
+func validateTemplates(node *apimodels.ApiRuleNode) error {
+	if node == nil {
+		return nil
+	}
+	for name, text := range node.Labels {
+		if err := state.ValidateTemplate(name, text); err != nil {
+			return fmt.Errorf("invalid template of label %s: %w", name, err)
+		}
+	}
+	for name, text := range node.Annotations {
+		if err := state.ValidateTemplate(name, text); err != nil {
+			return fmt.Errorf("invalid template of annotation %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateRuleStateQueries validates that the rules of a rule group only query the state of the rules evaluated
+// before them, which requires the rule group to be sequential.
+func validateRuleStateQueries(ruleGroupConfig apimodels.PostableRuleGroupConfig) error {
+	evaluated := make(map[string]struct{}, len(ruleGroupConfig.Rules))
+	for _, r := range ruleGroupConfig.Rules {
+		if r.GrafanaManagedAlert == nil {
+			continue
+		}
+		for _, q := range r.GrafanaManagedAlert.Data {
+			if !q.IsRuleState() {
+				continue
+			}
+			if !ruleGroupConfig.Sequential {
+				return fmt.Errorf("invalid query %s of alert rule %s: only the rules of sequential rule groups can query the state of other rules", q.RefID, r.GrafanaManagedAlert.Title)
+			}
+			title, err := q.GetRuleStateTitle()
+			if err != nil {
+				return err
+			}
+			if _, ok := evaluated[title]; !ok {
+				return fmt.Errorf("invalid query %s of alert rule %s: rule %s is not evaluated before it in the rule group", q.RefID, r.GrafanaManagedAlert.Title, title)
+			}
+		}
+		evaluated[r.GrafanaManagedAlert.Title] = struct{}{}
+	}
+	return nil
+}
+
 func toNamespaceErrorResponse(err error) response.Response {
 	if errors.Is(err, ngmodels.ErrCannotEditNamespace) {
 		return response.Error(http.StatusForbidden, err.Error(), err)
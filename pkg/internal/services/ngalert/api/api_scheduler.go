@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/internal/api/response"
+	"github.com/grafana/grafana/pkg/internal/api/routing"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/middleware"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/schedule"
+)
+
+type SchedulerSrv struct {
+	schedule schedule.ScheduleService
+	log      log.Logger
+}
+
+func (srv SchedulerSrv) RouteGetSchedulerStatus(c *models.ReqContext) response.Response {
+	status, err := srv.schedule.Status()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get scheduler status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// RegisterSchedulerApiEndpoints registers the endpoints of the scheduler, which cover all organizations and
+// require a Grafana admin.
+func (api *API) RegisterSchedulerApiEndpoints(srv SchedulerSrv, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/scheduler/status"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/scheduler/status",
+				srv.RouteGetSchedulerStatus,
+				m,
+			),
+		)
+	}, middleware.ReqGrafanaAdmin)
+}
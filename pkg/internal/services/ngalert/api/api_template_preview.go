@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/internal/api/response"
+	"github.com/grafana/grafana/pkg/internal/api/routing"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/middleware"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+)
+
+type TemplatePreviewSrv struct {
+	am  Alertmanager
+	log log.Logger
+}
+
+func (srv TemplatePreviewSrv) RoutePostTemplatePreview(c *models.ReqContext, body apimodels.PostableTemplatePreview) response.Response {
+	results, err := srv.am.PreviewTemplates(&body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to preview templates", err)
+	}
+	status := http.StatusOK
+	if len(results.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+	return response.JSON(status, results)
+}
+
+// RegisterTemplatePreviewApiEndpoints registers the endpoint rendering notification templates, which sends no
+// notification.
+func (api *API) RegisterTemplatePreviewApiEndpoints(srv TemplatePreviewSrv, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/templates/preview"),
+			binding.Bind(apimodels.PostableTemplatePreview{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/templates/preview",
+				srv.RoutePostTemplatePreview,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/prometheus/alertmanager/config"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 	"github.com/grafana/grafana/pkg/internal/components/simplejson"
-	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/grafana/grafana/pkg/internal/util"
 )
 
@@ -269,7 +269,7 @@ func (c *PostableUserConfig) ProcessConfig() error {
 		case GrafanaReceiverType:
 			for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
 				for k, v := range gr.SecureSettings {
-					encryptedData, err := util.Encrypt([]byte(v), setting.SecretKey)
+					encryptedData, err := envelope.Encrypt([]byte(v))
 					if err != nil {
 						return fmt.Errorf("failed to encrypt secure settings: %w", err)
 					}
@@ -577,7 +577,7 @@ func (r *PostableGrafanaReceiver) GetDecryptedSecret(key string) (string, error)
 	if err != nil {
 		return "", err
 	}
-	decryptedValue, err := util.Decrypt(decodeValue, setting.SecretKey)
+	decryptedValue, err := envelope.Decrypt(decodeValue)
 	if err != nil {
 		return "", err
 	}
@@ -99,6 +99,11 @@ type PostableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// Paused stops the evaluation of the Grafana managed rules of the group.
+	Paused bool `yaml:"paused,omitempty" json:"paused,omitempty"`
+	// Sequential evaluates the Grafana managed rules of the group one after the other, in order, at the
+	// group interval. The rules of a sequential group can query the state of the rules before them.
+	Sequential bool `yaml:"sequential,omitempty" json:"sequential,omitempty"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -142,9 +147,11 @@ func (c *PostableRuleGroupConfig) validate() error {
 
 // swagger:model
 type GettableRuleGroupConfig struct {
-	Name     string                     `yaml:"name" json:"name"`
-	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name       string                     `yaml:"name" json:"name"`
+	Interval   model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules      []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Paused     bool                       `yaml:"paused,omitempty" json:"paused,omitempty"`
+	Sequential bool                       `yaml:"sequential,omitempty" json:"sequential,omitempty"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -187,12 +194,13 @@ func (c *GettableRuleGroupConfig) validate() error {
 }
 
 type ApiRuleNode struct {
-	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
-	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Expr        string            `yaml:"expr" json:"expr"`
-	For         model.Duration    `yaml:"for,omitempty" json:"for,omitempty"`
-	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
-	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Record        string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Expr          string            `yaml:"expr" json:"expr"`
+	For           model.Duration    `yaml:"for,omitempty" json:"for,omitempty"`
+	KeepFiringFor model.Duration    `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
 type RuleType int
@@ -299,6 +307,10 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record makes the rule a recording rule, which has no condition.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"`
+	// IsPaused stops the evaluation of the rule.
+	IsPaused bool `json:"is_paused,omitempty" yaml:"is_paused,omitempty"`
 }
 
 // swagger:model
@@ -317,4 +329,6 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Record          *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`
+	IsPaused        bool                `json:"is_paused,omitempty" yaml:"is_paused,omitempty"`
 }
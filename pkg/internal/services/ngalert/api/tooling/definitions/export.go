@@ -0,0 +1,31 @@
+package definitions
+
+// swagger:route GET /api/v1/rules/export export RouteGetExportRules
+//
+// Export the Grafana managed rule groups of a folder, or of all the folders of the organization, as YAML or JSON
+//
+//     Produces:
+//     - application/yaml
+//     - application/json
+//
+//     Responses:
+//       200: RulesExport
+//       404: NotFound
+
+// swagger:parameters RouteGetExportRules
+type ExportRulesParams struct {
+	// Namespace is the title of the top level folder, or the UID of the folder, whose rule groups are exported.
+	// All the folders are exported if empty.
+	// in:query
+	Namespace string `json:"namespace"`
+	// Format is the format of the export, yaml or json.
+	// in:query
+	// default: yaml
+	Format string `json:"format"`
+}
+
+// RulesExport are the rule groups by folder UID. Each rule group can be posted as is to the ruler API of the
+// folder, /api/ruler/grafana/api/v1/rules/{Namespace} with the folder UID as Namespace, which replaces the rule
+// group. The rules keep their UIDs: to create the rules rather than update them, the UIDs must be removed.
+// swagger:model
+type RulesExport map[string][]PostableRuleGroupConfig
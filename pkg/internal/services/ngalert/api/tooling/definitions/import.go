@@ -0,0 +1,24 @@
+package definitions
+
+// swagger:route POST /api/v1/rules/import/{Namespace} import RoutePostImportRules
+//
+// Import the rule groups of a Prometheus or Loki rule file into a folder, as Grafana managed rules querying a data source
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
+// swagger:parameters RoutePostImportRules
+type ImportRulesParams struct {
+	// in:path
+	Namespace string
+	// DatasourceUID is the UID of the Prometheus or Loki data source queried by the rules.
+	// in:query
+	DatasourceUID string `json:"datasourceUid"`
+	// Body is the rule file, with the groups of rules in the format of Prometheus.
+	// in:body
+	Body string
+}
@@ -0,0 +1,44 @@
+package definitions
+
+// swagger:route GET /api/v1/recording/config recording RouteGetRecordingConfig
+//
+// Get the endpoint where the recording rules of the organization write their series
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecordingConfig
+
+// swagger:route POST /api/v1/recording/config recording RoutePostRecordingConfig
+//
+// Set the endpoint where the recording rules of the organization write their series
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
+// swagger:parameters RoutePostRecordingConfig
+type RecordingConfigParams struct {
+	// in:body
+	Body PostableRecordingConfig
+}
+
+// swagger:model
+type PostableRecordingConfig struct {
+	// RemoteWriteURL is the Prometheus remote write endpoint, for example http://prometheus:9090/api/v1/write.
+	RemoteWriteURL string `json:"remoteWriteUrl"`
+	BasicAuthUser  string `json:"basicAuthUser,omitempty"`
+	// BasicAuthPassword replaces the stored password when set.
+	BasicAuthPassword *string `json:"basicAuthPassword,omitempty"`
+}
+
+// swagger:model
+type GettableRecordingConfig struct {
+	RemoteWriteURL       string `json:"remoteWriteUrl"`
+	BasicAuthUser        string `json:"basicAuthUser,omitempty"`
+	BasicAuthPasswordSet bool   `json:"basicAuthPasswordSet"`
+}
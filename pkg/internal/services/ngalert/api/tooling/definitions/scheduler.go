@@ -0,0 +1,38 @@
+package definitions
+
+import "time"
+
+// swagger:route Get /api/v1/scheduler/status scheduler RouteGetSchedulerStatus
+//
+// Get the Grafana instances evaluating alert rules, and the instance owning each rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: SchedulerStatus
+
+// swagger:model
+type SchedulerStatus struct {
+	// InstanceID is the identifier of the instance answering the request.
+	InstanceID string `json:"instanceId"`
+	// Sharding is true when the alert rules are partitioned between the instances.
+	Sharding  bool                      `json:"sharding"`
+	Instances []SchedulerInstanceStatus `json:"instances"`
+	Rules     []SchedulerRuleStatus     `json:"rules"`
+}
+
+// swagger:model
+type SchedulerInstanceStatus struct {
+	InstanceID    string    `json:"instanceId"`
+	LastHeartbeat time.Time `json:"lastHeartbeat"`
+}
+
+// swagger:model
+type SchedulerRuleStatus struct {
+	OrgID int64  `json:"orgId"`
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// Owner is the identifier of the instance evaluating the rule.
+	Owner string `json:"owner"`
+}
@@ -0,0 +1,65 @@
+package definitions
+
+// swagger:route POST /api/v1/templates/preview templates RoutePostTemplatePreview
+//
+// Render the notifications of Grafana receivers for alerts with notification templates, without sending them
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: TemplatePreviewResults
+//       400: TemplatePreviewResults
+
+// swagger:parameters RoutePostTemplatePreview
+type TemplatePreviewParams struct {
+	// in:body
+	Body PostableTemplatePreview
+}
+
+// swagger:model
+type PostableTemplatePreview struct {
+	// TemplateFiles are the template files by file name, like in the Alertmanager configuration. The default
+	// template, defining default.title and default.message, is always available.
+	TemplateFiles map[string]string `json:"template_files"`
+	// Receivers are the Grafana receivers whose notifications are rendered, with the templates of their settings.
+	// The notifications of every supported receiver type with the default settings are rendered if empty.
+	Receivers []*PostableGrafanaReceiver `json:"receivers,omitempty"`
+	// Alerts are the alerts of the notification. A firing test alert is used if empty.
+	Alerts []TemplatePreviewAlert `json:"alerts,omitempty"`
+	// GroupLabels are the labels the alerts of the notification are grouped by.
+	GroupLabels map[string]string `json:"group_labels,omitempty"`
+}
+
+type TemplatePreviewAlert struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Value is the evaluation string of the alert rule, sent in the __value__ label.
+	Value        string `json:"value,omitempty"`
+	Resolved     bool   `json:"resolved,omitempty"`
+	GeneratorURL string `json:"generatorURL,omitempty"`
+}
+
+// swagger:model
+type TemplatePreviewResults struct {
+	// Notifications are the rendered notifications, by receiver.
+	Notifications []TemplatePreviewNotification `json:"notifications,omitempty"`
+	// Errors are the errors of the template files failing to parse, in which case no notification is rendered.
+	Errors []TemplatePreviewError `json:"errors,omitempty"`
+}
+
+type TemplatePreviewNotification struct {
+	Receiver string `json:"receiver"`
+	Type     string `json:"type"`
+	Title    string `json:"title,omitempty"`
+	Message  string `json:"message,omitempty"`
+	// Error is the error of the template of the title or message failing to render.
+	Error *TemplatePreviewError `json:"error,omitempty"`
+}
+
+type TemplatePreviewError struct {
+	// File is the name of the template file, or empty for a template of the receiver settings.
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
@@ -193,7 +193,8 @@ func validateQueriesAndExpressions(data []ngmodels.AlertQuery, user *models.Sign
 		if err != nil {
 			return nil, err
 		}
-		if isExpression {
+		// the rule state queries are validated with the rule group
+		if isExpression || query.IsRuleState() {
 			refIDs[query.RefID] = struct{}{}
 			continue
 		}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
@@ -24,6 +25,14 @@ type Evaluator struct {
 	Cfg *setting.Cfg
 }
 
+// Timeout returns the time after which the evaluations are cancelled.
+func (e *Evaluator) Timeout() time.Duration {
+	if e.Cfg.UnifiedAlerting.EvaluationTimeout <= 0 {
+		return alertingEvaluationTimeout
+	}
+	return e.Cfg.UnifiedAlerting.EvaluationTimeout
+}
+
 // invalidEvalResultFormatError is an error for invalid format of the alert definition evaluation results.
 type invalidEvalResultFormatError struct {
 	refID  string
@@ -49,6 +58,9 @@ type ExecutionResults struct {
 	Error error
 
 	Results data.Frames
+
+	// NumberValues are the values of the queries and expressions returning reduced numbers.
+	NumberValues []NumberValueCapture
 }
 
 // Results is a slice of evaluated alert instances states.
@@ -68,6 +80,23 @@ type Result struct {
 	// as EvalMatches (from "classic condition"), and in the future from operations
 	// like SSE "math".
 	EvaluationString string
+
+	// Values are the values of the queries and expressions for the series of the instance, by RefID.
+	Values map[string]NumberValueCapture
+}
+
+// NumberValueCapture is the value of a series returned by a query or expression returning reduced numbers.
+type NumberValueCapture struct {
+	Var    string // RefID
+	Labels data.Labels
+	Value  float64
+}
+
+func (v NumberValueCapture) String() string {
+	if v.Var == "" {
+		return "[no value]"
+	}
+	return strconv.FormatFloat(v.Value, 'f', -1, 64)
 }
 
 // State is an enum of the evaluation State for an alert instance.
@@ -156,6 +185,7 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	}
 
 	for refID, res := range execResp.Responses {
+		result.NumberValues = append(result.NumberValues, numberValues(refID, res.Frames)...)
 		if refID != c.Condition {
 			continue
 		}
@@ -165,6 +195,85 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	return result
 }
 
+// numberValues returns the values of the frames holding a single number, which are the results of
+// reduced queries and expressions.
+func numberValues(refID string, frames data.Frames) []NumberValueCapture {
+	var values []NumberValueCapture
+	for _, f := range frames {
+		if len(f.Fields) != 1 || f.Fields[0].Len() != 1 {
+			continue
+		}
+		field := f.Fields[0]
+		var value float64
+		switch field.Type() {
+		case data.FieldTypeNullableFloat64:
+			v := field.At(0).(*float64)
+			if v == nil {
+				continue
+			}
+			value = *v
+		case data.FieldTypeFloat64:
+			value = field.At(0).(float64)
+		default:
+			continue
+		}
+		values = append(values, NumberValueCapture{Var: refID, Labels: field.Labels, Value: value})
+	}
+	return values
+}
+
+// valuesFor returns the values of each query and expression for the series of an alert instance: the value
+// of the series with the same labels, else of the single series with labels included in the labels of the
+// instance, else the value of the query or expression if it returns a single series.
+func (execResults ExecutionResults) valuesFor(instance data.Labels) map[string]NumberValueCapture {
+	byRefID := make(map[string][]NumberValueCapture)
+	for _, v := range execResults.NumberValues {
+		byRefID[v.Var] = append(byRefID[v.Var], v)
+	}
+
+	var values map[string]NumberValueCapture
+	for refID, captures := range byRefID {
+		match := -1
+		included := -1
+		for i, v := range captures {
+			if v.Labels.String() == instance.String() {
+				match = i
+				break
+			}
+			if labelsIncluded(v.Labels, instance) {
+				if included == -1 {
+					included = i
+				} else {
+					included = -2
+				}
+			}
+		}
+		switch {
+		case match >= 0:
+		case included >= 0:
+			match = included
+		case len(captures) == 1:
+			match = 0
+		default:
+			continue
+		}
+		if values == nil {
+			values = make(map[string]NumberValueCapture, len(byRefID))
+		}
+		values[refID] = captures[match]
+	}
+	return values
+}
+
+func labelsIncluded(labels, in data.Labels) bool {
+	for k, v := range labels {
+		if in[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
 	queryDataReq, err := GetExprRequest(ctx, data, now)
 	if err != nil {
@@ -213,6 +322,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			Instance:           l,
 			EvaluatedAt:        ts,
 			EvaluationDuration: time.Since(ts),
+			Values:             execResults.valuesFor(l),
 		})
 	}
 
@@ -271,6 +381,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			EvaluatedAt:        ts,
 			EvaluationDuration: time.Since(ts),
 			EvaluationString:   extractEvalString(f),
+			Values:             execResults.valuesFor(f.Fields[0].Labels),
 		}
 
 		switch {
@@ -343,7 +454,7 @@ func (evalResults Results) AsDataFrame() data.Frame {
 
 // ConditionEval executes conditions and evaluates the result.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Timeout())
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled}
@@ -356,7 +467,7 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Timeout())
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled}
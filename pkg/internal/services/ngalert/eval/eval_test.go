@@ -294,3 +294,51 @@ func TestEvaluateExecutionResult(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberValues(t *testing.T) {
+	cpu := func(instance string, v float64) *data.Frame {
+		return data.NewFrame("", data.NewField("", data.Labels{"instance": instance}, []*float64{ptr.Float64(v)}))
+	}
+	frames := data.Frames{
+		cpu("a", 0.5),
+		cpu("b", 0.9),
+		data.NewFrame("", data.NewField("", data.Labels{"instance": "c"}, []*float64{nil})),
+		data.NewFrame("", data.NewField("time", nil, []time.Time{{}, {}})),
+	}
+	execResults := ExecutionResults{
+		Results: frames,
+		NumberValues: append(append(numberValues("B", frames),
+			numberValues("C", data.Frames{data.NewFrame("", data.NewField("", nil, []float64{80}))})...),
+			numberValues("D", data.Frames{data.NewFrame("", data.NewField("", data.Labels{"instance": "a", "job": "node"}, []float64{1}))})...),
+	}
+	require.Len(t, execResults.NumberValues, 4, "the null values and the series are not captured")
+
+	t.Run("values are matched by labels", func(t *testing.T) {
+		values := execResults.valuesFor(data.Labels{"instance": "b"})
+		require.Equal(t, NumberValueCapture{Var: "B", Labels: data.Labels{"instance": "b"}, Value: 0.9}, values["B"])
+		require.Equal(t, "0.9", values["B"].String())
+	})
+
+	t.Run("single values are matched to every instance", func(t *testing.T) {
+		values := execResults.valuesFor(data.Labels{"instance": "b"})
+		require.Equal(t, "80", values["C"].String())
+		require.Equal(t, "1", values["D"].String())
+	})
+
+	t.Run("values with labels included in the labels of the instance are matched", func(t *testing.T) {
+		values := execResults.valuesFor(data.Labels{"instance": "a", "job": "node", "env": "prod"})
+		require.Equal(t, 0.5, values["B"].Value)
+	})
+
+	t.Run("missing values", func(t *testing.T) {
+		values := execResults.valuesFor(data.Labels{"instance": "c"})
+		require.NotContains(t, values, "B")
+		require.Equal(t, "[no value]", values["B"].String())
+	})
+
+	t.Run("evaluation results have the values of their instance", func(t *testing.T) {
+		results := evaluateExecutionResult(execResults, time.Time{})
+		require.Equal(t, 0.5, results[0].Values["B"].Value)
+		require.Equal(t, 0.9, results[1].Values["B"].Value)
+	})
+}
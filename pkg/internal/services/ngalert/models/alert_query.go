@@ -1,17 +1,25 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"gopkg.in/yaml.v3"
+
 	"github.com/grafana/grafana/pkg/internal/expr"
 )
 
 const defaultMaxDataPoints float64 = 43200 // 12 hours at 1sec interval
 const defaultIntervalMS float64 = 1000
 
+// RuleStateDatasourceUID is the data source UID of the queries returning the state of another rule of a
+// sequential rule group. The query model names the rule by title, e.g. {"rule": "Service down"}, and the
+// query returns the number of firing alerts of that rule.
+const RuleStateDatasourceUID = "__rule_state__"
+
 // Duration is a type used for marshalling durations.
 type Duration time.Duration
 
@@ -37,11 +45,25 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
+// MarshalYAML marshals the duration in seconds, like in JSON.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).Seconds(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var v float64
+	if err := value.Decode(&v); err != nil {
+		return fmt.Errorf("invalid duration %s", value.Value)
+	}
+	*d = Duration(time.Duration(v) * time.Second)
+	return nil
+}
+
 // RelativeTimeRange is the per query start and end time
 // for requests.
 type RelativeTimeRange struct {
-	From Duration `json:"from"`
-	To   Duration `json:"to"`
+	From Duration `json:"from" yaml:"from"`
+	To   Duration `json:"to" yaml:"to"`
 }
 
 // isValid checks that From duration is greater than To duration.
@@ -77,6 +99,52 @@ type AlertQuery struct {
 	modelProps map[string]interface{}
 }
 
+// alertQueryYAML is an alert query in YAML, where the model is a mapping rather than raw JSON.
+type alertQueryYAML struct {
+	RefID             string                 `yaml:"refId"`
+	QueryType         string                 `yaml:"queryType,omitempty"`
+	RelativeTimeRange RelativeTimeRange      `yaml:"relativeTimeRange"`
+	DatasourceUID     string                 `yaml:"datasourceUid"`
+	Model             map[string]interface{} `yaml:"model"`
+}
+
+// MarshalYAML marshals the alert query with the same fields as in JSON.
+func (aq AlertQuery) MarshalYAML() (interface{}, error) {
+	var model map[string]interface{}
+	if err := json.Unmarshal(aq.Model, &model); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	return alertQueryYAML{
+		RefID:             aq.RefID,
+		QueryType:         aq.QueryType,
+		RelativeTimeRange: aq.RelativeTimeRange,
+		DatasourceUID:     aq.DatasourceUID,
+		Model:             model,
+	}, nil
+}
+
+func (aq *AlertQuery) UnmarshalYAML(value *yaml.Node) error {
+	var v alertQueryYAML
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	// the model is not escaped for HTML, like the models posted in JSON
+	var model bytes.Buffer
+	encoder := json.NewEncoder(&model)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v.Model); err != nil {
+		return fmt.Errorf("failed to marshal query model: %w", err)
+	}
+	*aq = AlertQuery{
+		RefID:             v.RefID,
+		QueryType:         v.QueryType,
+		RelativeTimeRange: v.RelativeTimeRange,
+		DatasourceUID:     v.DatasourceUID,
+		Model:             bytes.TrimSpace(model.Bytes()),
+	}
+	return nil
+}
+
 func (aq *AlertQuery) setModelProps() error {
 	aq.modelProps = make(map[string]interface{})
 	err := json.Unmarshal(aq.Model, &aq.modelProps)
@@ -92,6 +160,26 @@ func (aq *AlertQuery) IsExpression() (bool, error) {
 	return aq.DatasourceUID == expr.DatasourceUID, nil
 }
 
+// IsRuleState returns true if the alert query returns the state of another rule of the rule group.
+func (aq *AlertQuery) IsRuleState() bool {
+	return aq.DatasourceUID == RuleStateDatasourceUID
+}
+
+// GetRuleStateTitle returns the title of the rule whose state is returned by a rule state query.
+func (aq *AlertQuery) GetRuleStateTitle() (string, error) {
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return "", err
+		}
+	}
+	title, ok := aq.modelProps["rule"].(string)
+	if !ok || title == "" {
+		return "", fmt.Errorf("rule state query %s does not name a rule", aq.RefID)
+	}
+	return title, nil
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -232,7 +320,7 @@ func (aq *AlertQuery) PreSave() error {
 		return err
 	}
 
-	if ok := isExpression || aq.RelativeTimeRange.isValid(); !ok {
+	if ok := isExpression || aq.IsRuleState() || aq.RelativeTimeRange.isValid(); !ok {
 		return fmt.Errorf("invalid relative time range: %+v", aq.RelativeTimeRange)
 	}
 	return nil
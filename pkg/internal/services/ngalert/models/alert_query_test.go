@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestAlertQuery(t *testing.T) {
@@ -245,3 +246,33 @@ func TestAlertQueryMarshalling(t *testing.T) {
 		}
 	}
 }
+
+func TestAlertQueryYAMLMarshalling(t *testing.T) {
+	aq := AlertQuery{
+		RefID:             "A",
+		RelativeTimeRange: RelativeTimeRange{From: Duration(5 * time.Minute)},
+		DatasourceUID:     "prom",
+		Model:             json.RawMessage(`{"expr":"up","refId":"A","targets":[{"interval":"1m"}]}`),
+	}
+
+	b, err := yaml.Marshal(aq)
+	require.NoError(t, err)
+	assert.Equal(t, `refId: A
+relativeTimeRange:
+    from: 300
+    to: 0
+datasourceUid: prom
+model:
+    expr: up
+    refId: A
+    targets:
+        - interval: 1m
+`, string(b))
+
+	var unmarshalled AlertQuery
+	require.NoError(t, yaml.Unmarshal(b, &unmarshalled))
+	assert.Equal(t, aq, unmarshalled)
+
+	err = yaml.Unmarshal([]byte("refId: A\nrelativeTimeRange: {from: 5h10m}"), &unmarshalled)
+	require.EqualError(t, err, "invalid duration 5h10m")
+}
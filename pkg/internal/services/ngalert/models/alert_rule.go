@@ -66,6 +66,36 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// KeepFiringFor keeps the alerts firing until the condition has not been met for that long.
+	KeepFiringFor time.Duration
+	// Record is set for recording rules, which write the result of their queries and expressions instead of
+	// alerting on a condition.
+	Record *Record `xorm:"json"`
+	// IsPaused stops the evaluation of the rule. RuleGroupPaused is set on all the rules of a paused rule group.
+	IsPaused        bool
+	RuleGroupPaused bool
+	// RuleGroupIndex is the position of the rule in its rule group. The rules of a sequential rule group are
+	// evaluated one after the other in that order.
+	RuleGroupIndex      int
+	RuleGroupSequential bool
+}
+
+// Record defines the series written by a recording rule.
+type Record struct {
+	// Metric is the name of the written series.
+	Metric string `json:"metric" yaml:"metric"`
+	// From is the refID of the query or expression whose result is written.
+	From string `json:"from" yaml:"from"`
+}
+
+// Paused returns true if the alert rule or its rule group is paused.
+func (alertRule *AlertRule) Paused() bool {
+	return alertRule.IsPaused || alertRule.RuleGroupPaused
+}
+
+// IsRecording returns true if the alert rule is a recording rule.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != nil
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -116,9 +146,17 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For           time.Duration
+	KeepFiringFor time.Duration
+	Annotations   map[string]string
+	Labels        map[string]string
+	Record        *Record `xorm:"json"`
+
+	IsPaused        bool
+	RuleGroupPaused bool
+
+	RuleGroupIndex      int
+	RuleGroupSequential bool
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -15,6 +15,13 @@ type AlertInstance struct {
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
+	// KeepFiringSince is the first evaluation not meeting the condition of an alert kept firing.
+	KeepFiringSince time.Time
+	// Flapping is set on the alerts changing state often, at the times listed in StateChanges.
+	Flapping     bool
+	StateChanges []time.Time `xorm:"json"`
+	// Annotations are the annotations of the alert rule as expanded by the last evaluation.
+	Annotations map[string]string `xorm:"json"`
 }
 
 // InstanceStateType is an enum for instance states.
@@ -52,6 +59,10 @@ type SaveAlertInstanceCommand struct {
 	LastEvalTime      time.Time
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
+	KeepFiringSince   time.Time
+	Flapping          bool
+	StateChanges      []time.Time
+	Annotations       map[string]string
 }
 
 // GetAlertInstanceQuery is the query for retrieving/deleting an alert definition by ID.
@@ -83,6 +94,10 @@ type ListAlertInstancesQueryResult struct {
 	CurrentStateSince time.Time         `json:"currentStateSince"`
 	CurrentStateEnd   time.Time         `json:"currentStateEnd"`
 	LastEvalTime      time.Time         `json:"lastEvalTime"`
+	KeepFiringSince   time.Time         `json:"keepFiringSince"`
+	Flapping          bool              `json:"flapping"`
+	StateChanges      []time.Time       `xorm:"json" json:"stateChanges"`
+	Annotations       map[string]string `xorm:"json" json:"annotations"`
 }
 
 // ValidateAlertInstance validates that the alert instance contains an alert rule id,
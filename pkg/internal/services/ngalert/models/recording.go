@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/components/securejsondata"
+)
+
+// RecordingConfig is the configuration of the endpoint where the recording rules of an organisation
+// write their series.
+type RecordingConfig struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	RemoteWriteURL string `xorm:"remote_write_url"`
+	BasicAuthUser  string
+	SecureSettings securejsondata.SecureJsonData
+	Updated        time.Time
+}
+
+func (c RecordingConfig) TableName() string {
+	return "alert_recording_config"
+}
+
+// BasicAuthPassword returns the decrypted basic authentication password, if any.
+func (c *RecordingConfig) BasicAuthPassword() string {
+	password, _ := c.SecureSettings.DecryptedValue("basicAuthPassword")
+	return password
+}
+
+// GetRecordingConfigQuery is the query for retrieving the recording configuration of an organisation.
+type GetRecordingConfigQuery struct {
+	OrgID int64
+
+	Result *RecordingConfig
+}
+
+// SaveRecordingConfigCommand is the command for saving the recording configuration of an organisation.
+// The existing password is kept if BasicAuthPassword is nil.
+type SaveRecordingConfigCommand struct {
+	OrgID             int64
+	RemoteWriteURL    string
+	BasicAuthUser     string
+	BasicAuthPassword *string
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// SchedulerInstance is a Grafana instance evaluating a share of the alert rules.
+type SchedulerInstance struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	InstanceID    string `xorm:"instance_id"`
+	LastHeartbeat int64
+}
+
+// TableName returns the name of the table of the scheduler instances.
+func (i SchedulerInstance) TableName() string {
+	return "alert_scheduler_instance"
+}
+
+// SaveSchedulerHeartbeatCommand records that the scheduler instance is alive at Time.
+type SaveSchedulerHeartbeatCommand struct {
+	InstanceID string
+	Time       time.Time
+}
+
+// ListSchedulerInstancesQuery lists the scheduler instances whose last heartbeat is not before Since,
+// ordered by instance ID.
+type ListSchedulerInstancesQuery struct {
+	Since time.Time
+
+	Result []*SchedulerInstance
+}
+
+// DeleteSchedulerInstancesCommand deletes the scheduler instance InstanceID, and the instances whose last
+// heartbeat is before Before.
+type DeleteSchedulerInstancesCommand struct {
+	InstanceID string
+	Before     time.Time
+}
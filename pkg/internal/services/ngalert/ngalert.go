@@ -22,13 +22,13 @@ import (
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
 	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/grafana/grafana/pkg/internal/tsdb"
 )
 
 const (
-	maxAttempts int64 = 3
 	// scheduler interval
 	// changing this value is discouraged
 	// because this could cause existing alert definition
@@ -62,7 +62,10 @@ func init() {
 // Init initializes the AlertingService.
 func (ng *AlertNG) Init() error {
 	ng.Log = log.New("ngalert")
-	ng.stateManager = state.NewManager(ng.Log, ng.Metrics)
+	ng.stateManager = state.NewManager(ng.Log, ng.Metrics, state.FlapDetection{
+		Window:    ng.Cfg.UnifiedAlerting.FlapDetectionWindow,
+		Threshold: ng.Cfg.UnifiedAlerting.FlapDetectionThreshold,
+	})
 	baseInterval := baseIntervalSeconds * time.Second
 
 	store := &store.DBstore{
@@ -81,12 +84,21 @@ func (ng *AlertNG) Init() error {
 		C:             clock.New(),
 		BaseInterval:  baseInterval,
 		Logger:        ng.Log,
-		MaxAttempts:   maxAttempts,
+		MaxAttempts:   ng.Cfg.UnifiedAlerting.MaxAttempts,
 		Evaluator:     eval.Evaluator{Cfg: ng.Cfg},
 		InstanceStore: store,
 		RuleStore:     store,
 		Notifier:      ng.Alertmanager,
+		Recorder:      writer.NewPrometheusWriter(store, ng.Log.New("writer")),
 		Metrics:       ng.Metrics,
+
+		Sharding:        ng.Cfg.UnifiedAlerting.SchedulerSharding,
+		InstanceID:      ng.Cfg.UnifiedAlerting.InstanceID,
+		InstanceTimeout: ng.Cfg.UnifiedAlerting.SchedulerInstanceTimeout,
+		SchedulerStore:  store,
+
+		RetryBackoff:                          ng.Cfg.UnifiedAlerting.RetryBackoff,
+		MaxConcurrentEvaluationsPerDatasource: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerDatasource,
 	}
 	ng.schedule = schedule.NewScheduler(schedCfg, ng.DataService)
 
@@ -101,6 +113,7 @@ func (ng *AlertNG) Init() error {
 		InstanceStore:   store,
 		RuleStore:       store,
 		AlertingStore:   store,
+		RecordingStore:  store,
 		Alertmanager:    ng.Alertmanager,
 		StateManager:    ng.stateManager,
 	}
@@ -288,7 +288,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	if cfg.TemplateFiles == nil {
 		cfg.TemplateFiles = map[string]string{}
 	}
-	cfg.TemplateFiles["__default__.tmpl"] = channels.DefaultTemplateString
+	cfg.TemplateFiles[defaultTemplateFile] = channels.DefaultTemplateString
 
 	// next, we need to make sure we persist the templates to disk.
 	paths, templatesChanged, err := PersistTemplates(cfg, am.WorkingDirPath())
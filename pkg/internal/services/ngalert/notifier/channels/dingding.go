@@ -45,12 +45,17 @@ func NewDingDingNotifier(model *NotificationChannelConfig, t *template.Template)
 		}),
 		MsgType: msgType,
 		URL:     url,
-		Message: model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		Message: dingdingTemplates.message.get(model.Settings),
 		log:     log.New("alerting.notifier.dingding"),
 		tmpl:    t,
 	}, nil
 }
 
+var dingdingTemplates = notificationTemplates{
+	title:   notificationTemplate{text: defaultTitle},
+	message: notificationTemplate{setting: "message", text: defaultMessage},
+}
+
 // DingDingNotifier is responsible for sending alert notifications to ding ding.
 type DingDingNotifier struct {
 	old_notifiers.NotifierBase
@@ -79,7 +84,7 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 	tmpl := notify.TmplText(dd.tmpl, data, &tmplErr)
 
 	message := tmpl(dd.Message)
-	title := tmpl(`{{ template "default.title" . }}`)
+	title := tmpl(dingdingTemplates.title.text)
 
 	var bodyMsg map[string]interface{}
 	if dd.MsgType == "actionCard" {
@@ -30,6 +30,11 @@ type EmailNotifier struct {
 	tmpl        *template.Template
 }
 
+var emailTemplates = notificationTemplates{
+	title:   notificationTemplate{text: defaultTitle},
+	message: notificationTemplate{setting: "message"},
+}
+
 // NewEmailNotifier is the constructor function
 // for the EmailNotifier.
 func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*EmailNotifier, error) {
@@ -57,7 +62,7 @@ func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		}),
 		Addresses:   addresses,
 		SingleEmail: singleEmail,
-		Message:     model.Settings.Get("message").MustString(),
+		Message:     emailTemplates.message.get(model.Settings),
 		log:         log.New("alerting.notifier.email"),
 		tmpl:        t,
 	}, nil
@@ -70,7 +75,7 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	var tmplErr error
 	tmpl := notify.TmplText(en.tmpl, data, &tmplErr)
 
-	title := tmpl(`{{ template "default.title" . }}`)
+	title := tmpl(emailTemplates.title.text)
 
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
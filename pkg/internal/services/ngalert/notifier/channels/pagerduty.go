@@ -44,6 +44,11 @@ type PagerdutyNotifier struct {
 	log           log.Logger
 }
 
+var pagerdutyTemplates = notificationTemplates{
+	title:   notificationTemplate{setting: "summary", text: defaultTitle},
+	message: notificationTemplate{text: defaultTitle},
+}
+
 // NewPagerdutyNotifier is the constructor for the PagerDuty notifier
 func NewPagerdutyNotifier(model *NotificationChannelConfig, t *template.Template) (*PagerdutyNotifier, error) {
 	if model.Settings == nil {
@@ -74,7 +79,7 @@ func NewPagerdutyNotifier(model *NotificationChannelConfig, t *template.Template
 		Class:     model.Settings.Get("class").MustString("default"),
 		Component: model.Settings.Get("component").MustString("Grafana"),
 		Group:     model.Settings.Get("group").MustString("default"),
-		Summary:   model.Settings.Get("summary").MustString(`{{ template "default.title" . }}`),
+		Summary:   pagerdutyTemplates.title.get(model.Settings),
 		tmpl:      t,
 		log:       log.New("alerting.notifier." + model.Name),
 	}, nil
@@ -148,7 +153,7 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 			HRef: pn.tmpl.ExternalURL.String(),
 			Text: "External URL",
 		}},
-		Description: tmpl(`{{ template "default.title" . }}`), // TODO: this can be configurable template.
+		Description: tmpl(pagerdutyTemplates.message.text), // TODO: this can be configurable template.
 		Payload: &pagerDutyPayload{
 			Component:     tmpl(pn.Component),
 			Summary:       tmpl(pn.Summary),
@@ -0,0 +1,55 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+)
+
+// previewTemplates are the templates of the notifier types whose notifications can be previewed.
+var previewTemplates = map[string]notificationTemplates{
+	"dingding":  dingdingTemplates,
+	"email":     emailTemplates,
+	"pagerduty": pagerdutyTemplates,
+	"sensugo":   sensugoTemplates,
+	"slack":     slackTemplates,
+	"teams":     teamsTemplates,
+	"telegram":  telegramTemplates,
+	"webhook":   webhookTemplates,
+}
+
+// PreviewTypes returns the notifier types whose notifications can be previewed.
+func PreviewTypes() []string {
+	types := make([]string, 0, len(previewTemplates))
+	for t := range previewTemplates {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// PreviewNotification renders the title and message of the notification of a notifier for alerts, without sending
+// it. The receiver name and the group labels of the notification are read from the context.
+func PreviewNotification(ctx context.Context, tmpl *template.Template, notifierType string, settings *simplejson.Json, as []*types.Alert) (title string, message string, err error) {
+	templates, ok := previewTemplates[notifierType]
+	if !ok {
+		return "", "", fmt.Errorf("notifier %s is not supported", notifierType)
+	}
+
+	data := notify.GetTemplateData(ctx, tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	render := notify.TmplText(tmpl, data, &tmplErr)
+	title = render(templates.title.get(settings))
+	message = render(templates.message.get(settings))
+	if tmplErr != nil {
+		return "", "", tmplErr
+	}
+	return title, message, nil
+}
@@ -35,6 +35,10 @@ type SensuGoNotifier struct {
 	Message   string
 }
 
+var sensugoTemplates = notificationTemplates{
+	message: notificationTemplate{setting: "message", text: defaultMessage},
+}
+
 // NewSensuGoNotifier is the constructor for the SensuGo notifier
 func NewSensuGoNotifier(model *NotificationChannelConfig, t *template.Template) (*SensuGoNotifier, error) {
 	if model.Settings == nil {
@@ -66,7 +70,7 @@ func NewSensuGoNotifier(model *NotificationChannelConfig, t *template.Template)
 		Namespace: model.Settings.Get("namespace").MustString(),
 		Handler:   model.Settings.Get("handler").MustString(),
 		APIKey:    apikey,
-		Message:   model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		Message:   sensugoTemplates.message.get(model.Settings),
 		log:       log.New("alerting.notifier.sensugo"),
 		tmpl:      t,
 	}, nil
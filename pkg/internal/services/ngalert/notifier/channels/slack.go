@@ -53,6 +53,11 @@ var reRecipient *regexp.Regexp = regexp.MustCompile("^((@[a-z0-9][a-zA-Z0-9._-]*
 
 var SlackAPIEndpoint = "https://slack.com/api/chat.postMessage"
 
+var slackTemplates = notificationTemplates{
+	title:   notificationTemplate{setting: "title", text: defaultTitle},
+	message: notificationTemplate{setting: "text", text: defaultMessage},
+}
+
 // NewSlackNotifier is the constructor for the Slack notifier
 func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*SlackNotifier, error) {
 	if model.Settings == nil {
@@ -128,8 +133,8 @@ func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		IconEmoji:      model.Settings.Get("icon_emoji").MustString(),
 		IconURL:        model.Settings.Get("icon_url").MustString(),
 		Token:          token,
-		Text:           model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
-		Title:          model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
+		Text:           slackTemplates.message.get(model.Settings),
+		Title:          slackTemplates.title.get(model.Settings),
 		log:            log.New("alerting.notifier.slack"),
 		tmpl:           t,
 	}, nil
@@ -29,6 +29,11 @@ type TeamsNotifier struct {
 	log     log.Logger
 }
 
+var teamsTemplates = notificationTemplates{
+	title:   notificationTemplate{text: defaultTitle},
+	message: notificationTemplate{setting: "message", text: defaultMessage},
+}
+
 // NewTeamsNotifier is the constructor for Teams notifier.
 func NewTeamsNotifier(model *NotificationChannelConfig, t *template.Template) (*TeamsNotifier, error) {
 	if model.Settings == nil {
@@ -49,7 +54,7 @@ func NewTeamsNotifier(model *NotificationChannelConfig, t *template.Template) (*
 			Settings:              model.Settings,
 		}),
 		URL:     u,
-		Message: model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		Message: teamsTemplates.message.get(model.Settings),
 		log:     log.New("alerting.notifier.teams"),
 		tmpl:    t,
 	}, nil
@@ -61,7 +66,7 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	var tmplErr error
 	tmpl := notify.TmplText(tn.tmpl, data, &tmplErr)
 
-	title := tmpl(`{{ template "default.title" . }}`)
+	title := tmpl(teamsTemplates.title.text)
 	body := map[string]interface{}{
 		"@type":    "MessageCard",
 		"@context": "http://schema.org/extensions",
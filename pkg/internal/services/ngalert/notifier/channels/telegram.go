@@ -34,6 +34,10 @@ type TelegramNotifier struct {
 	tmpl     *template.Template
 }
 
+var telegramTemplates = notificationTemplates{
+	message: notificationTemplate{setting: "message", text: defaultMessage},
+}
+
 // NewTelegramNotifier is the constructor for the Telegram notifier
 func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template) (*TelegramNotifier, error) {
 	if model.Settings == nil {
@@ -42,7 +46,7 @@ func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template)
 
 	botToken := model.DecryptedValue("bottoken", model.Settings.Get("bottoken").MustString())
 	chatID := model.Settings.Get("chatid").MustString()
-	message := model.Settings.Get("message").MustString(`{{ template "default.message" . }}`)
+	message := telegramTemplates.message.get(model.Settings)
 
 	if botToken == "" {
 		return nil, alerting.ValidationError{Reason: "Could not find Bot Token in settings"}
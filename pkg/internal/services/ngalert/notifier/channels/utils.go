@@ -13,6 +13,32 @@ const (
 	ColorAlertResolved = "#36a64f"
 )
 
+const (
+	defaultTitle   = `{{ template "default.title" . }}`
+	defaultMessage = `{{ template "default.message" . }}`
+)
+
+// notificationTemplate is a template of a notification, which can be customised by the setting of the notifier if
+// the setting is not empty.
+type notificationTemplate struct {
+	setting string
+	text    string
+}
+
+// get returns the template customised by the settings of a notifier, if any.
+func (t notificationTemplate) get(settings *simplejson.Json) string {
+	if t.setting == "" || settings == nil {
+		return t.text
+	}
+	return settings.Get(t.setting).MustString(t.text)
+}
+
+// notificationTemplates are the templates of the title and message of the notifications of a notifier type. They
+// are rendered by the notifier when sending notifications, and by PreviewNotification.
+type notificationTemplates struct {
+	title, message notificationTemplate
+}
+
 func getAlertStatusColor(status model.AlertStatus) string {
 	if status == model.AlertFiring {
 		return ColorAlertFiring
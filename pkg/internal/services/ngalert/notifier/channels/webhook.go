@@ -32,6 +32,11 @@ type WebhookNotifier struct {
 	tmpl       *template.Template
 }
 
+var webhookTemplates = notificationTemplates{
+	title:   notificationTemplate{text: defaultTitle},
+	message: notificationTemplate{text: defaultMessage},
+}
+
 // NewWebHookNotifier is the constructor for
 // the WebHook notifier.
 func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template) (*WebhookNotifier, error) {
@@ -90,8 +95,8 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		Data:            data,
 		GroupKey:        groupKey.String(),
 		TruncatedAlerts: numTruncated,
-		Title:           tmpl(`{{ template "default.title" . }}`),
-		Message:         tmpl(`{{ template "default.message" . }}`),
+		Title:           tmpl(webhookTemplates.title.text),
+		Message:         tmpl(webhookTemplates.message.text),
 	}
 
 	if types.Alerts(as...).Status() == model.AlertFiring {
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/notifier/channels"
+)
+
+const defaultTemplateFile = "__default__.tmpl"
+
+// templateErrorRegex matches the errors of text/template, such as template: slack.tmpl:3: unexpected "}" in operand
+// or template: slack.tmpl:3:5: executing "slack.title" at <.Foo>: can't evaluate field Foo.
+var templateErrorRegex = regexp.MustCompile(`^template: ([^:]*):(\d+):(?:\d+:)? ?(.*)$`)
+
+// testAlert is the alert of the previews without alerts, like the alert of the notifications sent to test
+// the contact points.
+var testAlert = apimodels.TemplatePreviewAlert{
+	Labels:      map[string]string{"alertname": "TestAlert", "instance": "Grafana"},
+	Annotations: map[string]string{"summary": "Notification test"},
+	Value:       "[ metric='foo' labels={instance=bar} value=10 ]",
+}
+
+// PreviewTemplates renders the notifications of receivers for alerts with template files and the default template,
+// as the notifiers would send them. If a template file fails to parse, the results have its error and no
+// notification.
+func (am *Alertmanager) PreviewTemplates(preview *apimodels.PostableTemplatePreview) (*apimodels.TemplatePreviewResults, error) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			am.logger.Warn("failed to remove template preview directory", "path", dir, "err", err)
+		}
+	}()
+
+	names := make([]string, 0, len(preview.TemplateFiles))
+	for name := range preview.TemplateFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := &apimodels.TemplatePreviewResults{}
+	files := make(map[string]string, len(preview.TemplateFiles)+1)
+	for _, name := range names {
+		if name != filepath.Base(filepath.Clean(name)) || name == defaultTemplateFile {
+			results.Errors = append(results.Errors, apimodels.TemplatePreviewError{File: name, Message: "invalid template file name"})
+		}
+		files[name] = preview.TemplateFiles[name]
+	}
+	if len(results.Errors) > 0 {
+		return results, nil
+	}
+	files[defaultTemplateFile] = channels.DefaultTemplateString
+	if _, _, err := PersistTemplates(&apimodels.PostableUserConfig{TemplateFiles: files}, dir); err != nil {
+		return nil, err
+	}
+
+	// each file is parsed with the default template, to return the errors of all the files
+	defaultPath := filepath.Join(dir, defaultTemplateFile)
+	paths := []string{defaultPath}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, err := template.FromGlobs(defaultPath, path); err != nil {
+			results.Errors = append(results.Errors, toTemplatePreviewError(err))
+		}
+		paths = append(paths, path)
+	}
+	if len(results.Errors) > 0 {
+		return results, nil
+	}
+
+	tmpl, err := template.FromGlobs(paths...)
+	if err != nil {
+		return nil, err
+	}
+	externalURL, err := url.Parse(am.Settings.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.ExternalURL = externalURL
+
+	alerts := toPreviewAlerts(preview.Alerts)
+	groupLabels := make(model.LabelSet, len(preview.GroupLabels))
+	for k, v := range preview.GroupLabels {
+		groupLabels[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	receivers := preview.Receivers
+	if len(receivers) == 0 {
+		for _, t := range channels.PreviewTypes() {
+			receivers = append(receivers, &apimodels.PostableGrafanaReceiver{Name: t, Type: t, Settings: simplejson.New()})
+		}
+	}
+	for _, r := range receivers {
+		ctx := notify.WithGroupLabels(notify.WithReceiverName(context.Background(), r.Name), groupLabels)
+		notification := apimodels.TemplatePreviewNotification{Receiver: r.Name, Type: r.Type}
+		notification.Title, notification.Message, err = channels.PreviewNotification(ctx, tmpl, r.Type, r.Settings, alerts)
+		if err != nil {
+			e := toTemplatePreviewError(err)
+			notification.Error = &e
+		}
+		results.Notifications = append(results.Notifications, notification)
+	}
+	return results, nil
+}
+
+func toPreviewAlerts(previewAlerts []apimodels.TemplatePreviewAlert) []*types.Alert {
+	if len(previewAlerts) == 0 {
+		previewAlerts = []apimodels.TemplatePreviewAlert{testAlert}
+	}
+
+	now := time.Now()
+	alerts := make([]*types.Alert, 0, len(previewAlerts))
+	for _, a := range previewAlerts {
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels:       model.LabelSet{},
+				Annotations:  model.LabelSet{},
+				StartsAt:     now,
+				EndsAt:       now.Add(defaultResolveTimeout),
+				GeneratorURL: a.GeneratorURL,
+			},
+			UpdatedAt: now,
+		}
+		if a.Resolved {
+			alert.EndsAt = now
+		}
+		for k, v := range a.Labels {
+			alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if a.Value != "" {
+			alert.Labels["__value__"] = model.LabelValue(a.Value)
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// toTemplatePreviewError returns the error of a template, with its file and line when the error has them.
+func toTemplatePreviewError(err error) apimodels.TemplatePreviewError {
+	m := templateErrorRegex.FindStringSubmatch(err.Error())
+	if m == nil {
+		return apimodels.TemplatePreviewError{Message: err.Error()}
+	}
+	// the line cannot fail to parse, it matches digits
+	line, _ := strconv.Atoi(m[2])
+	return apimodels.TemplatePreviewError{File: m[1], Line: line, Message: m[3]}
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/notifier/channels"
+)
+
+func TestPreviewTemplates(t *testing.T) {
+	am := setupAMTest(t)
+
+	t.Run("the notifications of every type are rendered for a test alert without receivers", func(t *testing.T) {
+		results, err := am.PreviewTemplates(&apimodels.PostableTemplatePreview{})
+		require.NoError(t, err)
+		require.Empty(t, results.Errors)
+		require.Len(t, results.Notifications, len(channels.PreviewTypes()))
+		var slack apimodels.TemplatePreviewNotification
+		for _, n := range results.Notifications {
+			assert.Nil(t, n.Error)
+			assert.Equal(t, n.Type, n.Receiver)
+			if n.Type == "slack" {
+				slack = n
+			}
+		}
+		assert.Equal(t, "[FIRING:1]  (TestAlert [ metric='foo' labels={instance=bar} value=10 ] Grafana)", slack.Title)
+		assert.Contains(t, slack.Message, " - summary = Notification test\n")
+	})
+
+	t.Run("the templates of the receiver settings use the template files", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			"title": `{{ template "slack.title" . }}`,
+			"text":  `{{ range .Alerts }}{{ .Annotations.summary }}: {{ .Labels.__value__ }}{{ end }}`,
+		})
+		results, err := am.PreviewTemplates(&apimodels.PostableTemplatePreview{
+			TemplateFiles: map[string]string{
+				"slack.tmpl": `{{ define "slack.title" }}{{ .Status }} {{ .GroupLabels.service }} ({{ .Alerts | len }}){{ end }}`,
+			},
+			Receivers: []*apimodels.PostableGrafanaReceiver{{Name: "ops", Type: "slack", Settings: settings}},
+			Alerts: []apimodels.TemplatePreviewAlert{
+				{Labels: map[string]string{"service": "api"}, Annotations: map[string]string{"summary": "API down"}, Value: "B=0", Resolved: true},
+			},
+			GroupLabels: map[string]string{"service": "api"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, results.Errors)
+		assert.Equal(t, []apimodels.TemplatePreviewNotification{
+			{Receiver: "ops", Type: "slack", Title: "resolved api (1)", Message: "API down: B=0"},
+		}, results.Notifications)
+	})
+
+	t.Run("the template files failing to parse return their errors", func(t *testing.T) {
+		results, err := am.PreviewTemplates(&apimodels.PostableTemplatePreview{
+			TemplateFiles: map[string]string{
+				"a.tmpl":    "{{ define \"a\" }}\n{{ .Status }\n{{ end }}",
+				"b.tmpl":    "{{ define \"b\" }}{{ unknown . }}{{ end }}",
+				"ok.tmpl":   "{{ define \"ok\" }}ok{{ end }}",
+				"../x.tmpl": "",
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results.Notifications)
+		assert.Equal(t, []apimodels.TemplatePreviewError{
+			{File: "../x.tmpl", Message: "invalid template file name"},
+		}, results.Errors)
+
+		results, err = am.PreviewTemplates(&apimodels.PostableTemplatePreview{
+			TemplateFiles: map[string]string{
+				"a.tmpl":  "{{ define \"a\" }}\n{{ .Status }\n{{ end }}",
+				"b.tmpl":  "{{ define \"b\" }}{{ unknown . }}{{ end }}",
+				"ok.tmpl": "{{ define \"ok\" }}ok{{ end }}",
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results.Notifications)
+		require.Len(t, results.Errors, 2)
+		assert.Equal(t, "a.tmpl", results.Errors[0].File)
+		assert.Equal(t, 2, results.Errors[0].Line)
+		assert.Equal(t, apimodels.TemplatePreviewError{File: "b.tmpl", Line: 1, Message: `function "unknown" not defined`}, results.Errors[1])
+	})
+
+	t.Run("the templates failing to render return their errors by receiver", func(t *testing.T) {
+		results, err := am.PreviewTemplates(&apimodels.PostableTemplatePreview{
+			Receivers: []*apimodels.PostableGrafanaReceiver{
+				{Name: "ops", Type: "teams", Settings: simplejson.NewFromAny(map[string]interface{}{"message": `{{ template "missing" . }}`})},
+				{Name: "unknown", Type: "unknown"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, results.Notifications, 2)
+		require.NotNil(t, results.Notifications[0].Error)
+		assert.Equal(t, 1, results.Notifications[0].Error.Line)
+		assert.Contains(t, results.Notifications[0].Error.Message, `template "missing" not defined`)
+		assert.Equal(t, &apimodels.TemplatePreviewError{Message: "notifier unknown is not supported"}, results.Notifications[1].Error)
+	})
+}
@@ -0,0 +1,239 @@
+package prom
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+const (
+	datasourceTypeLoki = "loki"
+
+	// defaultInterval is the default evaluation interval of Prometheus, used for the groups without interval.
+	defaultInterval = time.Minute
+
+	// queryRange is the time range of the queries. The data sources run range queries, of which the last value is
+	// compared with the threshold: the alerts resolve up to a query range later than in Prometheus.
+	queryRange = time.Minute
+)
+
+// valueRegex matches the $value variable of the templates of Prometheus, but not $values.
+var valueRegex = regexp.MustCompile(`\$value\b`)
+
+// RuleFile is a Prometheus or Loki rule file.
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of alerting and recording rules of a Prometheus or Loki rule file.
+type RuleGroup struct {
+	Name     string                  `yaml:"name"`
+	Interval model.Duration          `yaml:"interval,omitempty"`
+	Rules    []apimodels.ApiRuleNode `yaml:"rules"`
+}
+
+// ParseRuleFile parses and validates a Prometheus or Loki rule file.
+func ParseRuleFile(b []byte) (*RuleFile, error) {
+	var file RuleFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("invalid rule file: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(file.Groups))
+	for _, g := range file.Groups {
+		if g.Name == "" {
+			return nil, fmt.Errorf("invalid rule file: rule group name is empty")
+		}
+		if _, ok := names[g.Name]; ok {
+			return nil, fmt.Errorf("invalid rule file: rule group %s is repeated", g.Name)
+		}
+		names[g.Name] = struct{}{}
+
+		for i, r := range g.Rules {
+			if (r.Alert == "") == (r.Record == "") {
+				return nil, fmt.Errorf("invalid rule %d of rule group %s: exactly one of alert and record must be set", i, g.Name)
+			}
+			if r.Expr == "" {
+				return nil, fmt.Errorf("invalid rule %d of rule group %s: expr is empty", i, g.Name)
+			}
+		}
+	}
+	return &file, nil
+}
+
+// Converter converts the rules of Prometheus or Loki rule files into Grafana managed rules querying a data
+// source. The titles of the rules are unique in an organization: the alerting rules repeating a name, for
+// instance with a different severity, are numbered.
+type Converter struct {
+	datasource *models.DataSource
+	titles     map[string]int
+}
+
+// NewConverter returns a converter of rules querying a Prometheus or Loki data source.
+func NewConverter(ds *models.DataSource) (*Converter, error) {
+	if ds.Type != models.DS_PROMETHEUS && ds.Type != datasourceTypeLoki {
+		return nil, fmt.Errorf("data source %s is not a Prometheus or Loki data source", ds.Name)
+	}
+	return &Converter{datasource: ds, titles: make(map[string]int)}, nil
+}
+
+// ConvertRuleGroup converts a rule group of a rule file.
+func (c *Converter) ConvertRuleGroup(g RuleGroup) (apimodels.PostableRuleGroupConfig, error) {
+	interval := model.Duration(defaultInterval)
+	if g.Interval != 0 {
+		interval = g.Interval
+	}
+
+	result := apimodels.PostableRuleGroupConfig{
+		Name:     g.Name,
+		Interval: interval,
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(g.Rules)),
+	}
+	for _, r := range g.Rules {
+		rule, err := c.convertRule(r)
+		if err != nil {
+			return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("failed to convert rule group %s: %w", g.Name, err)
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
+
+// convertRule converts a rule into a rule querying the data source (A). Alerting rules reduce the query to its
+// last value (B), and compare it with the threshold of the Prometheus expression (C). If the expression is not
+// a comparison with a number, every series returned by the query fires, as in Prometheus.
+func (c *Converter) convertRule(r apimodels.ApiRuleNode) (apimodels.PostableExtendedRuleNode, error) {
+	name := r.Alert
+	if r.Record != "" {
+		name = r.Record
+	}
+
+	query := r.Expr
+	threshold := ""
+	if c.datasource.Type == models.DS_PROMETHEUS {
+		parsed, err := parser.ParseExpr(r.Expr)
+		if err != nil {
+			return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid expression of rule %s: %w", name, err)
+		}
+		if r.Alert != "" {
+			query, threshold = splitThreshold(parsed)
+		}
+	}
+
+	data := []ngmodels.AlertQuery{c.query("A", query)}
+	grafanaRule := &apimodels.PostableGrafanaRule{
+		Title:        c.title(name),
+		NoDataState:  apimodels.OK,
+		ExecErrState: apimodels.AlertingErrState,
+	}
+	if r.Record != "" {
+		if !model.IsValidMetricName(model.LabelValue(r.Record)) {
+			return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid metric name of recording rule %s", r.Record)
+		}
+		grafanaRule.Data = data
+		grafanaRule.Record = &ngmodels.Record{Metric: r.Record, From: "A"}
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode:         &apimodels.ApiRuleNode{Labels: r.Labels},
+			GrafanaManagedAlert: grafanaRule,
+		}, nil
+	}
+
+	data = append(data, expression("B", map[string]interface{}{"type": "reduce", "reducer": "last", "expression": "A"}))
+	grafanaRule.Condition = "C"
+	if threshold != "" {
+		data = append(data, expression("C", map[string]interface{}{"type": "math", "expression": threshold}))
+	} else {
+		grafanaRule.Condition = "D"
+		data = append(data,
+			expression("C", map[string]interface{}{"type": "reduce", "reducer": "count", "expression": "A"}),
+			expression("D", map[string]interface{}{"type": "math", "expression": "$C > 0"}),
+		)
+	}
+	grafanaRule.Data = data
+
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:           r.For,
+			KeepFiringFor: r.KeepFiringFor,
+			Labels:        convertTemplates(r.Labels),
+			Annotations:   convertTemplates(r.Annotations),
+		},
+		GrafanaManagedAlert: grafanaRule,
+	}, nil
+}
+
+func (c *Converter) title(name string) string {
+	c.titles[name]++
+	if n := c.titles[name]; n > 1 {
+		return fmt.Sprintf("%s %d", name, n)
+	}
+	return name
+}
+
+func (c *Converter) query(refID, query string) ngmodels.AlertQuery {
+	// the model cannot fail to marshal
+	model, _ := json.Marshal(map[string]interface{}{"refId": refID, "expr": query})
+	return ngmodels.AlertQuery{
+		RefID:             refID,
+		DatasourceUID:     c.datasource.Uid,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(queryRange)},
+		Model:             model,
+	}
+}
+
+func expression(refID string, props map[string]interface{}) ngmodels.AlertQuery {
+	props["refId"] = refID
+	// the model cannot fail to marshal
+	model, _ := json.Marshal(props)
+	return ngmodels.AlertQuery{RefID: refID, DatasourceUID: expr.DatasourceUID, Model: model}
+}
+
+// splitThreshold splits a comparison of a query with a number, such as rate(errors[5m]) > 0.5, into the query and
+// the math expression comparing its reduced value (B) with the number. The threshold is empty if the expression
+// is not such a comparison.
+func splitThreshold(e parser.Expr) (query string, threshold string) {
+	b, ok := e.(*parser.BinaryExpr)
+	if !ok || !b.Op.IsComparisonOperator() || b.ReturnBool {
+		return e.String(), ""
+	}
+	if n, ok := b.RHS.(*parser.NumberLiteral); ok && b.LHS.Type() == parser.ValueTypeVector && isFinite(n.Val) {
+		return b.LHS.String(), fmt.Sprintf("$B %s %s", b.Op, formatNumber(n.Val))
+	}
+	if n, ok := b.LHS.(*parser.NumberLiteral); ok && b.RHS.Type() == parser.ValueTypeVector && isFinite(n.Val) {
+		return b.RHS.String(), fmt.Sprintf("%s %s $B", formatNumber(n.Val), b.Op)
+	}
+	return e.String(), ""
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// convertTemplates replaces the $value variable of the templates of Prometheus, which is the value of the
+// series, with the last value of the query.
+func convertTemplates(templates map[string]string) map[string]string {
+	if templates == nil {
+		return nil
+	}
+	converted := make(map[string]string, len(templates))
+	for k, v := range templates {
+		converted[k] = valueRegex.ReplaceAllString(v, "$$values.B.Value")
+	}
+	return converted
+}
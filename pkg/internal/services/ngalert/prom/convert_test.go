@@ -0,0 +1,144 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/models"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+const ruleFile = `
+groups:
+  - name: api
+    interval: 30s
+    rules:
+      - alert: HighErrorRate
+        expr: rate(errors_total[5m]) > 0.5
+        for: 10m
+        labels:
+          severity: critical
+        annotations:
+          summary: "{{ $labels.job }} has {{ $value | humanize }} errors per second"
+      - alert: HighErrorRate
+        expr: 0.1 < rate(errors_total[5m])
+        labels:
+          severity: warning
+      - alert: JobMissing
+        expr: absent(up{job="api"})
+      - record: job:errors:rate5m
+        expr: sum by (job) (rate(errors_total[5m]))
+`
+
+func modelOf(t *testing.T, q ngmodels.AlertQuery) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(q.Model, &m))
+	return m
+}
+
+func TestParseRuleFile(t *testing.T) {
+	file, err := ParseRuleFile([]byte(ruleFile))
+	require.NoError(t, err)
+	require.Len(t, file.Groups, 1)
+	assert.Equal(t, model.Duration(30*time.Second), file.Groups[0].Interval)
+	assert.Len(t, file.Groups[0].Rules, 4)
+
+	invalid := map[string]string{
+		"alert and record": "groups: [{name: a, rules: [{alert: a, record: b, expr: up}]}]",
+		"no expression":    "groups: [{name: a, rules: [{alert: a}]}]",
+		"repeated group":   "groups: [{name: a, rules: []}, {name: a, rules: []}]",
+		"no group name":    "groups: [{rules: []}]",
+	}
+	for name, text := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseRuleFile([]byte(text))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConvertRuleGroup(t *testing.T) {
+	file, err := ParseRuleFile([]byte(ruleFile))
+	require.NoError(t, err)
+	prometheus := &models.DataSource{Uid: "prom", Type: models.DS_PROMETHEUS}
+
+	converter, err := NewConverter(prometheus)
+	require.NoError(t, err)
+	config, err := converter.ConvertRuleGroup(file.Groups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "api", config.Name)
+	assert.Equal(t, model.Duration(30*time.Second), config.Interval)
+	require.Len(t, config.Rules, 4)
+
+	t.Run("comparisons with a number are split into a query and a threshold", func(t *testing.T) {
+		r := config.Rules[0]
+		assert.Equal(t, "HighErrorRate", r.GrafanaManagedAlert.Title)
+		assert.Equal(t, "C", r.GrafanaManagedAlert.Condition)
+		assert.Equal(t, apimodels.OK, r.GrafanaManagedAlert.NoDataState)
+		require.Len(t, r.GrafanaManagedAlert.Data, 3)
+
+		a, b, c := r.GrafanaManagedAlert.Data[0], r.GrafanaManagedAlert.Data[1], r.GrafanaManagedAlert.Data[2]
+		assert.Equal(t, "prom", a.DatasourceUID)
+		assert.Equal(t, "rate(errors_total[5m])", modelOf(t, a)["expr"])
+		assert.Equal(t, expr.DatasourceUID, b.DatasourceUID)
+		assert.Equal(t, "last", modelOf(t, b)["reducer"])
+		assert.Equal(t, "$B > 0.5", modelOf(t, c)["expression"])
+
+		assert.Equal(t, model.Duration(10*time.Minute), r.ApiRuleNode.For)
+		assert.Equal(t, map[string]string{"severity": "critical"}, r.ApiRuleNode.Labels)
+		assert.Equal(t, "{{ $labels.job }} has {{ $values.B.Value | humanize }} errors per second", r.ApiRuleNode.Annotations["summary"])
+	})
+
+	t.Run("repeated alert names are numbered", func(t *testing.T) {
+		r := config.Rules[1]
+		assert.Equal(t, "HighErrorRate 2", r.GrafanaManagedAlert.Title)
+		assert.Equal(t, "0.1 < $B", modelOf(t, r.GrafanaManagedAlert.Data[2])["expression"])
+	})
+
+	t.Run("every series of other expressions fires", func(t *testing.T) {
+		r := config.Rules[2]
+		assert.Equal(t, "D", r.GrafanaManagedAlert.Condition)
+		require.Len(t, r.GrafanaManagedAlert.Data, 4)
+		assert.Equal(t, `absent(up{job="api"})`, modelOf(t, r.GrafanaManagedAlert.Data[0])["expr"])
+		assert.Equal(t, "count", modelOf(t, r.GrafanaManagedAlert.Data[2])["reducer"])
+		assert.Equal(t, "$C > 0", modelOf(t, r.GrafanaManagedAlert.Data[3])["expression"])
+	})
+
+	t.Run("recording rules record the query", func(t *testing.T) {
+		r := config.Rules[3]
+		assert.Equal(t, &ngmodels.Record{Metric: "job:errors:rate5m", From: "A"}, r.GrafanaManagedAlert.Record)
+		assert.Empty(t, r.GrafanaManagedAlert.Condition)
+		require.Len(t, r.GrafanaManagedAlert.Data, 1)
+	})
+
+	t.Run("loki expressions are not split", func(t *testing.T) {
+		converter, err := NewConverter(&models.DataSource{Uid: "loki", Type: "loki"})
+		require.NoError(t, err)
+		config, err := converter.ConvertRuleGroup(RuleGroup{Name: "logs", Rules: []apimodels.ApiRuleNode{
+			{Alert: "Errors", Expr: `sum(rate({app="api"} |= "error" [5m])) > 1`},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, model.Duration(defaultInterval), config.Interval)
+		r := config.Rules[0]
+		assert.Equal(t, "D", r.GrafanaManagedAlert.Condition)
+		assert.Equal(t, `sum(rate({app="api"} |= "error" [5m])) > 1`, modelOf(t, r.GrafanaManagedAlert.Data[0])["expr"])
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		_, err := NewConverter(&models.DataSource{Type: "graphite"})
+		require.Error(t, err)
+
+		converter, err := NewConverter(prometheus)
+		require.NoError(t, err)
+		_, err = converter.ConvertRuleGroup(RuleGroup{Name: "a", Rules: []apimodels.ApiRuleNode{{Alert: "a", Expr: "rate(("}}})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,50 @@
+package prom
+
+import (
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+)
+
+// ConvertRuleFile converts the rule groups of a rule file.
+func (c *Converter) ConvertRuleFile(file *RuleFile) ([]apimodels.PostableRuleGroupConfig, error) {
+	configs := make([]apimodels.PostableRuleGroupConfig, 0, len(file.Groups))
+	for _, g := range file.Groups {
+		config, err := c.ConvertRuleGroup(g)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// SaveRuleGroups saves converted rule groups in a namespace. The rule groups with the same names are replaced,
+// and their rules with the same titles keep their UIDs. It returns the UIDs of the rules of the replaced rule
+// groups, whose states are reset.
+func SaveRuleGroups(st store.RuleStore, orgID int64, namespaceUID string, configs []apimodels.PostableRuleGroupConfig) ([]string, error) {
+	var replaced []string
+	for _, config := range configs {
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: namespaceUID, RuleGroup: config.Name}
+		if err := st.GetRuleGroupAlertRules(&q); err != nil {
+			return nil, err
+		}
+		uids := make(map[string]string, len(q.Result))
+		for _, r := range q.Result {
+			uids[r.Title] = r.UID
+			replaced = append(replaced, r.UID)
+		}
+		for _, r := range config.Rules {
+			r.GrafanaManagedAlert.UID = uids[r.GrafanaManagedAlert.Title]
+		}
+
+		if err := st.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:           orgID,
+			NamespaceUID:    namespaceUID,
+			RuleGroupConfig: config,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return replaced, nil
+}
@@ -0,0 +1,54 @@
+// +build integration
+
+package prom_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/registry"
+	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/tests"
+)
+
+func TestSaveRuleGroups(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, 10)
+	t.Cleanup(registry.ClearOverrides)
+
+	importFile := func(t *testing.T, text string) []string {
+		t.Helper()
+		file, err := prom.ParseRuleFile([]byte(text))
+		require.NoError(t, err)
+		converter, err := prom.NewConverter(&models.DataSource{Uid: "prom", Type: models.DS_PROMETHEUS})
+		require.NoError(t, err)
+		configs, err := converter.ConvertRuleFile(file)
+		require.NoError(t, err)
+		replaced, err := prom.SaveRuleGroups(dbstore, 1, "namespace", configs)
+		require.NoError(t, err)
+		return replaced
+	}
+	groupRules := func(t *testing.T) map[string]string {
+		t.Helper()
+		q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: "api"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+		uids := make(map[string]string, len(q.Result))
+		for _, r := range q.Result {
+			uids[r.Title] = r.UID
+		}
+		return uids
+	}
+
+	replaced := importFile(t, "groups: [{name: api, rules: [{alert: Down, expr: up == 0}, {alert: Slow, expr: latency > 1}]}]")
+	assert.Empty(t, replaced)
+	before := groupRules(t)
+	require.Len(t, before, 2)
+
+	replaced = importFile(t, "groups: [{name: api, rules: [{alert: Down, expr: up == 0, for: 5m}]}]")
+	assert.ElementsMatch(t, []string{before["Down"], before["Slow"]}, replaced)
+	after := groupRules(t)
+	assert.Equal(t, map[string]string{"Down": before["Down"]}, after, "the rules keep their UID and the missing rules are deleted")
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+// datasourceLimiter limits the number of evaluations querying each data source at once. It is shared by the
+// routines of all the alert rules.
+type datasourceLimiter struct {
+	max int
+
+	mtx        sync.Mutex
+	semaphores map[string]chan struct{}
+}
+
+func newDatasourceLimiter(max int) *datasourceLimiter {
+	return &datasourceLimiter{max: max, semaphores: make(map[string]chan struct{})}
+}
+
+func (l *datasourceLimiter) semaphore(uid string) chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	sem, ok := l.semaphores[uid]
+	if !ok {
+		sem = make(chan struct{}, l.max)
+		l.semaphores[uid] = sem
+	}
+	return sem
+}
+
+// acquire blocks until the alert rule can query its data sources, and returns the function releasing them.
+// The data sources are acquired in order of UID, so that the rules querying the same data sources cannot
+// deadlock. Expressions and rule state queries are not limited.
+func (l *datasourceLimiter) acquire(ctx context.Context, rule *models.AlertRule) (func(), error) {
+	if l.max <= 0 {
+		return func() {}, nil
+	}
+
+	uids := make([]string, 0, len(rule.Data))
+	seen := make(map[string]struct{}, len(rule.Data))
+	for _, q := range rule.Data {
+		if q.DatasourceUID == expr.DatasourceUID || q.IsRuleState() {
+			continue
+		}
+		if _, ok := seen[q.DatasourceUID]; ok {
+			continue
+		}
+		seen[q.DatasourceUID] = struct{}{}
+		uids = append(uids, q.DatasourceUID)
+	}
+	sort.Strings(uids)
+
+	acquired := make([]chan struct{}, 0, len(uids))
+	release := func() {
+		for _, sem := range acquired {
+			<-sem
+		}
+	}
+	for _, uid := range uids {
+		sem := l.semaphore(uid)
+		select {
+		case sem <- struct{}{}:
+			acquired = append(acquired, sem)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
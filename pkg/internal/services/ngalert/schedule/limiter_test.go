@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+func ruleQuerying(uids ...string) *models.AlertRule {
+	rule := &models.AlertRule{}
+	for _, uid := range uids {
+		rule.Data = append(rule.Data, models.AlertQuery{DatasourceUID: uid})
+	}
+	return rule
+}
+
+func TestDatasourceLimiter(t *testing.T) {
+	t.Run("evaluations over the limit wait for the data source", func(t *testing.T) {
+		limiter := newDatasourceLimiter(1)
+		release, err := limiter.acquire(context.Background(), ruleQuerying("a", "b"))
+		require.NoError(t, err)
+
+		acquired := make(chan struct{})
+		go func() {
+			release, err := limiter.acquire(context.Background(), ruleQuerying("b"))
+			assert.NoError(t, err)
+			release()
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("the data source was acquired twice")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			t.Fatal("the data source was not released")
+		}
+	})
+
+	t.Run("waiting evaluations stop with the context", func(t *testing.T) {
+		limiter := newDatasourceLimiter(1)
+		_, err := limiter.acquire(context.Background(), ruleQuerying("a"))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.acquire(ctx, ruleQuerying("b", "a"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release, err := limiter.acquire(context.Background(), ruleQuerying("b"))
+		require.NoError(t, err, "the data sources acquired before the timeout are released")
+		release()
+	})
+
+	t.Run("expressions and repeated data sources are acquired once", func(t *testing.T) {
+		limiter := newDatasourceLimiter(1)
+		release, err := limiter.acquire(context.Background(), ruleQuerying("a", expr.DatasourceUID, "a", expr.DatasourceUID))
+		require.NoError(t, err)
+		release()
+		assert.NotContains(t, limiter.semaphores, expr.DatasourceUID)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		limiter := newDatasourceLimiter(0)
+		for i := 0; i < 10; i++ {
+			_, err := limiter.acquire(context.Background(), ruleQuerying("a"))
+			require.NoError(t, err)
+		}
+	})
+}
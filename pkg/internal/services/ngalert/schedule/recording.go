@@ -0,0 +1,31 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+// recordRule executes the queries and expressions of a recording rule and writes the result of the
+// query or expression it records, labelled with the labels of the rule.
+func (sch *schedule) recordRule(ctx context.Context, rule *models.AlertRule, now time.Time) error {
+	if sch.recorder == nil {
+		return fmt.Errorf("recording rules are not supported")
+	}
+
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(rule.OrgID, rule.Data, now, sch.dataService)
+	if err != nil {
+		return err
+	}
+	res, ok := resp.Responses[rule.Record.From]
+	if !ok {
+		return fmt.Errorf("no result for the recorded query or expression %s", rule.Record.From)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("failed to execute the recorded query or expression %s: %w", rule.Record.From, res.Error)
+	}
+
+	return sch.recorder.Write(ctx, rule.OrgID, rule.Record.Metric, rule.Labels, res.Frames, now)
+}
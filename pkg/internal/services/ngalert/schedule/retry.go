@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+// executionError returns the error of the evaluation results if the condition failed to execute, for
+// instance because a data source is unavailable or the evaluation timed out.
+func executionError(results eval.Results) error {
+	for _, r := range results {
+		if r.State == eval.Error && r.Error != nil {
+			return r.Error
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay before the attempt following the given one, which doubles after each attempt.
+// It's capped at the interval of the rule, so that it doesn't overflow.
+func (sch *schedule) backoff(alertRule *models.AlertRule, attempt int64) time.Duration {
+	interval := sch.ruleInterval(alertRule)
+	backoff := sch.retryBackoff
+	for i := int64(0); i < attempt && backoff < interval; i++ {
+		backoff *= 2
+	}
+	if backoff > interval {
+		return interval
+	}
+	return backoff
+}
+
+func (sch *schedule) ruleInterval(alertRule *models.AlertRule) time.Duration {
+	if alertRule != nil {
+		return time.Duration(alertRule.IntervalSeconds) * time.Second
+	}
+	return sch.baseInterval
+}
+
+// retryAllowed returns true if a failed attempt to evaluate an alert rule can be followed by another one.
+// The attempts must start before the next evaluation of the rule.
+func (sch *schedule) retryAllowed(evaluatedAt time.Time, alertRule *models.AlertRule, attempt int64) bool {
+	if attempt+1 >= sch.maxAttempts {
+		return false
+	}
+	interval := sch.ruleInterval(alertRule)
+	return !sch.clock.Now().Add(sch.backoff(alertRule, attempt)).After(evaluatedAt.Add(interval))
+}
+
+// errRuleRoutineStopped is returned by waitRetry when the routine of the alert rule is stopped while waiting.
+var errRuleRoutineStopped = errors.New("alert rule routine stopped")
+
+// waitRetry waits before the next attempt to evaluate an alert rule. It returns an error if the scheduler is
+// stopped, or the routine of the rule is stopped through stopCh, in the meantime, so that the rule can be
+// deleted, paused or handed off without waiting for its retries.
+func (sch *schedule) waitRetry(ctx context.Context, stopCh <-chan struct{}, alertRule *models.AlertRule, attempt int64) error {
+	backoff := sch.backoff(alertRule, attempt)
+	if backoff <= 0 {
+		return nil
+	}
+	select {
+	case <-sch.clock.After(backoff):
+		return nil
+	case <-stopCh:
+		return errRuleRoutineStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
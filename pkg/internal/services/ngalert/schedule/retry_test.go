@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+func TestRetry(t *testing.T) {
+	mockedClock := clock.NewMock()
+	sch := &schedule{clock: mockedClock, maxAttempts: 3, retryBackoff: time.Second, baseInterval: 10 * time.Second}
+	rule := &models.AlertRule{IntervalSeconds: 10}
+	now := mockedClock.Now()
+
+	t.Run("the attempts are bounded", func(t *testing.T) {
+		assert.True(t, sch.retryAllowed(now, rule, 0))
+		assert.True(t, sch.retryAllowed(now, rule, 1))
+		assert.False(t, sch.retryAllowed(now, rule, 2))
+	})
+
+	t.Run("the attempts start before the next evaluation", func(t *testing.T) {
+		mockedClock.Add(9 * time.Second)
+		t.Cleanup(func() { mockedClock.Set(now) })
+		assert.True(t, sch.retryAllowed(now, rule, 0))
+		assert.False(t, sch.retryAllowed(now, rule, 1), "the second backoff of 2s ends after the interval")
+		assert.False(t, sch.retryAllowed(now, &models.AlertRule{IntervalSeconds: 5}, 0))
+	})
+
+	t.Run("the backoff is doubled after each attempt", func(t *testing.T) {
+		waited := make(chan error)
+		go func() { waited <- sch.waitRetry(context.Background(), nil, rule, 1) }()
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-waited:
+				t.Fatal("the backoff ended early")
+			case <-time.After(10 * time.Millisecond):
+			}
+			mockedClock.Add(time.Second)
+		}
+		select {
+		case err := <-waited:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("the backoff did not end")
+		}
+	})
+
+	t.Run("the backoff ends when the rule routine is stopped", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		waited := make(chan error)
+		go func() { waited <- sch.waitRetry(context.Background(), stopCh, rule, 1) }()
+
+		select {
+		case stopCh <- struct{}{}:
+		case <-time.After(time.Second):
+			t.Fatal("the stop was not received")
+		}
+		assert.ErrorIs(t, <-waited, errRuleRoutineStopped)
+	})
+
+	t.Run("the backoff is capped at the rule interval", func(t *testing.T) {
+		assert.Equal(t, 8*time.Second, sch.backoff(rule, 3))
+		assert.Equal(t, 10*time.Second, sch.backoff(rule, 4))
+		assert.Equal(t, 10*time.Second, sch.backoff(rule, 100))
+		assert.Equal(t, sch.baseInterval, sch.backoff(nil, 100))
+	})
+
+	t.Run("execution errors are retried", func(t *testing.T) {
+		err := errors.New("connection refused")
+		assert.Equal(t, err, executionError(eval.Results{{State: eval.Normal}, {State: eval.Error, Error: err}}))
+		assert.NoError(t, executionError(eval.Results{{State: eval.Alerting}, {State: eval.NoData}}))
+	})
+}
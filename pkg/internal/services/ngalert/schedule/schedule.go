@@ -2,11 +2,14 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
 	"golang.org/x/sync/errgroup"
@@ -29,6 +32,7 @@ type ScheduleService interface {
 	Pause() error
 	Unpause() error
 	WarmStateCache(*state.Manager)
+	Status() (*apimodels.SchedulerStatus, error)
 
 	// the following are used by tests only used for tests
 	evalApplied(models.AlertRuleKey, time.Time)
@@ -46,6 +50,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		select {
 		case ctx := <-evalCh:
 			if evalRunning {
+				if ctx.done != nil {
+					close(ctx.done)
+				}
 				continue
 			}
 
@@ -64,12 +71,41 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
 				}
 
-				condition := models.Condition{
-					Condition: alertRule.Condition,
-					OrgID:     alertRule.OrgID,
-					Data:      alertRule.Data,
+				// the rules of sequential rule groups can query the state of the rules evaluated before them
+				evaluatedRule := *alertRule
+				data, err := sch.resolveRuleStates(stateManager, alertRule)
+				if err != nil {
+					sch.log.Error("failed to resolve the rule state queries of the alert rule", "key", key, "attempt", attempt, "error", err)
+					return err
 				}
-				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				evaluatedRule.Data = data
+
+				// the evaluations waiting for a data source give up after the evaluation timeout, and are then
+				// evaluated with a timeout of their own
+				limitCtx, cancelFn := context.WithTimeout(grafanaCtx, sch.evaluator.Timeout())
+				release, err := sch.limiter.acquire(limitCtx, &evaluatedRule)
+				cancelFn()
+				if err != nil {
+					sch.log.Error("failed to wait for the data sources of the alert rule", "key", key, "attempt", attempt, "error", err)
+					return err
+				}
+
+				var results eval.Results
+				if alertRule.IsRecording() {
+					err = sch.recordRule(grafanaCtx, &evaluatedRule, ctx.now)
+				} else {
+					condition := models.Condition{
+						Condition: alertRule.Condition,
+						OrgID:     alertRule.OrgID,
+						Data:      evaluatedRule.Data,
+					}
+					results, err = sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+					// results failing to execute are only processed if the evaluation is not attempted again
+					if execErr := executionError(results); err == nil && execErr != nil && sch.retryAllowed(ctx.now, alertRule, attempt) {
+						err = execErr
+					}
+				}
+				release()
 				var (
 					end    = timeNow()
 					tenant = fmt.Sprint(alertRule.OrgID)
@@ -85,6 +121,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 						"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
 					return err
 				}
+				if alertRule.IsRecording() {
+					return nil
+				}
 
 				processedStates := stateManager.ProcessEvalResults(alertRule, results)
 				sch.saveAlertStates(processedStates)
@@ -97,20 +136,32 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				return nil
 			}
 
-			func() {
+			stopped := func() bool {
 				evalRunning = true
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.now)
+					if ctx.done != nil {
+						close(ctx.done)
+					}
 				}()
 
 				for attempt = 0; attempt < sch.maxAttempts; attempt++ {
 					err := evaluate(attempt)
-					if err == nil {
+					if err == nil || !sch.retryAllowed(ctx.now, alertRule, attempt) {
 						break
 					}
+					if err := sch.waitRetry(grafanaCtx, stopCh, alertRule, attempt); err != nil {
+						return errors.Is(err, errRuleRoutineStopped)
+					}
 				}
+				return false
 			}()
+			if stopped {
+				sch.stopApplied(key)
+				sch.log.Debug("stopping alert rule routine while retrying its evaluation", "key", key)
+				return nil
+			}
 		case <-stopCh:
 			sch.stopApplied(key)
 			sch.log.Debug("stopping alert rule routine", "key", key)
@@ -127,6 +178,11 @@ type Notifier interface {
 	PutAlerts(alerts apimodels.PostableAlerts) error
 }
 
+// Recorder writes the series computed by recording rules
+type Recorder interface {
+	Write(ctx context.Context, orgID int64, metric string, extraLabels map[string]string, frames data.Frames, ts time.Time) error
+}
+
 type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
@@ -134,7 +190,9 @@ type schedule struct {
 	// each alert rule gets its own channel and routine
 	registry alertRuleRegistry
 
-	maxAttempts int64
+	maxAttempts  int64
+	retryBackoff time.Duration
+	limiter      *datasourceLimiter
 
 	clock clock.Clock
 
@@ -161,7 +219,15 @@ type schedule struct {
 	dataService *tsdb.Service
 
 	notifier Notifier
+	recorder Recorder
 	metrics  *metrics.Metrics
+
+	sharding        bool
+	instanceID      string
+	instanceTimeout time.Duration
+	schedulerStore  store.SchedulerStore
+	// gainedRules are the times the alert rules not evaluated yet by this instance were first found to be owned by it.
+	gainedRules map[models.AlertRuleKey]time.Time
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -176,7 +242,18 @@ type SchedulerCfg struct {
 	RuleStore       store.RuleStore
 	InstanceStore   store.InstanceStore
 	Notifier        Notifier
+	Recorder        Recorder
 	Metrics         *metrics.Metrics
+	// Sharding partitions the alert rules between the instances sharing the database, which are
+	// identified by InstanceID and considered gone after InstanceTimeout without heartbeat.
+	Sharding        bool
+	InstanceID      string
+	InstanceTimeout time.Duration
+	SchedulerStore  store.SchedulerStore
+	// RetryBackoff is the wait before the second attempt of an evaluation, doubled after each attempt.
+	// MaxConcurrentEvaluationsPerDatasource limits the evaluations querying each data source at once.
+	RetryBackoff                          time.Duration
+	MaxConcurrentEvaluationsPerDatasource int
 }
 
 // NewScheduler returns a new schedule.
@@ -185,6 +262,8 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service) *schedule {
 	sch := schedule{
 		registry:        alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
 		maxAttempts:     cfg.MaxAttempts,
+		retryBackoff:    cfg.RetryBackoff,
+		limiter:         newDatasourceLimiter(cfg.MaxConcurrentEvaluationsPerDatasource),
 		clock:           cfg.C,
 		baseInterval:    cfg.BaseInterval,
 		log:             cfg.Logger,
@@ -196,7 +275,12 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service) *schedule {
 		instanceStore:   cfg.InstanceStore,
 		dataService:     dataService,
 		notifier:        cfg.Notifier,
+		recorder:        cfg.Recorder,
 		metrics:         cfg.Metrics,
+		sharding:        cfg.Sharding,
+		instanceID:      cfg.InstanceID,
+		instanceTimeout: cfg.InstanceTimeout,
+		schedulerStore:  cfg.SchedulerStore,
 	}
 	return &sch
 }
@@ -252,25 +336,40 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 			alertRules := sch.fetchAllDetails()
 			sch.log.Debug("alert rules fetched", "count", len(alertRules))
 
+			// existingRules is used for telling the alert rules owned by other instances from the deleted ones
+			var existingRules map[models.AlertRuleKey]struct{}
+			if sch.sharding {
+				existingRules = make(map[models.AlertRuleKey]struct{}, len(alertRules))
+				for _, item := range alertRules {
+					existingRules[item.GetKey()] = struct{}{}
+				}
+				alertRules = sch.ownedRules(alertRules)
+			}
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
-			type readyToRunItem struct {
-				key      models.AlertRuleKey
-				ruleInfo alertRuleInfo
-			}
 			readyToRun := make([]readyToRunItem, 0)
+			// the routines of the paused alert rules are stopped like the ones of the deleted alert rules
+			pausedRules := make(map[models.AlertRuleKey]*models.AlertRule)
 			for _, item := range alertRules {
 				key := item.GetKey()
+				if item.Paused() {
+					pausedRules[key] = item
+					continue
+				}
 				itemVersion := item.Version
 				newRoutine := !sch.registry.exists(key)
 				ruleInfo := sch.registry.getOrCreateInfo(key, itemVersion)
 				invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 				if newRoutine && !invalidInterval {
+					if sch.sharding {
+						sch.loadRuleStates(stateManager, item)
+					}
 					dispatcherGroup.Go(func() error {
 						return sch.ruleRoutine(ctx, key, ruleInfo.evalCh, ruleInfo.stopCh, stateManager)
 					})
@@ -285,27 +384,55 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+					readyToRun = append(readyToRun, readyToRunItem{key: key, rule: item, ruleInfo: ruleInfo})
 				}
 
 				// remove the alert rule from the registered alert rules
 				delete(registeredDefinitions, key)
 			}
 
+			// the rules of a sequential rule group are evaluated in order, and the group is staggered like a single rule
+			slots := make([][]readyToRunItem, 0, len(readyToRun))
+			groupSlots := make(map[ruleGroupKey]int)
+			for _, item := range readyToRun {
+				if !item.rule.RuleGroupSequential {
+					slots = append(slots, []readyToRunItem{item})
+					continue
+				}
+				groupKey := groupKeyOf(item.rule)
+				i, ok := groupSlots[groupKey]
+				if !ok {
+					i = len(slots)
+					groupSlots[groupKey] = i
+					slots = append(slots, nil)
+				}
+				slots[i] = append(slots[i], item)
+			}
+
 			var step int64 = 0
-			if len(readyToRun) > 0 {
-				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+			if len(slots) > 0 {
+				step = sch.baseInterval.Nanoseconds() / int64(len(slots))
 			}
 
-			for i := range readyToRun {
-				item := readyToRun[i]
+			for i := range slots {
+				items := slots[i]
+				if items[0].rule.RuleGroupSequential {
+					sort.SliceStable(items, func(i, j int) bool {
+						return items[i].rule.RuleGroupIndex < items[j].rule.RuleGroupIndex
+					})
+					time.AfterFunc(time.Duration(int64(i)*step), func() {
+						sch.evalSequentially(ctx, tick, items)
+					})
+					continue
+				}
 
+				item := items[0]
 				time.AfterFunc(time.Duration(int64(i)*step), func() {
 					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
 				})
 			}
 
-			// unregister and stop routines of the deleted alert rules
+			// unregister and stop routines of the deleted alert rules, and of the rules owned by other instances
 			for key := range registeredDefinitions {
 				ruleInfo, err := sch.registry.get(key)
 				if err != nil {
@@ -314,6 +441,22 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				}
 				ruleInfo.stopCh <- struct{}{}
 				sch.registry.del(key)
+
+				if _, ok := pausedRules[key]; ok {
+					sch.log.Debug("alert rule paused", "key", key)
+					continue
+				}
+				if _, ok := existingRules[key]; ok {
+					// the new owner loads the states from the database
+					sch.log.Debug("alert rule handed off to another instance", "key", key)
+					sch.saveAlertStates(stateManager.GetStatesForRuleUID(key.OrgID, key.UID))
+					stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+				}
+			}
+
+			// the alert rules can be paused while they are stopped, for instance when they are loaded on startup
+			for _, item := range pausedRules {
+				sch.resolveStates(stateManager, item, tick)
 			}
 		case <-grafanaCtx.Done():
 			waitErr := dispatcherGroup.Wait()
@@ -327,6 +470,14 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				sch.saveAlertStates(stateManager.GetAll(v))
 			}
 
+			if sch.sharding {
+				// hand off the alert rules to the other instances without waiting for the timeout
+				cmd := models.DeleteSchedulerInstancesCommand{InstanceID: sch.instanceID}
+				if err := sch.schedulerStore.DeleteSchedulerInstances(&cmd); err != nil {
+					sch.log.Error("unable to delete scheduler instance", "msg", err.Error())
+				}
+			}
+
 			stateManager.Close()
 			return waitErr
 		}
@@ -348,6 +499,10 @@ func (sch *schedule) saveAlertStates(states []*state.State) {
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			KeepFiringSince:   s.KeepFiringSince,
+			Flapping:          s.Flapping,
+			StateChanges:      s.StateChanges,
+			Annotations:       s.Annotations,
 		}
 		err := sch.instanceStore.SaveAlertInstance(&cmd)
 		if err != nil {
@@ -356,9 +511,48 @@ func (sch *schedule) saveAlertStates(states []*state.State) {
 	}
 }
 
+// resolveStates sets the states of a paused alert rule to Normal, and resolves its firing alerts.
+// The cached states are replaced by updated copies, as they may be read concurrently.
+func (sch *schedule) resolveStates(st *state.Manager, alertRule *models.AlertRule, now time.Time) {
+	var resolved []*state.State
+	for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		if s.State == eval.Normal {
+			continue
+		}
+		r := *s
+		r.EndsAt = now
+		resolved = append(resolved, &r)
+	}
+	if len(resolved) == 0 {
+		return
+	}
+
+	// only the firing states are turned into alerts
+	alerts := FromAlertStateToPostableAlerts(resolved)
+	for _, s := range resolved {
+		s.State = eval.Normal
+		s.StartsAt = now
+		s.Error = nil
+		s.KeepFiringSince = time.Time{}
+	}
+	st.Put(resolved)
+	sch.saveAlertStates(resolved)
+
+	if len(alerts.PostableAlerts) > 0 {
+		sch.log.Debug("resolving alerts of paused alert rule", "uid", alertRule.UID, "count", len(alerts.PostableAlerts))
+		if err := sch.sendAlerts(alerts); err != nil {
+			sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
+		}
+	}
+}
+
 func (sch *schedule) WarmStateCache(st *state.Manager) {
 	sch.log.Info("warming cache for startup")
 	st.ResetCache()
+	if sch.sharding {
+		sch.log.Info("alert rule states are loaded when the instance takes ownership of the rules")
+		return
+	}
 
 	orgIds, err := sch.instanceStore.FetchOrgIds()
 	if err != nil {
@@ -395,29 +589,40 @@ func (sch *schedule) WarmStateCache(st *state.Manager) {
 				continue
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				sch.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &state.State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []state.Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, sch.stateFromInstance(ruleForEntry, entry))
 		}
 	}
 	st.Put(states)
 }
 
+func (sch *schedule) stateFromInstance(rule *models.AlertRule, entry *models.ListAlertInstancesQueryResult) *state.State {
+	lbs := map[string]string(entry.Labels)
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		sch.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	// the annotations are restored as expanded by the last evaluation, unless they weren't saved yet
+	annotations := entry.Annotations
+	if annotations == nil {
+		annotations = state.ExpandAnnotations(sch.log, rule, lbs, entry.LastEvalTime)
+	}
+	return &state.State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheId:            cacheId,
+		Labels:             lbs,
+		State:              translateInstanceState(entry.CurrentState),
+		Results:            []state.Evaluation{},
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        annotations,
+		KeepFiringSince:    entry.KeepFiringSince,
+		Flapping:           entry.Flapping,
+		StateChanges:       entry.StateChanges,
+	}
+}
+
 func translateInstanceState(state models.InstanceStateType) eval.State {
 	switch {
 	case state == models.InstanceStateFiring:
@@ -512,4 +717,12 @@ type alertRuleInfo struct {
 type evalContext struct {
 	now     time.Time
 	version int64
+	// done is closed when the evaluation is over, if set.
+	done chan struct{}
+}
+
+type readyToRunItem struct {
+	key      models.AlertRuleKey
+	rule     *models.AlertRule
+	ruleInfo alertRuleInfo
 }
@@ -2,6 +2,7 @@ package schedule_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime"
 	"strings"
@@ -11,10 +12,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/tests"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/internal/services/ngalert/state"
 
@@ -69,7 +73,7 @@ func TestWarmStateCache(t *testing.T) {
 			StartsAt:           evaluationTime.Add(-1 * time.Minute),
 			EndsAt:             evaluationTime.Add(1 * time.Minute),
 			LastEvaluationTime: evaluationTime,
-			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
+			Annotations:        map[string]string{"testAnnoKey": "expanded testAnnoValue"},
 		},
 	}
 
@@ -93,6 +97,7 @@ func TestWarmStateCache(t *testing.T) {
 		LastEvalTime:      evaluationTime,
 		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		Annotations:       map[string]string{"testAnnoKey": "expanded testAnnoValue"},
 	}
 	_ = dbstore.SaveAlertInstance(saveCmd2)
 
@@ -108,7 +113,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
 	}
 	sched := schedule.NewScheduler(schedCfg, nil)
-	st := state.NewManager(schedCfg.Logger, nilMetrics)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, state.FlapDetection{})
 	sched.WarmStateCache(st)
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -159,7 +164,7 @@ func TestAlertingTicker(t *testing.T) {
 
 	ctx := context.Background()
 
-	st := state.NewManager(schedCfg.Logger, nilMetrics)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, state.FlapDetection{})
 	go func() {
 		err := sched.Ticker(ctx, st)
 		require.NoError(t, err)
@@ -225,6 +230,189 @@ func TestAlertingTicker(t *testing.T) {
 	})
 }
 
+type fakeNotifier struct {
+	alerts chan apimodels.PostableAlerts
+}
+
+func (n *fakeNotifier) PutAlerts(alerts apimodels.PostableAlerts) error {
+	n.alerts <- alerts
+	return nil
+}
+
+func TestPausedAlertRule(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, 1)
+	t.Cleanup(registry.ClearOverrides)
+
+	rule := tests.CreateTestAlertRule(t, dbstore, 1)
+	err := dbstore.SaveAlertInstance(&models.SaveAlertInstanceCommand{
+		RuleOrgID:         rule.OrgID,
+		RuleUID:           rule.UID,
+		Labels:            models.InstanceLabels{"test": "testValue"},
+		State:             models.InstanceStateFiring,
+		LastEvalTime:      time.Unix(0, 0),
+		CurrentStateSince: time.Unix(0, 0),
+	})
+	require.NoError(t, err)
+
+	setPaused := func(t *testing.T, paused bool) {
+		t.Helper()
+		err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        rule.OrgID,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     rule.RuleGroup,
+				Interval: model.Duration(time.Second),
+				Paused:   paused,
+				Rules: []apimodels.PostableExtendedRuleNode{
+					{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: rule.UID}},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	evalAppliedCh := make(chan evalAppliedInfo, 1)
+	stopAppliedCh := make(chan models.AlertRuleKey, 1)
+	notifier := &fakeNotifier{alerts: make(chan apimodels.PostableAlerts, 1)}
+	mockedClock := clock.NewMock()
+
+	schedCfg := schedule.SchedulerCfg{
+		C:            mockedClock,
+		BaseInterval: time.Second,
+		EvalAppliedFunc: func(alertDefKey models.AlertRuleKey, now time.Time) {
+			evalAppliedCh <- evalAppliedInfo{alertDefKey: alertDefKey, now: now}
+		},
+		StopAppliedFunc: func(alertDefKey models.AlertRuleKey) {
+			stopAppliedCh <- alertDefKey
+		},
+		RuleStore:     dbstore,
+		InstanceStore: dbstore,
+		Notifier:      notifier,
+		Logger:        log.New("ngalert schedule test"),
+		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
+	}
+	sched := schedule.NewScheduler(schedCfg, nil)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, state.FlapDetection{})
+	sched.WarmStateCache(st)
+	// the states stay in the cache when the rule group is paused
+	setPaused(t, true)
+
+	go func() {
+		err := sched.Ticker(context.Background(), st)
+		require.NoError(t, err)
+	}()
+	runtime.Gosched()
+
+	t.Run("paused alert rules are not evaluated and their alerts are resolved", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+
+		select {
+		case alerts := <-notifier.alerts:
+			require.Len(t, alerts.PostableAlerts, 1)
+			assert.Equal(t, "testValue", alerts.PostableAlerts[0].Labels["test"])
+			assert.Equal(t, tick, time.Time(alerts.PostableAlerts[0].EndsAt))
+		case <-time.After(time.Second):
+			t.Fatal("alerts were not resolved")
+		}
+
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		assert.Equal(t, eval.Normal, states[0].State)
+
+		q := &models.GetAlertInstanceQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID, Labels: models.InstanceLabels{"test": "testValue"}}
+		require.NoError(t, dbstore.GetAlertInstance(q))
+		assert.Equal(t, models.InstanceStateNormal, q.Result.CurrentState)
+	})
+
+	t.Run("resumed alert rules are evaluated", func(t *testing.T) {
+		setPaused(t, false)
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, rule.GetKey())
+	})
+
+	t.Run("the routines of paused alert rules are stopped", func(t *testing.T) {
+		setPaused(t, true)
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+		assertStopRun(t, stopAppliedCh, rule.GetKey())
+	})
+}
+
+func TestSequentialRuleGroup(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, 1)
+	t.Cleanup(registry.ClearOverrides)
+
+	expression := func(refID, text string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(fmt.Sprintf(`{"type": "math", "expression": %q}`, text)),
+		}
+	}
+	ruleGroup := apimodels.PostableRuleGroupConfig{
+		Name:       "sequential",
+		Interval:   model.Duration(time.Second),
+		Sequential: true,
+		Rules: []apimodels.PostableExtendedRuleNode{
+			{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     "service down",
+				Condition: "A",
+				Data:      []models.AlertQuery{expression("A", "1 > 0")},
+			}},
+			{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     "high latency",
+				Condition: "C",
+				Data: []models.AlertQuery{
+					{RefID: "B", DatasourceUID: models.RuleStateDatasourceUID, Model: json.RawMessage(`{"rule": "service down"}`)},
+					expression("C", "$B == 0"),
+				},
+			}},
+		},
+	}
+	require.NoError(t, dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{OrgID: 1, NamespaceUID: "namespace", RuleGroupConfig: ruleGroup}))
+	q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: ruleGroup.Name}
+	require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+	require.Len(t, q.Result, 2)
+	serviceDown, highLatency := q.Result[0], q.Result[1]
+	require.Equal(t, "service down", serviceDown.Title)
+	require.True(t, highLatency.RuleGroupSequential)
+
+	evalAppliedCh := make(chan evalAppliedInfo, 1)
+	mockedClock := clock.NewMock()
+	schedCfg := schedule.SchedulerCfg{
+		C:            mockedClock,
+		BaseInterval: time.Second,
+		EvalAppliedFunc: func(alertDefKey models.AlertRuleKey, now time.Time) {
+			evalAppliedCh <- evalAppliedInfo{alertDefKey: alertDefKey, now: now}
+		},
+		RuleStore:     dbstore,
+		InstanceStore: dbstore,
+		Notifier:      &fakeNotifier{alerts: make(chan apimodels.PostableAlerts, 10)},
+		Logger:        log.New("ngalert schedule test"),
+		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
+	}
+	sched := schedule.NewScheduler(schedCfg, nil)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, state.FlapDetection{})
+
+	go func() {
+		err := sched.Ticker(context.Background(), st)
+		require.NoError(t, err)
+	}()
+	runtime.Gosched()
+
+	tick := advanceClock(t, mockedClock)
+	for _, rule := range []*models.AlertRule{serviceDown, highLatency} {
+		select {
+		case info := <-evalAppliedCh:
+			assert.Equal(t, rule.GetKey(), info.alertDefKey, "the rules are evaluated in order")
+			assert.Equal(t, tick, info.now)
+		case <-time.After(time.Second):
+			t.Fatal("cycle has expired")
+		}
+	}
+}
+
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
 	timeout := time.After(time.Second)
 
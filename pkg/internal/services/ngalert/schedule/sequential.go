@@ -0,0 +1,108 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/state"
+)
+
+// ruleGroupKey identifies a rule group.
+type ruleGroupKey struct {
+	orgID        int64
+	namespaceUID string
+	ruleGroup    string
+}
+
+func groupKeyOf(rule *models.AlertRule) ruleGroupKey {
+	return ruleGroupKey{orgID: rule.OrgID, namespaceUID: rule.NamespaceUID, ruleGroup: rule.RuleGroup}
+}
+
+// evalSequentially evaluates the rules of a sequential rule group one after the other, in order. The remaining
+// rules are skipped if the group is not evaluated before its next evaluation.
+func (sch *schedule) evalSequentially(ctx context.Context, tick time.Time, items []readyToRunItem) {
+	if len(items) == 0 {
+		return
+	}
+	timeout := time.After(time.Duration(items[0].rule.IntervalSeconds) * time.Second)
+	for _, item := range items {
+		done := make(chan struct{})
+		select {
+		case item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version, done: done}:
+		case <-timeout:
+			sch.log.Warn("rule group evaluation skipped the remaining rules: the next evaluation is due", "key", item.key, "rule group", item.rule.RuleGroup)
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-done:
+		case <-timeout:
+			sch.log.Warn("rule group evaluation skipped the remaining rules: the next evaluation is due", "key", item.key, "rule group", item.rule.RuleGroup)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resolveRuleStates returns the queries and expressions of an alert rule, with the rule state queries replaced by
+// math expressions returning the number of firing alerts of the rules they query.
+func (sch *schedule) resolveRuleStates(st *state.Manager, alertRule *models.AlertRule) ([]models.AlertQuery, error) {
+	var uids map[string]string
+	data := make([]models.AlertQuery, 0, len(alertRule.Data))
+	for _, q := range alertRule.Data {
+		if !q.IsRuleState() {
+			data = append(data, q)
+			continue
+		}
+
+		if uids == nil {
+			groupQuery := models.ListRuleGroupAlertRulesQuery{
+				OrgID:        alertRule.OrgID,
+				NamespaceUID: alertRule.NamespaceUID,
+				RuleGroup:    alertRule.RuleGroup,
+			}
+			if err := sch.ruleStore.GetRuleGroupAlertRules(&groupQuery); err != nil {
+				return nil, err
+			}
+			uids = make(map[string]string, len(groupQuery.Result))
+			for _, r := range groupQuery.Result {
+				uids[r.Title] = r.UID
+			}
+		}
+
+		title, err := q.GetRuleStateTitle()
+		if err != nil {
+			return nil, err
+		}
+		uid, ok := uids[title]
+		if !ok {
+			return nil, fmt.Errorf("rule %s queried by %s is not found in the rule group", title, q.RefID)
+		}
+
+		firing := 0
+		for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, uid) {
+			if s.State == eval.Alerting {
+				firing++
+			}
+		}
+		model, err := json.Marshal(map[string]interface{}{
+			"refId":      q.RefID,
+			"type":       "math",
+			"expression": strconv.Itoa(firing),
+		})
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, models.AlertQuery{RefID: q.RefID, DatasourceUID: expr.DatasourceUID, Model: model})
+	}
+	return data, nil
+}
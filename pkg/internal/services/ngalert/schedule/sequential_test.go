@@ -0,0 +1,59 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/expr"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+)
+
+type fakeRuleGroupStore struct {
+	store.RuleStore
+	rules []*models.AlertRule
+}
+
+func (f *fakeRuleGroupStore) GetRuleGroupAlertRules(query *models.ListRuleGroupAlertRulesQuery) error {
+	query.Result = f.rules
+	return nil
+}
+
+func TestResolveRuleStates(t *testing.T) {
+	serviceDown := &models.AlertRule{OrgID: 1, UID: "down", Title: "service down"}
+	highLatency := &models.AlertRule{OrgID: 1, UID: "latency", Title: "high latency", Data: []models.AlertQuery{
+		{RefID: "A", DatasourceUID: "prometheus", Model: []byte(`{"expr": "latency"}`)},
+		{RefID: "B", DatasourceUID: models.RuleStateDatasourceUID, Model: []byte(`{"rule": "service down"}`)},
+	}}
+	sch := &schedule{ruleStore: &fakeRuleGroupStore{rules: []*models.AlertRule{serviceDown, highLatency}}}
+	st := state.NewManager(log.New("test"), metrics.NewMetrics(nil), state.FlapDetection{})
+	st.Put([]*state.State{
+		{OrgID: 1, AlertRuleUID: "down", CacheId: "1", State: eval.Alerting},
+		{OrgID: 1, AlertRuleUID: "down", CacheId: "2", State: eval.Alerting},
+		{OrgID: 1, AlertRuleUID: "down", CacheId: "3", State: eval.Normal},
+	})
+
+	t.Run("rule state queries return the number of firing alerts", func(t *testing.T) {
+		data, err := sch.resolveRuleStates(st, highLatency)
+		require.NoError(t, err)
+		require.Len(t, data, 2)
+		assert.Equal(t, highLatency.Data[0], data[0])
+		assert.Equal(t, "B", data[1].RefID)
+		assert.Equal(t, expr.DatasourceUID, data[1].DatasourceUID)
+		assert.JSONEq(t, `{"refId": "B", "type": "math", "expression": "2"}`, string(data[1].Model))
+	})
+
+	t.Run("the queried rule must be in the rule group", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 1, Data: []models.AlertQuery{
+			{RefID: "B", DatasourceUID: models.RuleStateDatasourceUID, Model: []byte(`{"rule": "unknown"}`)},
+		}}
+		_, err := sch.resolveRuleStates(st, rule)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,182 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/state"
+)
+
+// ownerOf returns the instance owning an alert rule, using rendezvous hashing: the rule is owned by the
+// instance with the highest hash of the instance and the rule. When an instance joins or leaves, only the
+// rules it gains or owned are reassigned.
+func ownerOf(key models.AlertRuleKey, instances []string) string {
+	var owner string
+	var highest uint64
+	for _, instance := range instances {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(instance))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(strconv.FormatInt(key.OrgID, 10)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key.UID))
+		if sum := mix(h.Sum64()); owner == "" || sum > highest {
+			owner, highest = instance, sum
+		}
+	}
+	return owner
+}
+
+// shardingKey returns the key used for assigning an alert rule to an instance. The rules of a sequential rule
+// group share the key of the group, so that they are evaluated in order by the same instance.
+func shardingKey(rule *models.AlertRule) models.AlertRuleKey {
+	if rule.RuleGroupSequential {
+		return models.AlertRuleKey{OrgID: rule.OrgID, UID: rule.NamespaceUID + "/" + rule.RuleGroup}
+	}
+	return rule.GetKey()
+}
+
+// mix is the finalizer of SplitMix64. FNV alone barely spreads the hashes of inputs sharing a suffix.
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// saveHeartbeat records that this instance is alive, deletes the instances that stopped sending heartbeats, and
+// returns the identifiers of the live instances.
+func (sch *schedule) saveHeartbeat(now time.Time) ([]string, error) {
+	err := sch.schedulerStore.SaveSchedulerHeartbeat(&models.SaveSchedulerHeartbeatCommand{InstanceID: sch.instanceID, Time: now})
+	if err != nil {
+		return nil, err
+	}
+
+	err = sch.schedulerStore.DeleteSchedulerInstances(&models.DeleteSchedulerInstancesCommand{Before: now.Add(-sch.instanceTimeout)})
+	if err != nil {
+		sch.log.Warn("failed to delete stopped scheduler instances", "err", err)
+	}
+
+	instances, err := sch.liveInstances(now)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.InstanceID)
+	}
+	return ids, nil
+}
+
+func (sch *schedule) liveInstances(now time.Time) ([]*models.SchedulerInstance, error) {
+	q := models.ListSchedulerInstancesQuery{Since: now.Add(-sch.instanceTimeout)}
+	if err := sch.schedulerStore.ListSchedulerInstances(&q); err != nil {
+		return nil, err
+	}
+	return q.Result, nil
+}
+
+// ownedRules returns the alert rules owned by this instance. If the live instances cannot be determined,
+// the instance keeps evaluating the rules it owns.
+//
+// The rules gained from another instance are only returned one interval after they are first found to be
+// owned: the previous owner releases them on its next tick, and the states it saves then must not be loaded
+// before.
+func (sch *schedule) ownedRules(alertRules []*models.AlertRule) []*models.AlertRule {
+	now := sch.clock.Now()
+	instances, err := sch.saveHeartbeat(now)
+	if err != nil {
+		sch.log.Error("failed to update scheduler instances, keeping the alert rules owned", "err", err)
+	}
+
+	owned := make([]*models.AlertRule, 0, len(alertRules))
+	gained := make(map[models.AlertRuleKey]time.Time)
+	for _, rule := range alertRules {
+		key := rule.GetKey()
+		evaluated := sch.registry.exists(key)
+		owns := evaluated
+		if err == nil {
+			owns = ownerOf(shardingKey(rule), instances) == sch.instanceID
+		}
+		if owns && !evaluated {
+			gainedAt, ok := sch.gainedRules[key]
+			if !ok {
+				gainedAt = now
+			}
+			if now.Sub(gainedAt) < sch.baseInterval {
+				gained[key] = gainedAt
+				continue
+			}
+		}
+		if owns {
+			owned = append(owned, rule)
+		}
+	}
+	sch.gainedRules = gained
+	sch.log.Debug("alert rules owned", "count", len(owned), "gained", len(gained), "instances", len(instances))
+	return owned
+}
+
+// loadRuleStates replaces the states of an alert rule with the states saved by the instance that owned it.
+func (sch *schedule) loadRuleStates(st *state.Manager, rule *models.AlertRule) {
+	cmd := models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
+	if err := sch.instanceStore.ListAlertInstances(&cmd); err != nil {
+		sch.log.Error("unable to fetch previous state", "uid", rule.UID, "msg", err.Error())
+		return
+	}
+
+	states := make([]*state.State, 0, len(cmd.Result))
+	for _, entry := range cmd.Result {
+		states = append(states, sch.stateFromInstance(rule, entry))
+	}
+	st.RemoveByRuleUID(rule.OrgID, rule.UID)
+	st.Put(states)
+}
+
+// Status returns the live scheduler instances and the instance owning each alert rule. Without sharding,
+// every instance evaluates every rule.
+func (sch *schedule) Status() (*apimodels.SchedulerStatus, error) {
+	status := &apimodels.SchedulerStatus{
+		InstanceID: sch.instanceID,
+		Sharding:   sch.sharding,
+		Instances:  []apimodels.SchedulerInstanceStatus{},
+		Rules:      []apimodels.SchedulerRuleStatus{},
+	}
+
+	ids := []string{sch.instanceID}
+	if sch.sharding {
+		instances, err := sch.liveInstances(sch.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		ids = ids[:0]
+		for _, instance := range instances {
+			ids = append(ids, instance.InstanceID)
+			status.Instances = append(status.Instances, apimodels.SchedulerInstanceStatus{
+				InstanceID:    instance.InstanceID,
+				LastHeartbeat: time.Unix(instance.LastHeartbeat, 0),
+			})
+		}
+	} else {
+		status.Instances = append(status.Instances, apimodels.SchedulerInstanceStatus{InstanceID: sch.instanceID})
+	}
+
+	q := models.ListAlertRulesQuery{}
+	if err := sch.ruleStore.GetAlertRulesForScheduling(&q); err != nil {
+		return nil, err
+	}
+	for _, rule := range q.Result {
+		status.Rules = append(status.Rules, apimodels.SchedulerRuleStatus{
+			OrgID: rule.OrgID,
+			UID:   rule.UID,
+			Title: rule.Title,
+			Owner: ownerOf(shardingKey(rule), ids),
+		})
+	}
+	return status, nil
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+)
+
+func TestOwnerOf(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 300)
+	for i := 0; i < 300; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("no instance owns no rule", func(t *testing.T) {
+		assert.Empty(t, ownerOf(keys[0], nil))
+	})
+
+	t.Run("rules are distributed between the instances", func(t *testing.T) {
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ownerOf(key, []string{"a", "b", "c"})]++
+		}
+		require.Len(t, counts, 3)
+		for instance, count := range counts {
+			assert.Greater(t, count, 50, "instance %s owns too few rules", instance)
+		}
+	})
+
+	t.Run("the owner does not depend on the order of the instances", func(t *testing.T) {
+		for _, key := range keys {
+			assert.Equal(t, ownerOf(key, []string{"a", "b", "c"}), ownerOf(key, []string{"c", "a", "b"}))
+		}
+	})
+
+	t.Run("only the rules of a stopped instance are reassigned", func(t *testing.T) {
+		for _, key := range keys {
+			before := ownerOf(key, []string{"a", "b", "c"})
+			after := ownerOf(key, []string{"a", "c"})
+			if before != "b" {
+				assert.Equal(t, before, after)
+			} else {
+				assert.Contains(t, []string{"a", "c"}, after)
+			}
+		}
+	})
+
+	t.Run("the rules of a sequential rule group have the same owner", func(t *testing.T) {
+		owners := map[string]struct{}{}
+		for i := 0; i < 20; i++ {
+			rule := &models.AlertRule{OrgID: 1, UID: fmt.Sprintf("rule-%d", i), NamespaceUID: "namespace", RuleGroup: "group", RuleGroupSequential: true}
+			owners[ownerOf(shardingKey(rule), []string{"a", "b", "c"})] = struct{}{}
+		}
+		assert.Len(t, owners, 1)
+	})
+}
+
+func TestOwnedRules(t *testing.T) {
+	mockedClock := clock.NewMock()
+	schedulerStore := &fakeSchedulerStore{heartbeats: map[string]time.Time{}}
+	sch := &schedule{
+		clock:           mockedClock,
+		baseInterval:    10 * time.Second,
+		log:             log.New("test"),
+		registry:        alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
+		sharding:        true,
+		instanceID:      "a",
+		instanceTimeout: time.Minute,
+		schedulerStore:  schedulerStore,
+	}
+	rule := &models.AlertRule{OrgID: 1, UID: "rule"}
+
+	t.Run("rules gained are owned one interval later", func(t *testing.T) {
+		require.Empty(t, sch.ownedRules([]*models.AlertRule{rule}))
+		mockedClock.Add(5 * time.Second)
+		require.Empty(t, sch.ownedRules([]*models.AlertRule{rule}))
+		mockedClock.Add(5 * time.Second)
+		require.Equal(t, []*models.AlertRule{rule}, sch.ownedRules([]*models.AlertRule{rule}))
+	})
+
+	t.Run("rules evaluated are owned until another instance gains them", func(t *testing.T) {
+		sch.registry.getOrCreateInfo(rule.GetKey(), rule.Version)
+		require.Equal(t, []*models.AlertRule{rule}, sch.ownedRules([]*models.AlertRule{rule}))
+
+		other := ""
+		for i := 0; other == "" || ownerOf(rule.GetKey(), []string{"a", other}) == "a"; i++ {
+			other = fmt.Sprintf("instance-%d", i)
+		}
+		schedulerStore.heartbeats[other] = mockedClock.Now()
+		require.Empty(t, sch.ownedRules([]*models.AlertRule{rule}))
+	})
+}
+
+type fakeSchedulerStore struct {
+	heartbeats map[string]time.Time
+}
+
+func (f *fakeSchedulerStore) SaveSchedulerHeartbeat(cmd *models.SaveSchedulerHeartbeatCommand) error {
+	f.heartbeats[cmd.InstanceID] = cmd.Time
+	return nil
+}
+
+func (f *fakeSchedulerStore) ListSchedulerInstances(query *models.ListSchedulerInstancesQuery) error {
+	query.Result = nil
+	for id, heartbeat := range f.heartbeats {
+		if !heartbeat.Before(query.Since) {
+			query.Result = append(query.Result, &models.SchedulerInstance{InstanceID: id, LastHeartbeat: heartbeat.Unix()})
+		}
+	}
+	return nil
+}
+
+func (f *fakeSchedulerStore) DeleteSchedulerInstances(cmd *models.DeleteSchedulerInstancesCommand) error {
+	return nil
+}
@@ -34,7 +34,7 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	defer c.mtxStates.Unlock()
 
 	// if duplicate labels exist, alertRule label will take precedence
-	lbs := mergeLabels(alertRule.Labels, result.Instance)
+	lbs := mergeLabels(expandTemplates(c.log, alertRule.Labels, result.Instance, result), result.Instance)
 	lbs[ngModels.UIDLabel] = alertRule.UID
 	lbs[ngModels.NamespaceUIDLabel] = alertRule.NamespaceUID
 	lbs[prometheusModel.AlertNameLabel] = alertRule.Title
@@ -11,18 +11,20 @@ import (
 )
 
 type Manager struct {
-	cache   *cache
-	quit    chan struct{}
-	Log     log.Logger
-	metrics *metrics.Metrics
+	cache         *cache
+	quit          chan struct{}
+	Log           log.Logger
+	metrics       *metrics.Metrics
+	flapDetection FlapDetection
 }
 
-func NewManager(logger log.Logger, metrics *metrics.Metrics) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.Metrics, flapDetection FlapDetection) *Manager {
 	manager := &Manager{
-		cache:   newCache(logger, metrics),
-		quit:    make(chan struct{}),
-		Log:     logger,
-		metrics: metrics,
+		cache:         newCache(logger, metrics),
+		quit:          make(chan struct{}),
+		Log:           logger,
+		metrics:       metrics,
+		flapDetection: flapDetection,
 	}
 	go manager.recordMetrics()
 	return manager
@@ -78,11 +80,20 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 		EvaluationString: result.EvaluationString,
 	})
 	currentState.TrimResults(alertRule)
+	// the annotations are expanded with the values of every evaluation
+	currentState.Annotations = expandTemplates(st.Log, alertRule.Annotations, currentState.Labels, result)
 
 	st.Log.Debug("setting alert state", "uid", alertRule.UID)
+	previousState := currentState.State
+	keepFiring := result.State == eval.Normal && currentState.keepFiring(alertRule, result)
+	if !keepFiring {
+		currentState.KeepFiringSince = time.Time{}
+	}
 	switch result.State {
 	case eval.Normal:
-		currentState = resultNormal(currentState, result)
+		if !keepFiring {
+			currentState = resultNormal(currentState, result)
+		}
 	case eval.Alerting:
 		currentState = currentState.resultAlerting(alertRule, result)
 	case eval.Error:
@@ -91,6 +102,7 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 		currentState = currentState.resultNoData(alertRule, result)
 	case eval.Pending: // we do not emit results with this state
 	}
+	currentState.detectFlapping(previousState, result.EvaluatedAt, st.flapDetection)
 
 	st.set(currentState)
 	return currentState
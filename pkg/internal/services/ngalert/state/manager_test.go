@@ -716,7 +716,7 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), nilMetrics)
+		st := state.NewManager(log.New("test_state_manager"), nilMetrics, state.FlapDetection{})
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(tc.alertRule, res)
@@ -729,3 +729,127 @@ func TestProcessEvalResults(t *testing.T) {
 		})
 	}
 }
+
+func TestKeepFiring(t *testing.T) {
+	evaluationTime := time.Unix(0, 0)
+	rule := &models.AlertRule{OrgID: 1, UID: "test_alert_rule_uid", IntervalSeconds: 10, KeepFiringFor: 30 * time.Second}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, state.FlapDetection{})
+
+	process := func(offset time.Duration, s eval.State) *state.State {
+		states := st.ProcessEvalResults(rule, eval.Results{{State: s, EvaluatedAt: evaluationTime.Add(offset)}})
+		require.Len(t, states, 1)
+		return states[0]
+	}
+
+	process(0, eval.Alerting)
+	s := process(10*time.Second, eval.Normal)
+	assert.Equal(t, eval.Alerting, s.State)
+	assert.Equal(t, evaluationTime.Add(10*time.Second), s.KeepFiringSince)
+	assert.Equal(t, evaluationTime.Add(30*time.Second), s.EndsAt)
+
+	s = process(20*time.Second, eval.Alerting)
+	assert.Equal(t, eval.Alerting, s.State)
+	assert.True(t, s.KeepFiringSince.IsZero(), "the condition met again resets the keep firing duration")
+
+	for _, offset := range []time.Duration{30, 40, 50} {
+		s = process(offset*time.Second, eval.Normal)
+		assert.Equal(t, eval.Alerting, s.State)
+	}
+	s = process(60*time.Second, eval.Normal)
+	assert.Equal(t, eval.Normal, s.State)
+	assert.Equal(t, evaluationTime.Add(60*time.Second), s.EndsAt)
+	assert.True(t, s.KeepFiringSince.IsZero())
+}
+
+func TestFlapDetection(t *testing.T) {
+	evaluationTime := time.Unix(0, 0)
+	annotations := map[string]string{"summary": "flaky"}
+	rule := &models.AlertRule{OrgID: 1, UID: "test_alert_rule_uid", IntervalSeconds: 10, Annotations: annotations}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, state.FlapDetection{Window: time.Minute, Threshold: 3})
+
+	process := func(offset time.Duration, s eval.State) *state.State {
+		states := st.ProcessEvalResults(rule, eval.Results{{State: s, EvaluatedAt: evaluationTime.Add(offset)}})
+		require.Len(t, states, 1)
+		return states[0]
+	}
+
+	process(0, eval.Normal)
+	for i, s := range []eval.State{eval.Alerting, eval.Normal, eval.Alerting} {
+		flapping := process(time.Duration(i+1)*10*time.Second, s)
+		assert.False(t, flapping.Flapping)
+		assert.NotContains(t, flapping.Annotations, state.FlappingAnnotation)
+	}
+
+	s := process(40*time.Second, eval.Normal)
+	assert.True(t, s.Flapping)
+	assert.Len(t, s.StateChanges, 4)
+	assert.Equal(t, map[string]string{"summary": "flaky", state.FlappingAnnotation: "true"}, s.Annotations)
+	assert.Equal(t, map[string]string{"summary": "flaky"}, annotations, "the annotations of the rule are not modified")
+
+	s = process(50*time.Second, eval.Normal)
+	assert.True(t, s.Flapping, "the alert is flapping while the state changes are within the window")
+
+	s = process(80*time.Second, eval.Normal)
+	assert.False(t, s.Flapping)
+	assert.Len(t, s.StateChanges, 2)
+	assert.Equal(t, map[string]string{"summary": "flaky"}, s.Annotations)
+}
+
+func TestTemplates(t *testing.T) {
+	evaluationTime := time.Unix(0, 0)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "cpu",
+		UID:             "test_alert_rule_uid",
+		IntervalSeconds: 10,
+		Labels:          map[string]string{"severity": `{{ if gt $values.B.Value 0.9 }}critical{{ else }}warning{{ end }}`},
+		Annotations: map[string]string{
+			"summary": `CPU at {{ humanizePercentage $values.B.Value }} on {{ $labels.instance }} ({{ $values.B }})`,
+			"static":  "no template",
+			"invalid": "{{ $values.B.Value | nonexistent }}",
+		},
+	}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, state.FlapDetection{})
+
+	states := st.ProcessEvalResults(rule, eval.Results{
+		{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Labels: data.Labels{"instance": "a"}, Value: 0.95}},
+		},
+		{
+			Instance:    data.Labels{"instance": "b"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Labels: data.Labels{"instance": "b"}, Value: 0.5}},
+		},
+	})
+	require.Len(t, states, 2)
+
+	assert.Equal(t, "critical", states[0].Labels["severity"])
+	assert.Equal(t, "CPU at 95% on a (0.95)", states[0].Annotations["summary"])
+	assert.Equal(t, "no template", states[0].Annotations["static"])
+	assert.Contains(t, states[0].Annotations["invalid"], "<error expanding template")
+	assert.Equal(t, "warning", states[1].Labels["severity"])
+	assert.Equal(t, "CPU at 50% on b (0.5)", states[1].Annotations["summary"])
+
+	t.Run("annotations are expanded with the values of every evaluation", func(t *testing.T) {
+		states := st.ProcessEvalResults(rule, eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime.Add(10 * time.Second),
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Labels: data.Labels{"instance": "a"}, Value: 0.99}},
+		}})
+		require.Len(t, states, 1)
+		assert.Len(t, st.GetStatesForRuleUID(1, rule.UID), 2, "the labels of the instance are unchanged")
+		assert.Equal(t, "CPU at 99% on a (0.99)", states[0].Annotations["summary"])
+	})
+
+	t.Run("annotations of restored instances are expanded with their labels", func(t *testing.T) {
+		annotations := state.ExpandAnnotations(log.New("test_state_manager"), rule, map[string]string{"instance": "c"},
+			evaluationTime)
+		assert.Contains(t, annotations["summary"], " on c ")
+		assert.Equal(t, "no template", annotations["static"])
+	})
+}
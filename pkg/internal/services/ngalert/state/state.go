@@ -21,6 +21,22 @@ type State struct {
 	EvaluationDuration time.Duration
 	Annotations        map[string]string
 	Error              error
+	// KeepFiringSince is the first evaluation not meeting the condition of an alert kept firing.
+	KeepFiringSince time.Time
+	// Flapping is set while the state changed more times than the threshold of the flap detection. The
+	// changes within the window of the flap detection are listed in StateChanges.
+	Flapping     bool
+	StateChanges []time.Time
+}
+
+// FlappingAnnotation is the annotation added to flapping alerts.
+const FlappingAnnotation = "flapping"
+
+// FlapDetection marks the alerts changing state more than Threshold times within Window as flapping. The
+// detection is disabled if Threshold is zero.
+type FlapDetection struct {
+	Window    time.Duration
+	Threshold int
 }
 
 type Evaluation struct {
@@ -40,6 +56,62 @@ func resultNormal(alertState *State, result eval.Result) *State {
 	return newState
 }
 
+// keepFiring returns true if a firing alert keeps firing after an evaluation not meeting the condition. The
+// alert keeps firing until the condition has not been met for the KeepFiringFor of the rule.
+func (a *State) keepFiring(alertRule *ngModels.AlertRule, result eval.Result) bool {
+	if a.State != eval.Alerting || alertRule.KeepFiringFor <= 0 {
+		return false
+	}
+	if a.KeepFiringSince.IsZero() {
+		a.KeepFiringSince = result.EvaluatedAt
+	}
+	if result.EvaluatedAt.Sub(a.KeepFiringSince) >= alertRule.KeepFiringFor {
+		return false
+	}
+	a.EndsAt = result.EvaluatedAt.Add(time.Duration(alertRule.IntervalSeconds*2) * time.Second)
+	return true
+}
+
+// detectFlapping records the state changes within the window of the flap detection, and sets Flapping
+// and the flapping annotation while there are more changes than the threshold.
+func (a *State) detectFlapping(previous eval.State, now time.Time, fd FlapDetection) {
+	if fd.Threshold <= 0 {
+		a.StateChanges = nil
+		a.setFlapping(false)
+		return
+	}
+
+	if a.State != previous {
+		a.StateChanges = append(a.StateChanges, now)
+	}
+	changes := a.StateChanges[:0]
+	for _, t := range a.StateChanges {
+		if now.Sub(t) < fd.Window {
+			changes = append(changes, t)
+		}
+	}
+	a.StateChanges = changes
+	a.setFlapping(len(a.StateChanges) > fd.Threshold)
+}
+
+func (a *State) setFlapping(flapping bool) {
+	a.Flapping = flapping
+	if _, ok := a.Annotations[FlappingAnnotation]; ok == flapping {
+		return
+	}
+	// the annotations can be shared with the alert rule and the other states
+	annotations := make(map[string]string, len(a.Annotations)+1)
+	for k, v := range a.Annotations {
+		annotations[k] = v
+	}
+	if flapping {
+		annotations[FlappingAnnotation] = "true"
+	} else {
+		delete(annotations, FlappingAnnotation)
+	}
+	a.Annotations = annotations
+}
+
 func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result) *State {
 	switch a.State {
 	case eval.Alerting:
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/template"
+
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/setting"
+)
+
+// templateData is the data of the templates of labels and annotations. In the templates, $labels are the
+// labels of the alert instance, $values the values of the queries and expressions by RefID, and $value the
+// evaluation string.
+type templateData struct {
+	Labels map[string]string
+	Values map[string]eval.NumberValueCapture
+	Value  string
+}
+
+const templateHeader = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}"
+
+// expandTemplate expands a template of a label or annotation with the functions of the templates of
+// Prometheus, such as humanize.
+func expandTemplate(name, text string, labels map[string]string, result eval.Result) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	externalURL, err := url.Parse(setting.AppUrl)
+	if err != nil {
+		externalURL = &url.URL{}
+	}
+	data := templateData{Labels: labels, Values: result.Values, Value: result.EvaluationString}
+	// there is no query function: queries cannot be run from the templates
+	expander := template.NewTemplateExpander(context.Background(), templateHeader+text, name, data,
+		model.TimeFromUnixNano(result.EvaluatedAt.UnixNano()), nil, externalURL)
+	return expander.Expand()
+}
+
+// ValidateTemplate returns an error if a template of a label or annotation cannot be parsed.
+func ValidateTemplate(name, text string) error {
+	expander := template.NewTemplateExpander(context.Background(), templateHeader+text, name, templateData{}, 0, nil, nil)
+	return expander.ParseTest()
+}
+
+// expandTemplates expands the templates of labels or annotations. The templates failing to expand are replaced
+// with the error.
+func expandTemplates(logger log.Logger, templates map[string]string, labels map[string]string, result eval.Result) map[string]string {
+	expanded := make(map[string]string, len(templates))
+	for k, text := range templates {
+		v, err := expandTemplate(k, text, labels, result)
+		if err != nil {
+			logger.Error("failed to expand template", "name", k, "err", err)
+			v = fmt.Sprintf("<error expanding template: %s>", err)
+		}
+		expanded[k] = v
+	}
+	return expanded
+}
+
+// ExpandAnnotations expands the annotations of an alert rule for an alert instance restored without the
+// annotations expanded by its last evaluation. The values of the evaluation aren't known, so $values and
+// $value are empty.
+func ExpandAnnotations(logger log.Logger, alertRule *ngModels.AlertRule, labels map[string]string, evaluatedAt time.Time) map[string]string {
+	if len(alertRule.Annotations) == 0 {
+		return alertRule.Annotations
+	}
+	return expandTemplates(logger, alertRule.Annotations, labels, eval.Result{EvaluatedAt: evaluatedAt})
+}
@@ -16,6 +16,7 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
 	"github.com/grafana/grafana/pkg/internal/util"
+	"github.com/prometheus/common/model"
 )
 
 // AlertRuleMaxTitleLength is the maximum length of the alert rule title
@@ -241,7 +242,8 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 					r.New.Title = r.Existing.Title
 				}
 
-				if r.New.Condition == "" {
+				// recording rules have no condition
+				if r.New.Condition == "" && r.New.Record == nil {
 					r.New.Condition = r.Existing.Condition
 				}
 
@@ -280,7 +282,8 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 				}
 
 				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).Update(r.New); err != nil {
+				// the flags, the record, the keep firing duration and the position in the group are updated even if they are unset
+				if _, err := sess.ID(r.Existing.ID).UseBool("is_paused", "rule_group_paused", "rule_group_sequential").MustCols("record", "keep_firing_for", "rule_group_index").Update(r.New); err != nil {
 					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
 				}
 
@@ -288,22 +291,28 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 			}
 
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:           r.New.OrgID,
+				RuleUID:             r.New.UID,
+				RuleNamespaceUID:    r.New.NamespaceUID,
+				RuleGroup:           r.New.RuleGroup,
+				ParentVersion:       parentVersion,
+				Version:             r.New.Version,
+				Created:             r.New.Updated,
+				Condition:           r.New.Condition,
+				Title:               r.New.Title,
+				Data:                r.New.Data,
+				IntervalSeconds:     r.New.IntervalSeconds,
+				NoDataState:         r.New.NoDataState,
+				ExecErrState:        r.New.ExecErrState,
+				For:                 r.New.For,
+				KeepFiringFor:       r.New.KeepFiringFor,
+				Annotations:         r.New.Annotations,
+				Labels:              r.New.Labels,
+				Record:              r.New.Record,
+				IsPaused:            r.New.IsPaused,
+				RuleGroupPaused:     r.New.RuleGroupPaused,
+				RuleGroupIndex:      r.New.RuleGroupIndex,
+				RuleGroupSequential: r.New.RuleGroupSequential,
 			})
 		}
 
@@ -327,7 +336,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 func (st DBstore) GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT * FROM alert_rule WHERE org_id = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? ORDER BY rule_group_index"
 		if err := sess.SQL(q, query.OrgID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -342,7 +351,7 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		// TODO rewrite using group by namespace_uid, rule_group
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? ORDER BY rule_group_index"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -357,7 +366,7 @@ func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ? ORDER BY rule_group_index"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
 			return err
 		}
@@ -368,9 +377,14 @@ func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRules
 }
 
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+// Only the top level folders are found by title, so the namespace is otherwise looked up by folder UID,
+// which also finds subfolders.
 func (st DBstore) GetNamespaceByTitle(namespace string, orgID int64, user *models.SignedInUser, withEdit bool) (*models.Folder, error) {
 	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
 	folder, err := s.GetFolderByTitle(namespace)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		folder, err = s.GetFolderByUID(namespace)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -401,7 +415,7 @@ func (st DBstore) GetNamespaceByUID(UID string, orgID int64, user *models.Signed
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, title, interval_seconds, version, namespace_uid, rule_group, is_paused, rule_group_paused, rule_group_index, rule_group_sequential FROM alert_rule"
 		if err := sess.SQL(q).Find(&alerts); err != nil {
 			return err
 		}
@@ -459,6 +473,15 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: no organisation is found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.Record != nil {
+		if !model.IsValidMetricName(model.LabelValue(alertRule.Record.Metric)) {
+			return fmt.Errorf("%w: invalid metric name %q", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record.Metric)
+		}
+		if alertRule.Condition != "" {
+			return fmt.Errorf("%w: recording rules cannot have a condition", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
 	return nil
 }
 
@@ -498,10 +521,17 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 				RuleGroup:       ruleGroup,
 				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
 				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				Record:          r.GrafanaManagedAlert.Record,
+				IsPaused:        r.GrafanaManagedAlert.IsPaused,
+				RuleGroupPaused: cmd.RuleGroupConfig.Paused,
+				// the rules are evaluated in the order of the group
+				RuleGroupIndex:      len(upsertRules),
+				RuleGroupSequential: cmd.RuleGroupConfig.Sequential,
 			}
 
 			if r.ApiRuleNode != nil {
 				new.For = time.Duration(r.ApiRuleNode.For)
+				new.KeepFiringFor = time.Duration(r.ApiRuleNode.KeepFiringFor)
 				new.Annotations = r.ApiRuleNode.Annotations
 				new.Labels = r.ApiRuleNode.Labels
 			}
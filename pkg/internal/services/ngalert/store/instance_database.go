@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -99,18 +100,32 @@ func (st DBstore) SaveAlertInstance(cmd *models.SaveAlertInstanceCommand) error
 			CurrentStateSince: cmd.CurrentStateSince,
 			CurrentStateEnd:   cmd.CurrentStateEnd,
 			LastEvalTime:      cmd.LastEvalTime,
+			KeepFiringSince:   cmd.KeepFiringSince,
+			Flapping:          cmd.Flapping,
+			StateChanges:      cmd.StateChanges,
+			Annotations:       cmd.Annotations,
 		}
 
 		if err := models.ValidateAlertInstance(alertInstance); err != nil {
 			return err
 		}
 
-		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+		stateChangesJSON, err := json.Marshal(alertInstance.StateChanges)
+		if err != nil {
+			return err
+		}
+		annotationsJSON, err := json.Marshal(alertInstance.Annotations)
+		if err != nil {
+			return err
+		}
+
+		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(),
+			alertInstance.KeepFiringSince.Unix(), alertInstance.Flapping, string(stateChangesJSON), string(annotationsJSON))
 
 		upsertSQL := st.SQLStore.Dialect.UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_state_since", "current_state_end", "last_eval_time"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_state_since", "current_state_end", "last_eval_time", "keep_firing_since", "flapping", "state_changes", "annotations"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
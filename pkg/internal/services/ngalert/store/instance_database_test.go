@@ -177,4 +177,72 @@ func TestAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, saveCmdTwo.Labels, listQuery.Result[0].Labels)
 		require.Equal(t, saveCmdTwo.State, listQuery.Result[0].CurrentState)
 	})
+
+	t.Run("can save and read the expanded annotations of an alert instance", func(t *testing.T) {
+		alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+		saveCmd := &models.SaveAlertInstanceCommand{
+			RuleOrgID:   alertRule.OrgID,
+			RuleUID:     alertRule.UID,
+			State:       models.InstanceStateFiring,
+			Labels:      models.InstanceLabels{"test": "annotations"},
+			Annotations: map[string]string{"summary": "CPU usage is 95%"},
+		}
+		err := dbstore.SaveAlertInstance(saveCmd)
+		require.NoError(t, err)
+
+		getCmd := &models.GetAlertInstanceQuery{
+			RuleOrgID: saveCmd.RuleOrgID,
+			RuleUID:   saveCmd.RuleUID,
+			Labels:    saveCmd.Labels,
+		}
+		err = dbstore.GetAlertInstance(getCmd)
+		require.NoError(t, err)
+		require.Equal(t, saveCmd.Annotations, getCmd.Result.Annotations)
+
+		listQuery := &models.ListAlertInstancesQuery{
+			RuleOrgID: alertRule.OrgID,
+			RuleUID:   alertRule.UID,
+		}
+		err = dbstore.ListAlertInstances(listQuery)
+		require.NoError(t, err)
+		require.Len(t, listQuery.Result, 1)
+		require.Equal(t, saveCmd.Annotations, listQuery.Result[0].Annotations)
+	})
+
+	t.Run("can save and read the keep firing and flapping of an alert instance", func(t *testing.T) {
+		saveCmd := &models.SaveAlertInstanceCommand{
+			RuleOrgID:       alertRule4.OrgID,
+			RuleUID:         alertRule4.UID,
+			State:           models.InstanceStateFiring,
+			Labels:          models.InstanceLabels{"test": "testValue"},
+			KeepFiringSince: time.Unix(100, 0),
+			Flapping:        true,
+			StateChanges:    []time.Time{time.Unix(10, 0).UTC(), time.Unix(20, 0).UTC()},
+		}
+		err := dbstore.SaveAlertInstance(saveCmd)
+		require.NoError(t, err)
+
+		getCmd := &models.GetAlertInstanceQuery{
+			RuleOrgID: saveCmd.RuleOrgID,
+			RuleUID:   saveCmd.RuleUID,
+			Labels:    saveCmd.Labels,
+		}
+		err = dbstore.GetAlertInstance(getCmd)
+		require.NoError(t, err)
+
+		require.Equal(t, saveCmd.KeepFiringSince.Unix(), getCmd.Result.KeepFiringSince.Unix())
+		require.True(t, getCmd.Result.Flapping)
+		require.Equal(t, saveCmd.StateChanges, getCmd.Result.StateChanges)
+
+		listQuery := &models.ListAlertInstancesQuery{
+			RuleOrgID: alertRule4.OrgID,
+			RuleUID:   alertRule4.UID,
+		}
+		err = dbstore.ListAlertInstances(listQuery)
+		require.NoError(t, err)
+
+		require.Len(t, listQuery.Result, 1)
+		require.True(t, listQuery.Result[0].Flapping)
+		require.Equal(t, saveCmd.StateChanges, listQuery.Result[0].StateChanges)
+	})
 }
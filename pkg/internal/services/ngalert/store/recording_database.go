@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/internal/components/securejsondata"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+)
+
+var (
+	// ErrNoRecordingConfiguration is an error for when an organisation has no recording configuration.
+	ErrNoRecordingConfiguration = fmt.Errorf("could not find a recording configuration")
+)
+
+// RecordingStore is the database interface used for the configuration of the recording rules.
+type RecordingStore interface {
+	GetRecordingConfig(query *models.GetRecordingConfigQuery) error
+	SaveRecordingConfig(cmd *models.SaveRecordingConfigCommand) error
+}
+
+// GetRecordingConfig is a handler for retrieving the recording configuration of an organisation.
+// It returns ErrNoRecordingConfiguration if no configuration is found.
+func (st DBstore) GetRecordingConfig(query *models.GetRecordingConfigQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		config := models.RecordingConfig{}
+		has, err := sess.Where("org_id = ?", query.OrgID).Get(&config)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrNoRecordingConfiguration
+		}
+
+		query.Result = &config
+		return nil
+	})
+}
+
+// SaveRecordingConfig is a handler for creating or updating the recording configuration of an organisation.
+func (st DBstore) SaveRecordingConfig(cmd *models.SaveRecordingConfigCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		config := models.RecordingConfig{}
+		has, err := sess.Where("org_id = ?", cmd.OrgID).Get(&config)
+		if err != nil {
+			return err
+		}
+
+		config.OrgID = cmd.OrgID
+		config.RemoteWriteURL = cmd.RemoteWriteURL
+		config.BasicAuthUser = cmd.BasicAuthUser
+		config.Updated = TimeNow()
+		if cmd.BasicAuthPassword != nil {
+			config.SecureSettings = securejsondata.GetEncryptedJsonData(map[string]string{"basicAuthPassword": *cmd.BasicAuthPassword})
+		}
+
+		if has {
+			_, err = sess.ID(config.ID).AllCols().Update(&config)
+		} else {
+			_, err = sess.Insert(&config)
+		}
+		return err
+	})
+}
@@ -0,0 +1,89 @@
+// +build integration
+
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/registry"
+	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/tests"
+	"github.com/prometheus/common/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingOperations(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	t.Run("recording configurations are saved per organisation", func(t *testing.T) {
+		err := dbstore.GetRecordingConfig(&models.GetRecordingConfigQuery{OrgID: 1})
+		require.ErrorIs(t, err, store.ErrNoRecordingConfiguration)
+
+		password := "secret"
+		require.NoError(t, dbstore.SaveRecordingConfig(&models.SaveRecordingConfigCommand{
+			OrgID: 1, RemoteWriteURL: "http://prometheus:9090/api/v1/write", BasicAuthUser: "grafana", BasicAuthPassword: &password,
+		}))
+		require.NoError(t, dbstore.SaveRecordingConfig(&models.SaveRecordingConfigCommand{
+			OrgID: 1, RemoteWriteURL: "http://cortex/api/v1/push", BasicAuthUser: "grafana",
+		}))
+
+		q := &models.GetRecordingConfigQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetRecordingConfig(q))
+		require.Equal(t, "http://cortex/api/v1/push", q.Result.RemoteWriteURL)
+		require.Equal(t, "secret", q.Result.BasicAuthPassword())
+
+		err = dbstore.GetRecordingConfig(&models.GetRecordingConfigQuery{OrgID: 2})
+		require.ErrorIs(t, err, store.ErrNoRecordingConfiguration)
+	})
+
+	t.Run("recording rules are saved without condition", func(t *testing.T) {
+		record := &models.Record{Metric: "job:up:sum", From: "A"}
+		err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "recording",
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{Labels: map[string]string{"team": "infra"}},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:  "job up",
+						Record: record,
+						Data: []models.AlertQuery{{
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type": "math", "expression": "2 + 2"}`),
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+							RefID:             "A",
+						}},
+					},
+				}},
+			},
+		})
+		require.NoError(t, err)
+
+		q := &models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: "recording"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(q))
+		require.Len(t, q.Result, 1)
+		require.True(t, q.Result[0].IsRecording())
+		require.Equal(t, record, q.Result[0].Record)
+		require.Empty(t, q.Result[0].Condition)
+
+		record.Metric = "job up"
+		err = dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name: "recording",
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: q.Result[0].UID, Record: record},
+				}},
+			},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
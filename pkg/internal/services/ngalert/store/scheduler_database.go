@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore"
+)
+
+// SchedulerStore is the database interface used by the scheduler to partition the alert rules between
+// the Grafana instances sharing the database.
+type SchedulerStore interface {
+	SaveSchedulerHeartbeat(cmd *models.SaveSchedulerHeartbeatCommand) error
+	ListSchedulerInstances(query *models.ListSchedulerInstancesQuery) error
+	DeleteSchedulerInstances(cmd *models.DeleteSchedulerInstancesCommand) error
+}
+
+// SaveSchedulerHeartbeat is a handler for updating the last heartbeat of a scheduler instance, creating
+// the instance if it does not exist.
+func (st DBstore) SaveSchedulerHeartbeat(cmd *models.SaveSchedulerHeartbeatCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		instance := models.SchedulerInstance{}
+		has, err := sess.Where("instance_id = ?", cmd.InstanceID).Get(&instance)
+		if err != nil {
+			return err
+		}
+
+		instance.InstanceID = cmd.InstanceID
+		instance.LastHeartbeat = cmd.Time.Unix()
+		if has {
+			_, err = sess.ID(instance.ID).Cols("last_heartbeat").Update(&instance)
+		} else {
+			_, err = sess.Insert(&instance)
+		}
+		return err
+	})
+}
+
+// ListSchedulerInstances is a handler for retrieving the live scheduler instances.
+func (st DBstore) ListSchedulerInstances(query *models.ListSchedulerInstancesQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		instances := make([]*models.SchedulerInstance, 0)
+		if err := sess.Where("last_heartbeat >= ?", query.Since.Unix()).Asc("instance_id").Find(&instances); err != nil {
+			return err
+		}
+
+		query.Result = instances
+		return nil
+	})
+}
+
+// DeleteSchedulerInstances is a handler for deleting a scheduler instance that stops, and the instances that
+// stopped sending heartbeats.
+func (st DBstore) DeleteSchedulerInstances(cmd *models.DeleteSchedulerInstancesCommand) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_instance WHERE instance_id = ? OR last_heartbeat < ?", cmd.InstanceID, cmd.Before.Unix())
+		return err
+	})
+}
@@ -0,0 +1,51 @@
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/registry"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerInstanceOperations(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(registry.ClearOverrides)
+
+	now := time.Unix(1000, 0)
+
+	listInstances := func(t *testing.T, since time.Time) []string {
+		t.Helper()
+		q := &models.ListSchedulerInstancesQuery{Since: since}
+		require.NoError(t, dbstore.ListSchedulerInstances(q))
+		ids := make([]string, 0, len(q.Result))
+		for _, instance := range q.Result {
+			ids = append(ids, instance.InstanceID)
+		}
+		return ids
+	}
+
+	t.Run("heartbeats are saved and updated", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveSchedulerHeartbeat(&models.SaveSchedulerHeartbeatCommand{InstanceID: "b", Time: now}))
+		require.NoError(t, dbstore.SaveSchedulerHeartbeat(&models.SaveSchedulerHeartbeatCommand{InstanceID: "a", Time: now}))
+		require.Equal(t, []string{"a", "b"}, listInstances(t, now))
+
+		require.NoError(t, dbstore.SaveSchedulerHeartbeat(&models.SaveSchedulerHeartbeatCommand{InstanceID: "a", Time: now.Add(time.Minute)}))
+		require.Equal(t, []string{"a"}, listInstances(t, now.Add(time.Second)))
+	})
+
+	t.Run("stopped and timed out instances are deleted", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveSchedulerHeartbeat(&models.SaveSchedulerHeartbeatCommand{InstanceID: "c", Time: now.Add(time.Minute)}))
+
+		require.NoError(t, dbstore.DeleteSchedulerInstances(&models.DeleteSchedulerInstancesCommand{Before: now.Add(time.Second)}))
+		require.Equal(t, []string{"a", "c"}, listInstances(t, now))
+
+		require.NoError(t, dbstore.DeleteSchedulerInstances(&models.DeleteSchedulerInstancesCommand{InstanceID: "c"}))
+		require.Equal(t, []string{"a"}, listInstances(t, now))
+	})
+}
@@ -0,0 +1,158 @@
+// Package writer writes the series computed by recording rules to Prometheus remote write endpoints.
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+)
+
+const writeTimeout = 30 * time.Second
+
+// PrometheusWriter writes series to the Prometheus remote write endpoint configured for each organisation.
+type PrometheusWriter struct {
+	store  store.RecordingStore
+	client *http.Client
+	log    log.Logger
+}
+
+func NewPrometheusWriter(store store.RecordingStore, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		store:  store,
+		client: &http.Client{Timeout: writeTimeout},
+		log:    logger,
+	}
+}
+
+// Write writes a sample at ts for each series of the frames, named metric and labelled with the labels of
+// the series and extraLabels, which take precedence.
+func (w *PrometheusWriter) Write(ctx context.Context, orgID int64, metric string, extraLabels map[string]string, frames data.Frames, ts time.Time) error {
+	series, err := toTimeSeries(metric, extraLabels, frames, ts)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		w.log.Debug("no series to write", "org", orgID, "metric", metric)
+		return nil
+	}
+
+	q := models.GetRecordingConfigQuery{OrgID: orgID}
+	if err := w.store.GetRecordingConfig(&q); err != nil {
+		if errors.Is(err, store.ErrNoRecordingConfiguration) {
+			return fmt.Errorf("no remote write endpoint configured for organisation %d", orgID)
+		}
+		return err
+	}
+	return w.push(ctx, q.Result, series)
+}
+
+func (w *PrometheusWriter) push(ctx context.Context, config *models.RecordingConfig, series []prompb.TimeSeries) error {
+	wr := prompb.WriteRequest{Timeseries: series}
+	b, err := wr.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode write request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.RemoteWriteURL, bytes.NewReader(snappy.Encode(nil, b)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if config.BasicAuthUser != "" {
+		req.SetBasicAuth(config.BasicAuthUser, config.BasicAuthPassword())
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write series: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to write series: remote write endpoint returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// toTimeSeries converts the numeric fields of the frames to series. Frames with a time field are time series,
+// of which the last value is written. Null values are skipped.
+func toTimeSeries(metric string, extraLabels map[string]string, frames data.Frames, ts time.Time) ([]prompb.TimeSeries, error) {
+	var series []prompb.TimeSeries
+	seen := make(map[string]struct{})
+	for _, frame := range frames {
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			return nil, fmt.Errorf("invalid frame %s: %w", frame.RefID, err)
+		}
+		if rowLen == 0 {
+			continue
+		}
+
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			if _, ok := field.ConcreteAt(rowLen - 1); !ok {
+				continue
+			}
+			value, err := field.FloatAt(rowLen - 1)
+			if err != nil {
+				return nil, err
+			}
+
+			lbls := make(map[string]string, len(field.Labels)+len(extraLabels)+1)
+			for k, v := range field.Labels {
+				lbls[k] = v
+			}
+			for k, v := range extraLabels {
+				lbls[k] = v
+			}
+			lbls[model.MetricNameLabel] = metric
+
+			s := toTimeSeriesLabels(lbls)
+			key := fmt.Sprint(s)
+			if _, ok := seen[key]; ok {
+				return nil, fmt.Errorf("series cannot uniquely be identified by their labels: has duplicate series with labels %s", data.Labels(lbls))
+			}
+			seen[key] = struct{}{}
+
+			series = append(series, prompb.TimeSeries{
+				Labels:  s,
+				Samples: []prompb.Sample{{Value: value, Timestamp: ts.UnixNano() / int64(time.Millisecond)}},
+			})
+		}
+	}
+	return series, nil
+}
+
+func toTimeSeriesLabels(lbls map[string]string) []prompb.Label {
+	result := make([]prompb.Label, 0, len(lbls))
+	for k, v := range lbls {
+		result = append(result, prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,138 @@
+package writer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/components/securejsondata"
+	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/internal/services/ngalert/store"
+)
+
+type fakeRecordingStore struct {
+	configs map[int64]*models.RecordingConfig
+}
+
+func (f *fakeRecordingStore) GetRecordingConfig(query *models.GetRecordingConfigQuery) error {
+	config, ok := f.configs[query.OrgID]
+	if !ok {
+		return store.ErrNoRecordingConfiguration
+	}
+	query.Result = config
+	return nil
+}
+
+func (f *fakeRecordingStore) SaveRecordingConfig(cmd *models.SaveRecordingConfigCommand) error {
+	return nil
+}
+
+// receiver decodes the remote write requests.
+type receiver struct {
+	requests []prompb.WriteRequest
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.headers = append(r.headers, req.Header)
+	compressed, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var wr prompb.WriteRequest
+	if err := wr.Unmarshal(b); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.requests = append(r.requests, wr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestPrometheusWriter(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	w := NewPrometheusWriter(&fakeRecordingStore{configs: map[int64]*models.RecordingConfig{
+		1: {OrgID: 1, RemoteWriteURL: server.URL},
+		2: {OrgID: 2, RemoteWriteURL: server.URL, BasicAuthUser: "grafana", SecureSettings: securejsondata.GetEncryptedJsonData(map[string]string{"basicAuthPassword": "secret"})},
+	}}, log.New("test"))
+	ts := time.Unix(1000, 0)
+
+	t.Run("reduced series are written at the evaluation time", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("", data.Labels{"instance": "a", "job": "node"}, []*float64{float64Ptr(1.5)})),
+			data.NewFrame("", data.NewField("", data.Labels{"instance": "b", "job": "node"}, []*float64{float64Ptr(2)})),
+			data.NewFrame("", data.NewField("", data.Labels{"instance": "c", "job": "node"}, []*float64{nil})),
+		}
+		require.NoError(t, w.Write(context.Background(), 1, "job:cpu:avg", map[string]string{"job": "recorded", "team": "infra"}, frames, ts))
+
+		require.Len(t, recv.requests, 1)
+		assert.Equal(t, []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "job:cpu:avg"}, {Name: "instance", Value: "a"}, {Name: "job", Value: "recorded"}, {Name: "team", Value: "infra"}},
+				Samples: []prompb.Sample{{Value: 1.5, Timestamp: 1000000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "job:cpu:avg"}, {Name: "instance", Value: "b"}, {Name: "job", Value: "recorded"}, {Name: "team", Value: "infra"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 1000000}},
+			},
+		}, recv.requests[0].Timeseries)
+		assert.Equal(t, "snappy", recv.headers[0].Get("Content-Encoding"))
+		assert.Empty(t, recv.headers[0].Get("Authorization"))
+	})
+
+	t.Run("the last value of time series is written", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{ts.Add(-time.Minute), ts}),
+			data.NewField("value", data.Labels{"db": "main"}, []int64{3, 4}),
+		)}
+		require.NoError(t, w.Write(context.Background(), 2, "db:queries", nil, frames, ts))
+
+		require.Len(t, recv.requests, 2)
+		assert.Equal(t, []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "db:queries"}, {Name: "db", Value: "main"}},
+			Samples: []prompb.Sample{{Value: 4, Timestamp: 1000000}},
+		}}, recv.requests[1].Timeseries)
+		user, password, ok := (&http.Request{Header: recv.headers[1]}).BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "grafana", user)
+		assert.Equal(t, "secret", password)
+	})
+
+	t.Run("series with the same labels are rejected", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("",
+			data.NewField("A", nil, []float64{1}),
+			data.NewField("B", nil, []float64{2}),
+		)}
+		err := w.Write(context.Background(), 1, "duplicate", nil, frames, ts)
+		require.Error(t, err)
+		assert.Len(t, recv.requests, 2)
+	})
+
+	t.Run("organisations without configuration cannot write", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("", data.NewField("", nil, []float64{1}))}
+		err := w.Write(context.Background(), 3, "unconfigured", nil, frames, ts)
+		require.EqualError(t, err, "no remote write endpoint configured for organisation 3")
+	})
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -44,7 +44,12 @@ func (cr *configReader) parseConfigs(file os.FileInfo) ([]*config, error) {
 		}
 
 		if v1 != nil {
-			return v1.mapToDashboardsAsConfig()
+			configs, err := v1.mapToDashboardsAsConfig()
+			if err != nil {
+				return nil, err
+			}
+			resolveRelativePaths(configs, filepath.Dir(filename))
+			return configs, nil
 		}
 	} else {
 		var v0 []*configV0
@@ -62,6 +67,24 @@ func (cr *configReader) parseConfigs(file os.FileInfo) ([]*config, error) {
 	return []*config{}, nil
 }
 
+// resolveRelativePaths makes the relative paths of file providers that don't exist in the working directory relative
+// to the directory of their configuration file instead, so that a provisioning directory can be moved along with its
+// dashboards.
+func resolveRelativePaths(configs []*config, configDir string) {
+	for _, cfg := range configs {
+		path, ok := cfg.Options["path"].(string)
+		if !ok || path == "" || filepath.IsAbs(path) {
+			continue
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(configDir, path)); err == nil {
+			cfg.Options["path"] = filepath.Join(configDir, path)
+		}
+	}
+}
+
 func (cr *configReader) readConfig() ([]*config, error) {
 	var dashboards []*config
 
@@ -3,7 +3,9 @@ package dashboards
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,6 +61,19 @@ func TestDashboardsAsConfig(t *testing.T) {
 			validateDashboardAsConfig(t, cfg)
 		})
 
+		t.Run("Relative paths missing in the working directory are relative to the config file", func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "org_1"), 0750))
+			config := "apiVersion: 1\nproviders:\n- name: export\n  options:\n    path: org_1\n"
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "dashboards.yaml"), []byte(config), 0600))
+
+			cfgProvider := configReader{path: dir, log: logger}
+			cfg, err := cfgProvider.readConfig()
+			require.NoError(t, err)
+			require.Len(t, cfg, 1)
+			require.Equal(t, filepath.Join(dir, "org_1"), cfg[0].Options["path"])
+		})
+
 		t.Run("Can read config file in version 0 format", func(t *testing.T) {
 			cfgProvider := configReader{path: oldVersion, log: logger}
 			cfg, err := cfgProvider.readConfig()
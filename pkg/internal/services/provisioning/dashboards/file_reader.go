@@ -118,7 +118,7 @@ func (fr *FileReader) walkDisk() error {
 // storeDashboardsInFolder saves dashboards from the filesystem on disk to the folder from config
 func (fr *FileReader) storeDashboardsInFolder(filesFoundOnDisk map[string]os.FileInfo,
 	dashboardRefs map[string]*models.DashboardProvisioning, sanityChecker *provisioningSanityChecker) error {
-	folderID, err := getOrCreateFolderID(fr.Cfg, fr.dashboardProvisioningService, fr.Cfg.Folder)
+	folderID, err := getOrCreateFolderID(fr.Cfg, fr.dashboardProvisioningService, fr.Cfg.Folder, 0)
 	if err != nil && !errors.Is(err, ErrFolderNameMissing) {
 		return err
 	}
@@ -137,20 +137,14 @@ func (fr *FileReader) storeDashboardsInFolder(filesFoundOnDisk map[string]os.Fil
 }
 
 // storeDashboardsInFoldersFromFilesystemStructure saves dashboards from the filesystem on disk to the same folder
-// in Grafana as they are in on the filesystem.
+// in Grafana as they are in on the filesystem. Nested directories are provisioned as nested folders.
 func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(filesFoundOnDisk map[string]os.FileInfo,
 	dashboardRefs map[string]*models.DashboardProvisioning, resolvedPath string, sanityChecker *provisioningSanityChecker) error {
+	folderIDs := map[string]int64{}
 	for path, fileInfo := range filesFoundOnDisk {
-		folderName := ""
-
-		dashboardsFolder := filepath.Dir(path)
-		if dashboardsFolder != resolvedPath {
-			folderName = filepath.Base(dashboardsFolder)
-		}
-
-		folderID, err := getOrCreateFolderID(fr.Cfg, fr.dashboardProvisioningService, folderName)
-		if err != nil && !errors.Is(err, ErrFolderNameMissing) {
-			return fmt.Errorf("can't provision folder %q from file system structure: %w", folderName, err)
+		folderID, err := fr.getOrCreateFolderIDFromFileStructure(filepath.Dir(path), resolvedPath, folderIDs)
+		if err != nil {
+			return err
 		}
 
 		provisioningMetadata, err := fr.saveDashboard(path, folderID, fileInfo, dashboardRefs)
@@ -162,6 +156,34 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(filesFoundOnDisk
 	return nil
 }
 
+// getOrCreateFolderIDFromFileStructure returns the ID of the folder for the directory dashboardsFolder, creating
+// a folder for every directory between resolvedPath and dashboardsFolder if necessary. The IDs of the folders
+// are cached in folderIDs by directory.
+func (fr *FileReader) getOrCreateFolderIDFromFileStructure(dashboardsFolder, resolvedPath string,
+	folderIDs map[string]int64) (int64, error) {
+	if len(dashboardsFolder) <= len(resolvedPath) {
+		return 0, nil
+	}
+
+	if folderID, ok := folderIDs[dashboardsFolder]; ok {
+		return folderID, nil
+	}
+
+	parentID, err := fr.getOrCreateFolderIDFromFileStructure(filepath.Dir(dashboardsFolder), resolvedPath, folderIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	folderName := filepath.Base(dashboardsFolder)
+	folderID, err := getOrCreateFolderID(fr.Cfg, fr.dashboardProvisioningService, folderName, parentID)
+	if err != nil {
+		return 0, fmt.Errorf("can't provision folder %q from file system structure: %w", folderName, err)
+	}
+
+	folderIDs[dashboardsFolder] = folderID
+	return folderID, nil
+}
+
 // handleMissingDashboardFiles will unprovision or delete dashboards which are missing on disk.
 func (fr *FileReader) handleMissingDashboardFiles(provisionedDashboardRefs map[string]*models.DashboardProvisioning,
 	filesFoundOnDisk map[string]os.FileInfo) {
@@ -263,12 +285,15 @@ func getProvisionedDashboardsByPath(service dashboards.DashboardProvisioningServ
 	return byPath, nil
 }
 
-func getOrCreateFolderID(cfg *config, service dashboards.DashboardProvisioningService, folderName string) (int64, error) {
+// getOrCreateFolderID returns the ID of the folder named folderName in the folder with ID parentID,
+// creating it if it doesn't exist. A parentID of 0 refers to the top level.
+func getOrCreateFolderID(cfg *config, service dashboards.DashboardProvisioningService, folderName string,
+	parentID int64) (int64, error) {
 	if folderName == "" {
 		return 0, ErrFolderNameMissing
 	}
 
-	cmd := &models.GetDashboardQuery{Slug: models.SlugifyTitle(folderName), OrgId: cfg.OrgID}
+	cmd := &models.GetDashboardQuery{Slug: models.SlugifyTitle(folderName), OrgId: cfg.OrgID, FolderId: &parentID}
 	err := bus.Dispatch(cmd)
 
 	if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
@@ -280,6 +305,7 @@ func getOrCreateFolderID(cfg *config, service dashboards.DashboardProvisioningSe
 		dash := &dashboards.SaveDashboardDTO{}
 		dash.Dashboard = models.NewDashboardFolder(folderName)
 		dash.Dashboard.IsFolder = true
+		dash.Dashboard.FolderId = parentID
 		dash.Overwrite = true
 		dash.OrgId = cfg.OrgID
 		// set dashboard folderUid if given
@@ -26,6 +26,7 @@ const (
 	containingID              = "testdata/test-dashboards/containing-id"
 	unprovision               = "testdata/test-dashboards/unprovision"
 	foldersFromFilesStructure = "testdata/test-dashboards/folders-from-files-structure"
+	nestedFoldersFromFiles    = "testdata/test-dashboards/nested-folders-from-files-structure"
 )
 
 var fakeService *fakeDashboardProvisioningService
@@ -324,6 +325,31 @@ func TestDashboardFileReader(t *testing.T) {
 				}
 			})
 
+			Convey("Get nested folders from files structure", func() {
+				cfg.Options["path"] = nestedFoldersFromFiles
+				cfg.Options["foldersFromFilesStructure"] = true
+
+				reader, err := NewDashboardFileReader(cfg, logger, nil)
+				So(err, ShouldBeNil)
+
+				err = reader.walkDisk()
+				So(err, ShouldBeNil)
+
+				So(len(fakeService.inserted), ShouldEqual, 4)
+
+				byTitle := make(map[string]*models.Dashboard, 4)
+				for _, d := range fakeService.inserted {
+					byTitle[d.Dashboard.Title] = d.Dashboard
+				}
+
+				So(byTitle["parent"].IsFolder, ShouldBeTrue)
+				So(byTitle["parent"].FolderId, ShouldEqual, 0)
+				So(byTitle["child"].IsFolder, ShouldBeTrue)
+				So(byTitle["child"].FolderId, ShouldEqual, byTitle["parent"].Id)
+				So(byTitle["ParentDashboard"].FolderId, ShouldEqual, byTitle["parent"].Id)
+				So(byTitle["ChildDashboard"].FolderId, ShouldEqual, byTitle["child"].Id)
+			})
+
 			Convey("Invalid configuration should return error", func() {
 				cfg := &config{
 					Name:   "Default",
@@ -385,7 +411,7 @@ func TestDashboardFileReader(t *testing.T) {
 				},
 			}
 
-			_, err := getOrCreateFolderID(cfg, fakeService, cfg.Folder)
+			_, err := getOrCreateFolderID(cfg, fakeService, cfg.Folder, 0)
 			So(err, ShouldEqual, ErrFolderNameMissing)
 		})
 
@@ -400,7 +426,7 @@ func TestDashboardFileReader(t *testing.T) {
 				},
 			}
 
-			folderID, err := getOrCreateFolderID(cfg, fakeService, cfg.Folder)
+			folderID, err := getOrCreateFolderID(cfg, fakeService, cfg.Folder, 0)
 			So(err, ShouldBeNil)
 			inserted := false
 			for _, d := range fakeService.inserted {
@@ -577,7 +603,11 @@ func (s *fakeDashboardProvisioningService) SaveProvisionedDashboard(dto *dashboa
 }
 
 func (s *fakeDashboardProvisioningService) SaveFolderForProvisionedDashboards(dto *dashboards.SaveDashboardDTO) (*models.Dashboard, error) {
+	if dto.Dashboard.Id == 0 {
+		dto.Dashboard.Id = rand.Int63n(1000000) + 1
+	}
 	s.inserted = append(s.inserted, dto)
+	s.getDashboard = append(s.getDashboard, dto.Dashboard)
 	return dto.Dashboard, nil
 }
 
@@ -612,7 +642,7 @@ func (s *fakeDashboardProvisioningService) GetProvisionedDashboardDataByDashboar
 
 func mockGetDashboardQuery(cmd *models.GetDashboardQuery) error {
 	for _, d := range fakeService.getDashboard {
-		if d.Slug == cmd.Slug {
+		if d.Slug == cmd.Slug && (cmd.FolderId == nil || d.FolderId == *cmd.FolderId) {
 			cmd.Result = d
 			return nil
 		}
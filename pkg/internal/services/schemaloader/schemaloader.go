@@ -2,14 +2,19 @@ package schemaloader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/grafana/grafana"
+	"github.com/grafana/grafana/pkg/internal/bus"
 	"github.com/grafana/grafana/pkg/internal/components/simplejson"
 	"github.com/grafana/grafana/pkg/internal/schema"
 	"github.com/grafana/grafana/pkg/internal/schema/load"
 
 	"github.com/grafana/grafana/pkg/internal/infra/log"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/plugins"
 	"github.com/grafana/grafana/pkg/internal/registry"
 	"github.com/grafana/grafana/pkg/internal/setting"
 )
@@ -35,9 +40,16 @@ type RenderUser struct {
 }
 
 type SchemaLoaderService struct {
-	log        log.Logger
-	DashFamily schema.VersionedCueSchema
-	Cfg        *setting.Cfg `inject:""`
+	log           log.Logger
+	DashFamily    schema.VersionedCueSchema
+	Cfg           *setting.Cfg    `inject:""`
+	Bus           bus.Bus         `inject:""`
+	PluginManager plugins.Manager `inject:""`
+
+	// instanceDashFamily is the dashboard family composed with the schemas of the core
+	// and non-core panel plugins. It is loaded on first use, and again once plugins change.
+	instanceDashFamily schema.VersionedCueSchema
+	instanceMu         sync.Mutex
 }
 
 func (rs *SchemaLoaderService) Init() error {
@@ -48,20 +60,31 @@ func (rs *SchemaLoaderService) Init() error {
 	if err != nil {
 		return fmt.Errorf("failed to load dashboard cue schema from path %q: %w", baseLoadPath, err)
 	}
+
+	rs.Bus.AddHandler(rs.validateDashboardSchema)
+	rs.Bus.AddEventListener(rs.handlePluginsChanged)
 	return nil
 }
+
+// IsDisabled returns true if neither the trimDefaults feature toggle nor the dashboard schema validation
+// or migration is enabled, since the validateDashboardSchema handler is registered by Init.
 func (rs *SchemaLoaderService) IsDisabled() bool {
 	if rs.Cfg == nil {
 		return true
 	}
-	return !rs.Cfg.IsTrimDefaultsEnabled()
+	return !rs.Cfg.IsTrimDefaultsEnabled() && !rs.Cfg.DashboardSchemaValidation && !rs.Cfg.DashboardSchemaMigration
 }
 
 func (rs *SchemaLoaderService) DashboardApplyDefaults(input *simplejson.Json) (*simplejson.Json, error) {
+	fam, err := rs.InstanceDashboardFamily()
+	if err != nil {
+		return input, err
+	}
+
 	val, _ := input.Map()
 	val = removeNils(val)
 	data, _ := json.Marshal(val)
-	dsSchema := schema.Find(rs.DashFamily, schema.Latest())
+	dsSchema := schema.Find(fam, schema.Latest())
 	result, err := schema.ApplyDefaults(schema.Resource{Value: data}, dsSchema.CUE())
 	if err != nil {
 		return input, err
@@ -74,11 +97,16 @@ func (rs *SchemaLoaderService) DashboardApplyDefaults(input *simplejson.Json) (*
 }
 
 func (rs *SchemaLoaderService) DashboardTrimDefaults(input simplejson.Json) (simplejson.Json, error) {
+	fam, err := rs.InstanceDashboardFamily()
+	if err != nil {
+		return input, err
+	}
+
 	val, _ := input.Map()
 	val = removeNils(val)
 	data, _ := json.Marshal(val)
 
-	dsSchema, err := schema.SearchAndValidate(rs.DashFamily, data)
+	dsSchema, err := schema.SearchAndValidate(fam, data)
 	if err != nil {
 		return input, err
 	}
@@ -94,6 +122,157 @@ func (rs *SchemaLoaderService) DashboardTrimDefaults(input simplejson.Json) (sim
 	return *output, nil
 }
 
+func (rs *SchemaLoaderService) validateDashboardSchema(cmd *models.ValidateDashboardSchemaCommand) error {
+	cmd.Result = cmd.Dashboard
+	if !rs.Cfg.DashboardSchemaValidation && !rs.Cfg.DashboardSchemaMigration {
+		return nil
+	}
+
+	result, err := rs.DashboardMigrate(cmd.Dashboard)
+	if err != nil {
+		var schemaErr models.DashboardSchemaError
+		if errors.As(err, &schemaErr) && !rs.Cfg.DashboardSchemaValidation {
+			rs.log.Debug("Not migrating dashboard that is not valid with respect to the dashboard schema", "error", err)
+			return nil
+		}
+		return err
+	}
+
+	if rs.Cfg.DashboardSchemaMigration {
+		cmd.Result = result
+	}
+	return nil
+}
+
+// DashboardMigrate validates the dashboard against the dashboard schema family and migrates it from the
+// schema version it is valid with respect to, to the latest version. If the dashboard is not valid with
+// respect to any schema version, a models.DashboardSchemaError is returned.
+func (rs *SchemaLoaderService) DashboardMigrate(input *simplejson.Json) (*simplejson.Json, error) {
+	val, _ := input.Map()
+	val = removeNils(val)
+	data, _ := json.Marshal(val)
+
+	dsSchema, err := schema.SearchAndValidate(rs.DashFamily, data)
+	if err != nil {
+		// SearchAndValidate returns the error of the oldest schema in the family, which is where the search ends
+		schemaErr := models.DashboardSchemaError{}
+		for _, e := range schema.ValidationErrors(err, rs.DashFamily) {
+			schemaErr.Errors = append(schemaErr.Errors, models.DashboardSchemaErrorItem{Path: e.Path, Message: e.Message})
+		}
+		return input, schemaErr
+	}
+
+	if err := rs.validatePanels(val); err != nil {
+		return input, err
+	}
+
+	if dsSchema.Successor() == nil {
+		return input, nil
+	}
+
+	result, _, err := schema.MigrateToLatest(dsSchema, schema.Resource{Value: data})
+	if err != nil {
+		return input, err
+	}
+	return simplejson.NewJson([]byte(result.Value.(string)))
+}
+
+// InstanceDashboardFamily returns the dashboard family composed with the schemas of the core panel plugins
+// and of the non-core panel plugins that ship a models.cue file.
+func (rs *SchemaLoaderService) InstanceDashboardFamily() (schema.VersionedCueSchema, error) {
+	rs.instanceMu.Lock()
+	defer rs.instanceMu.Unlock()
+
+	if rs.instanceDashFamily != nil {
+		return rs.instanceDashFamily, nil
+	}
+
+	panels := make(map[string]schema.VersionedCueSchema)
+	if rs.PluginManager != nil {
+		for _, p := range rs.PluginManager.Panels() {
+			if p.Schema != nil {
+				panels[p.Id] = p.Schema
+			}
+		}
+	}
+
+	fam, err := load.InstanceDashboardFamily(baseLoadPath, panels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose dashboard cue schema with panel plugin schemas: %w", err)
+	}
+
+	rs.instanceDashFamily = fam
+	return fam, nil
+}
+
+// handlePluginsChanged drops the instance dashboard family, so that it is composed again with the schemas of the
+// installed panel plugins on next use.
+func (rs *SchemaLoaderService) handlePluginsChanged(_ *models.PluginsChangedEvent) error {
+	rs.instanceMu.Lock()
+	defer rs.instanceMu.Unlock()
+
+	rs.instanceDashFamily = nil
+	return nil
+}
+
+// validatePanels validates the panels of the dashboard against the schemas of their panel plugins.
+// Panels of plugins without a schema are not validated.
+func (rs *SchemaLoaderService) validatePanels(dash map[string]interface{}) error {
+	panels, _ := dash["panels"].([]interface{})
+	if len(panels) == 0 {
+		return nil
+	}
+
+	fam, err := rs.InstanceDashboardFamily()
+	if err != nil {
+		return err
+	}
+	cds, ok := fam.(load.CompositeDashboardSchema)
+	if !ok {
+		return nil
+	}
+
+	schemaErr := models.DashboardSchemaError{}
+	var validate func(path string, panels []interface{})
+	validate = func(path string, panels []interface{}) {
+		for i, p := range panels {
+			panel, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			panelPath := fmt.Sprintf("%s.%d", path, i)
+
+			// collapsed rows keep their panels nested
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				validate(panelPath+".panels", nested)
+			}
+
+			panelType, _ := panel["type"].(string)
+			psch, err := cds.LatestPanelSchemaFor(panelType)
+			if err != nil {
+				continue
+			}
+
+			data, _ := json.Marshal(panel)
+			if err := psch.Validate(schema.Resource{Value: data}); err != nil {
+				for _, e := range schema.ValidationErrors(err, psch) {
+					item := models.DashboardSchemaErrorItem{Path: panelPath, Message: e.Message}
+					if e.Path != "" {
+						item.Path = panelPath + "." + e.Path
+					}
+					schemaErr.Errors = append(schemaErr.Errors, item)
+				}
+			}
+		}
+	}
+	validate("panels", panels)
+
+	if len(schemaErr.Errors) > 0 {
+		return schemaErr
+	}
+	return nil
+}
+
 func removeNils(initialMap map[string]interface{}) map[string]interface{} {
 	withoutNils := map[string]interface{}{}
 	for key, value := range initialMap {
@@ -0,0 +1,171 @@
+package schemaloader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/plugins"
+	"github.com/grafana/grafana/pkg/internal/schema/load"
+	"github.com/grafana/grafana/pkg/internal/setting"
+)
+
+func TestValidateDashboardSchema(t *testing.T) {
+	setup := func(t *testing.T, validation bool) *SchemaLoaderService {
+		t.Helper()
+
+		cfg := setting.NewCfg()
+		cfg.DashboardSchemaValidation = validation
+		cfg.DashboardSchemaMigration = true
+		rs := &SchemaLoaderService{Cfg: cfg, Bus: bus.New()}
+		require.NoError(t, rs.Init())
+		return rs
+	}
+
+	dashboard := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+
+	t.Run("Valid dashboard is saved as is", func(t *testing.T) {
+		rs := setup(t, true)
+		input := dashboard(t, `{"title": "Dash", "schemaVersion": 27, "graphTooltip": 1}`)
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		require.NoError(t, rs.Bus.Dispatch(&cmd))
+		assert.Same(t, input, cmd.Result)
+	})
+
+	t.Run("Invalid dashboard returns the invalid paths", func(t *testing.T) {
+		rs := setup(t, true)
+		input := dashboard(t, `{"title": "Dash", "schemaVersion": 27, "graphTooltip": 5}`)
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		err := rs.Bus.Dispatch(&cmd)
+
+		var schemaErr models.DashboardSchemaError
+		require.True(t, errors.As(err, &schemaErr))
+		require.NotEmpty(t, schemaErr.Errors)
+		assert.Equal(t, "graphTooltip", schemaErr.Errors[0].Path)
+	})
+
+	t.Run("Dashboards are validated through the bus without the trimDefaults feature toggle", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		require.False(t, cfg.IsTrimDefaultsEnabled())
+		cfg.DashboardSchemaValidation = true
+		rs := &SchemaLoaderService{Cfg: cfg, Bus: bus.New()}
+
+		// the registry only initializes the services that are not disabled
+		require.False(t, rs.IsDisabled())
+		require.NoError(t, rs.Init())
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: dashboard(t, `{"title": "Dash", "schemaVersion": 27, "graphTooltip": 5}`)}
+		var schemaErr models.DashboardSchemaError
+		require.True(t, errors.As(rs.Bus.Dispatch(&cmd), &schemaErr))
+	})
+
+	t.Run("The service is disabled without the toggle and the schema settings", func(t *testing.T) {
+		rs := &SchemaLoaderService{Cfg: setting.NewCfg(), Bus: bus.New()}
+		require.True(t, rs.IsDisabled())
+	})
+
+	t.Run("Invalid dashboard is accepted when validation is disabled", func(t *testing.T) {
+		rs := setup(t, false)
+		input := dashboard(t, `{"title": "Dash", "schemaVersion": 27, "graphTooltip": 5}`)
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		require.NoError(t, rs.Bus.Dispatch(&cmd))
+		assert.Same(t, input, cmd.Result)
+	})
+}
+
+func TestValidateDashboardSchema_PanelPlugins(t *testing.T) {
+	fam, err := load.PanelPluginFamily(baseLoadPath, os.DirFS(filepath.Join("testdata", "test-panel")))
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.DashboardSchemaValidation = true
+	rs := &SchemaLoaderService{
+		Cfg: cfg,
+		Bus: bus.New(),
+		PluginManager: &fakePluginManager{panels: []*plugins.PanelPlugin{
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "test-panel"}}, Schema: fam},
+		}},
+	}
+	require.NoError(t, rs.Init())
+
+	t.Run("Panels of non-core plugins are validated", func(t *testing.T) {
+		input, err := simplejson.NewJson([]byte(`{
+			"title": "Dash",
+			"schemaVersion": 27,
+			"panels": [
+				{"id": 1, "type": "test-panel", "options": {"showLegend": false}},
+				{"id": 2, "type": "test-panel", "options": {"showLegend": "yes"}}
+			]
+		}`))
+		require.NoError(t, err)
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		err = rs.Bus.Dispatch(&cmd)
+
+		var schemaErr models.DashboardSchemaError
+		require.True(t, errors.As(err, &schemaErr))
+		require.NotEmpty(t, schemaErr.Errors)
+		assert.Equal(t, "panels.1.options.showLegend", schemaErr.Errors[0].Path)
+	})
+
+	t.Run("Panels of plugins without schema are not validated", func(t *testing.T) {
+		input, err := simplejson.NewJson([]byte(`{
+			"title": "Dash",
+			"schemaVersion": 27,
+			"panels": [{"id": 1, "type": "unknown-panel", "options": {"showLegend": "yes"}}]
+		}`))
+		require.NoError(t, err)
+
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		require.NoError(t, rs.Bus.Dispatch(&cmd))
+	})
+
+	t.Run("Panels of plugins installed after the first validation are validated", func(t *testing.T) {
+		pluginManager := rs.PluginManager.(*fakePluginManager)
+		installed := pluginManager.panels
+		pluginManager.panels = nil
+		require.NoError(t, rs.Bus.Publish(&models.PluginsChangedEvent{}))
+
+		input, err := simplejson.NewJson([]byte(`{
+			"title": "Dash",
+			"schemaVersion": 27,
+			"panels": [{"id": 1, "type": "test-panel", "options": {"showLegend": "yes"}}]
+		}`))
+		require.NoError(t, err)
+		cmd := models.ValidateDashboardSchemaCommand{Dashboard: input}
+		require.NoError(t, rs.Bus.Dispatch(&cmd))
+
+		pluginManager.panels = installed
+		require.NoError(t, rs.Bus.Publish(&models.PluginsChangedEvent{}))
+
+		err = rs.Bus.Dispatch(&cmd)
+		var schemaErr models.DashboardSchemaError
+		require.True(t, errors.As(err, &schemaErr))
+		assert.Equal(t, "panels.0.options.showLegend", schemaErr.Errors[0].Path)
+	})
+}
+
+type fakePluginManager struct {
+	plugins.Manager
+
+	panels []*plugins.PanelPlugin
+}
+
+func (pm *fakePluginManager) Panels() []*plugins.PanelPlugin {
+	return pm.panels
+}
@@ -11,21 +11,71 @@ const (
 )
 
 type Hit struct {
-	ID           int64    `json:"id"`
-	UID          string   `json:"uid"`
-	Title        string   `json:"title"`
-	URI          string   `json:"uri"`
-	URL          string   `json:"url"`
-	Slug         string   `json:"slug"`
-	Type         HitType  `json:"type"`
-	Tags         []string `json:"tags"`
-	IsStarred    bool     `json:"isStarred"`
-	FolderID     int64    `json:"folderId,omitempty"`
-	FolderUID    string   `json:"folderUid,omitempty"`
-	FolderTitle  string   `json:"folderTitle,omitempty"`
-	FolderURL    string   `json:"folderUrl,omitempty"`
-	SortMeta     int64    `json:"sortMeta"`
-	SortMetaName string   `json:"sortMetaName,omitempty"`
+	ID           int64       `json:"id"`
+	UID          string      `json:"uid"`
+	Title        string      `json:"title"`
+	URI          string      `json:"uri"`
+	URL          string      `json:"url"`
+	Slug         string      `json:"slug"`
+	Type         HitType     `json:"type"`
+	Tags         []string    `json:"tags"`
+	IsStarred    bool        `json:"isStarred"`
+	FolderID     int64       `json:"folderId,omitempty"`
+	FolderUID    string      `json:"folderUid,omitempty"`
+	FolderTitle  string      `json:"folderTitle,omitempty"`
+	FolderURL    string      `json:"folderUrl,omitempty"`
+	FolderPath   []HitFolder `json:"folderPath,omitempty"`
+	Matches      []HitMatch  `json:"matches,omitempty"`
+	SortMeta     int64       `json:"sortMeta"`
+	SortMetaName string      `json:"sortMetaName,omitempty"`
+}
+
+// HitFolder is a folder in the path of a hit, the path starts with the top level folder and
+// ends with the folder the hit is in.
+type HitFolder struct {
+	ID    int64  `json:"id"`
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// HitMatch is a piece of the content of a dashboard that matched a content search.
+type HitMatch struct {
+	Field string `json:"field"`
+	// PanelID is the ID of the panel the match is in, or 0 if the match is in the dashboard itself.
+	PanelID    int64          `json:"panelId,omitempty"`
+	PanelTitle string         `json:"panelTitle,omitempty"`
+	Text       string         `json:"text"`
+	Highlights []HitHighlight `json:"highlights"`
+}
+
+// HitHighlight is the position of a match of the query in the text of a HitMatch,
+// counted in characters from the start of the text.
+type HitHighlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// FindHighlights returns the positions of all case insensitive occurrences of query in text.
+func FindHighlights(text, query string) []HitHighlight {
+	highlights := make([]HitHighlight, 0)
+
+	t := []rune(strings.ToLower(text))
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 || len(t) != len([]rune(text)) {
+		return highlights
+	}
+
+	for i := 0; i+len(q) <= len(t); {
+		if string(t[i:i+len(q)]) == string(q) {
+			highlights = append(highlights, HitHighlight{Start: i, End: i + len(q)})
+			i += len(q)
+			continue
+		}
+		i++
+	}
+
+	return highlights
 }
 
 type HitList []*Hit
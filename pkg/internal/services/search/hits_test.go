@@ -0,0 +1,15 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindHighlights(t *testing.T) {
+	assert.Equal(t, []HitHighlight{{Start: 5, End: 12}, {Start: 27, End: 34}},
+		FindHighlights("rate(Latency_bucket) / sum(latency)", "latency"))
+	assert.Equal(t, []HitHighlight{{Start: 2, End: 4}}, FindHighlights("Über", "er"))
+	assert.Empty(t, FindHighlights("requests", "errors"))
+	assert.Empty(t, FindHighlights("requests", ""))
+}
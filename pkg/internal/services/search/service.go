@@ -15,35 +15,39 @@ func init() {
 }
 
 type Query struct {
-	Title        string
-	Tags         []string
-	OrgId        int64
-	SignedInUser *models.SignedInUser
-	Limit        int64
-	Page         int64
-	IsStarred    bool
-	Type         string
-	DashboardIds []int64
-	FolderIds    []int64
-	Permission   models.PermissionType
-	Sort         string
+	Title         string
+	Content       string
+	ContentFields []string
+	Tags          []string
+	OrgId         int64
+	SignedInUser  *models.SignedInUser
+	Limit         int64
+	Page          int64
+	IsStarred     bool
+	Type          string
+	DashboardIds  []int64
+	FolderIds     []int64
+	Permission    models.PermissionType
+	Sort          string
 
 	Result HitList
 }
 
 type FindPersistedDashboardsQuery struct {
-	Title        string
-	OrgId        int64
-	SignedInUser *models.SignedInUser
-	IsStarred    bool
-	DashboardIds []int64
-	Type         string
-	FolderIds    []int64
-	Tags         []string
-	Limit        int64
-	Page         int64
-	Permission   models.PermissionType
-	Sort         SortOption
+	Title         string
+	Content       string
+	ContentFields []string
+	OrgId         int64
+	SignedInUser  *models.SignedInUser
+	IsStarred     bool
+	DashboardIds  []int64
+	Type          string
+	FolderIds     []int64
+	Tags          []string
+	Limit         int64
+	Page          int64
+	Permission    models.PermissionType
+	Sort          SortOption
 
 	Filters []interface{}
 
@@ -69,16 +73,18 @@ func (s *SearchService) Init() error {
 
 func (s *SearchService) searchHandler(query *Query) error {
 	dashboardQuery := FindPersistedDashboardsQuery{
-		Title:        query.Title,
-		SignedInUser: query.SignedInUser,
-		IsStarred:    query.IsStarred,
-		DashboardIds: query.DashboardIds,
-		Type:         query.Type,
-		FolderIds:    query.FolderIds,
-		Tags:         query.Tags,
-		Limit:        query.Limit,
-		Page:         query.Page,
-		Permission:   query.Permission,
+		Title:         query.Title,
+		Content:       query.Content,
+		ContentFields: query.ContentFields,
+		SignedInUser:  query.SignedInUser,
+		IsStarred:     query.IsStarred,
+		DashboardIds:  query.DashboardIds,
+		Type:          query.Type,
+		FolderIds:     query.FolderIds,
+		Tags:          query.Tags,
+		Limit:         query.Limit,
+		Page:          query.Page,
+		Permission:    query.Permission,
 	}
 
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
@@ -90,7 +96,8 @@ func (s *SearchService) searchHandler(query *Query) error {
 	}
 
 	hits := dashboardQuery.Result
-	if query.Sort == "" {
+	// hits matching the content are already ranked by relevance
+	if query.Sort == "" && query.Content == "" {
 		hits = sortedHits(hits)
 	}
 
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -161,11 +162,40 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		}
 	}
 
+	if err := updateDashboardContent(sess, dash); err != nil {
+		return err
+	}
+
 	cmd.Result = dash
 
 	return nil
 }
 
+// updateDashboardContent replaces the content of the dashboard stored for content search.
+func updateDashboardContent(sess *DBSession, dash *models.Dashboard) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_content WHERE dashboard_id=?", dash.Id); err != nil {
+		return err
+	}
+
+	content := dash.GetContent()
+	if len(content) == 0 {
+		return nil
+	}
+
+	rows := make([]*DashboardContent, 0, len(content))
+	for _, item := range content {
+		rows = append(rows, &DashboardContent{
+			DashboardId: dash.Id,
+			PanelId:     item.PanelId,
+			Field:       item.Field,
+			Term:        item.Term,
+		})
+	}
+
+	_, err := sess.Insert(&rows)
+	return err
+}
+
 func generateNewDashboardUid(sess *DBSession, orgId int64) (string, error) {
 	for i := 0; i < 3; i++ {
 		uid := generateNewUid()
@@ -209,9 +239,9 @@ func (ss *SQLStore) GetFolderByTitle(orgID int64, title string) (*models.Dashboa
 	}
 
 	// there is a unique constraint on org_id, folder_id, title
-	// there are no nested folders so the parent folder id is always 0
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
-	has, err := ss.engine.Get(&dashboard)
+	// only top level folders are considered, subfolders can share their title with folders elsewhere
+	dashboard := models.Dashboard{OrgId: orgID, Title: title}
+	has, err := ss.engine.Where("folder_id = 0").Get(&dashboard)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -228,6 +258,28 @@ func (ss *SQLStore) GetFolderByTitle(orgID int64, title string) (*models.Dashboa
 	return &dashboard, nil
 }
 
+// GetFolderAncestors returns a folder and the folders above it, starting with the top level folder.
+func (ss *SQLStore) GetFolderAncestors(orgID int64, folderID int64) ([]*models.Dashboard, error) {
+	var ancestors []*models.Dashboard
+	err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+		var err error
+		ancestors, err = getFolderAncestors(sess, orgID, folderID)
+		return err
+	})
+	return ancestors, err
+}
+
+// GetFolderDescendants returns all subfolders of a folder, at any depth.
+func (ss *SQLStore) GetFolderDescendants(orgID int64, folderID int64) ([]*models.Dashboard, error) {
+	var descendants []*models.Dashboard
+	err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+		var err error
+		descendants, err = getFolderDescendants(sess, orgID, folderID)
+		return err
+	})
+	return descendants, err
+}
+
 // TODO: Remove me
 func GetDashboard(query *models.GetDashboardQuery) error {
 	if query.Id == 0 && len(query.Slug) == 0 && len(query.Uid) == 0 {
@@ -235,7 +287,13 @@ func GetDashboard(query *models.GetDashboardQuery) error {
 	}
 
 	dashboard := models.Dashboard{Slug: query.Slug, OrgId: query.OrgId, Id: query.Id, Uid: query.Uid}
-	has, err := x.Get(&dashboard)
+	sess := x.NewSession()
+	defer sess.Close()
+	// the folder is an explicit condition since xorm ignores the zero folder ID of the top level
+	if query.FolderId != nil {
+		sess.Where("folder_id = ?", *query.FolderId)
+	}
+	has, err := sess.Get(&dashboard)
 
 	if err != nil {
 		return err
@@ -308,6 +366,14 @@ func findDashboards(query *search.FindPersistedDashboardsQuery) ([]DashboardSear
 		filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
 	}
 
+	if len(query.Content) > 0 {
+		filters = append(filters, searchstore.ContentFilter{Dialect: dialect, Query: query.Content, Fields: query.ContentFields})
+		if len(query.Sort.Filter) == 0 {
+			// dashboards with the same rank are sorted by title
+			filters = append(filters, searchstore.TitleSorter{})
+		}
+	}
+
 	var res []DashboardSearchProjection
 	sb := &searchstore.Builder{Dialect: dialect, Filters: filters}
 
@@ -338,9 +404,174 @@ func SearchDashboards(query *search.FindPersistedDashboardsQuery) error {
 
 	makeQueryResult(query, res)
 
+	if err := setContentMatches(query); err != nil {
+		return err
+	}
+
+	return setFolderPaths(query)
+}
+
+// setContentMatches sets the content of every hit that matched the content query.
+func setContentMatches(query *search.FindPersistedDashboardsQuery) error {
+	if len(query.Content) == 0 || len(query.Result) == 0 {
+		return nil
+	}
+
+	params := make([]interface{}, 0, len(query.Result)+len(query.ContentFields)+1)
+	for _, hit := range query.Result {
+		params = append(params, hit.ID)
+	}
+
+	match, param := searchstore.LikeContains(dialect, "term", query.Content)
+	params = append(params, param)
+	if len(query.ContentFields) > 0 {
+		match += " AND field IN (?" + strings.Repeat(",?", len(query.ContentFields)-1) + ")"
+		for _, field := range query.ContentFields {
+			params = append(params, field)
+		}
+	}
+
+	// panel titles are loaded as well, to show which panel a match is in
+	var content []*DashboardContent
+	err := x.SQL(`SELECT dashboard_id, panel_id, field, term FROM dashboard_content
+		WHERE dashboard_id IN (?`+strings.Repeat(",?", len(query.Result)-1)+`)
+		AND ((`+match+`) OR (field = ? AND panel_id > 0))
+		ORDER BY id`, append(params, models.DashboardContentTitle)...).Find(&content)
+	if err != nil {
+		return err
+	}
+
+	type panelKey struct {
+		dashboardID int64
+		panelID     int64
+	}
+	panelTitles := make(map[panelKey]string)
+	for _, c := range content {
+		if c.Field == models.DashboardContentTitle && c.PanelId > 0 {
+			panelTitles[panelKey{c.DashboardId, c.PanelId}] = c.Term
+		}
+	}
+
+	fields := make(map[string]bool, len(query.ContentFields))
+	for _, field := range query.ContentFields {
+		fields[field] = true
+	}
+
+	hits := make(map[int64]*search.Hit, len(query.Result))
+	for _, hit := range query.Result {
+		hits[hit.ID] = hit
+	}
+
+	for _, c := range content {
+		if len(fields) > 0 && !fields[c.Field] {
+			continue
+		}
+
+		highlights := search.FindHighlights(c.Term, query.Content)
+		if len(highlights) == 0 {
+			continue
+		}
+
+		hit := hits[c.DashboardId]
+		hit.Matches = append(hit.Matches, search.HitMatch{
+			Field:      c.Field,
+			PanelID:    c.PanelId,
+			PanelTitle: panelTitles[panelKey{c.DashboardId, c.PanelId}],
+			Text:       c.Term,
+			Highlights: highlights,
+		})
+	}
+
+	return nil
+}
+
+// setFolderPaths sets the path of folders leading to every hit that is in a folder. The ancestor folders
+// the user cannot view are left out of the path.
+func setFolderPaths(query *search.FindPersistedDashboardsQuery) error {
+	orgID := query.OrgId
+	if orgID == 0 {
+		orgID = query.SignedInUser.OrgId
+	}
+
+	ancestorsByFolderID := make(map[int64][]*models.Dashboard)
+	var folderIDs []interface{}
+	err := withDbSession(context.Background(), x, func(sess *DBSession) error {
+		for _, hit := range query.Result {
+			if _, exists := ancestorsByFolderID[hit.FolderID]; exists || hit.FolderID == 0 {
+				continue
+			}
+			ancestors, err := getFolderAncestors(sess, orgID, hit.FolderID)
+			// the folder may have been deleted since the search
+			if err != nil && !errors.Is(err, models.ErrDashboardFolderNotFound) {
+				return err
+			}
+			ancestorsByFolderID[hit.FolderID] = ancestors
+			for _, folder := range ancestors {
+				folderIDs = append(folderIDs, folder.Id)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(folderIDs) == 0 {
+		return err
+	}
+
+	viewable, err := viewableFolderIDs(query.SignedInUser, orgID, folderIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, hit := range query.Result {
+		path := make([]search.HitFolder, 0)
+		for _, folder := range ancestorsByFolderID[hit.FolderID] {
+			if !viewable[folder.Id] {
+				continue
+			}
+			path = append(path, search.HitFolder{
+				ID:    folder.Id,
+				UID:   folder.Uid,
+				Title: folder.Title,
+				URL:   models.GetFolderUrl(folder.Uid, folder.Slug),
+			})
+		}
+		if len(path) > 0 {
+			hit.FolderPath = path
+		}
+	}
+
 	return nil
 }
 
+// viewableFolderIDs returns which of the given folders the user can view.
+func viewableFolderIDs(user *models.SignedInUser, orgID int64, folderIDs []interface{}) (map[int64]bool, error) {
+	sql := "SELECT dashboard.id FROM dashboard WHERE dashboard.org_id = ? AND dashboard.id IN (?" +
+		strings.Repeat(",?", len(folderIDs)-1) + ")"
+	params := append([]interface{}{orgID}, folderIDs...)
+
+	filter := permissions.DashboardPermissionFilter{
+		OrgRole:         user.OrgRole,
+		OrgId:           user.OrgId,
+		Dialect:         dialect,
+		UserId:          user.UserId,
+		PermissionLevel: models.PERMISSION_VIEW,
+	}
+	if where, whereParams := filter.Where(); where != "" {
+		sql += " AND " + where
+		params = append(params, whereParams...)
+	}
+
+	ids := make([]int64, 0, len(folderIDs))
+	if err := x.SQL(sql, params...).Find(&ids); err != nil {
+		return nil, err
+	}
+
+	viewable := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		viewable[id] = true
+	}
+	return viewable, nil
+}
+
 func getHitType(item DashboardSearchProjection) search.HitType {
 	var hitType search.HitType
 	if item.IsFolder {
@@ -423,6 +654,7 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 
 	deletes := []string{
 		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
+		"DELETE FROM dashboard_content WHERE dashboard_id = ?",
 		"DELETE FROM star WHERE dashboard_id = ? ",
 		"DELETE FROM dashboard WHERE id = ?",
 		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
@@ -433,12 +665,26 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 	}
 
 	if dashboard.IsFolder {
+		// subfolders are deleted along with their own dashboards and subfolders first
+		subfolders := make([]*models.Dashboard, 0)
+		err := sess.Where("org_id = ? AND folder_id = ? AND is_folder = ?", dashboard.OrgId, dashboard.Id,
+			dialect.BooleanStr(true)).Find(&subfolders)
+		if err != nil {
+			return err
+		}
+
+		for _, subfolder := range subfolders {
+			if err := deleteDashboard(&models.DeleteDashboardCommand{Id: subfolder.Id, OrgId: dashboard.OrgId}, sess); err != nil {
+				return err
+			}
+		}
+
 		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
 
 		dashIds := []struct {
 			Id int64
 		}{}
-		err := sess.SQL("SELECT id FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
+		err = sess.SQL("SELECT id FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
 		if err != nil {
 			return err
 		}
@@ -452,6 +698,7 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		if len(dashIds) > 0 {
 			childrenDeletes := []string{
 				"DELETE FROM dashboard_tag WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_content WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM star WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_version WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
@@ -519,7 +766,8 @@ func GetDashboardPermissionsForUser(query *models.GetDashboardPermissionsForUser
 	// check dashboards that have ACLs via user id, team id or role
 	sql := `SELECT d.id AS dashboard_id, MAX(COALESCE(da.permission, pt.permission)) AS permission
 	FROM dashboard AS d
-		LEFT JOIN dashboard_acl as da on d.folder_id = da.dashboard_id or d.id = da.dashboard_id
+		` + permissions.AncestorFolderJoins("d") + `
+		LEFT JOIN dashboard_acl as da on ` + permissions.ACLOnDashboardOrAncestors("da", "d") + `
 		LEFT JOIN team_member as ugm on ugm.team_id =  da.team_id
 		LEFT JOIN org_user ou ON ou.role = da.role AND ou.user_id = ?
 	`
@@ -540,19 +788,19 @@ func GetDashboardPermissionsForUser(query *models.GetDashboardPermissionsForUser
 		params = append(params, id)
 	}
 
+	// the permissions of the dashboard and its ancestor folders replace the permissions of the role
+	hasACL := permissions.HasACLOnDashboardOrAncestors(dialect, "d")
 	sql += ` AND
 	d.org_id = ? AND
 	  (
-		(d.has_acl = ?  AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
-		OR (d.has_acl = ? AND ouRole.id IS NOT NULL)
+		(` + hasACL + ` AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
+		OR (NOT ` + hasACL + ` AND ouRole.id IS NOT NULL)
 	)
 	group by d.id
 	order by d.id asc`
 	params = append(params, query.OrgId)
-	params = append(params, dialect.BooleanStr(true))
 	params = append(params, query.UserId)
 	params = append(params, query.UserId)
-	params = append(params, dialect.BooleanStr(false))
 
 	err := x.SQL(sql, params...).Find(&query.Result)
 
@@ -666,6 +914,9 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 	}
 
 	if !dashWithIdExists && !dashWithUidExists {
+		if dash.IsFolder && dash.FolderId > 0 {
+			return isParentFolderChanged, validateFolderParent(sess, dash.OrgId, 0, dash.FolderId)
+		}
 		return isParentFolderChanged, nil
 	}
 
@@ -680,9 +931,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 		dash.SetUid(existingByUid.Uid)
 		existing = existingByUid
 
-		if !dash.IsFolder {
-			isParentFolderChanged = true
-		}
+		isParentFolderChanged = true
 	}
 
 	if (existing.IsFolder && !dash.IsFolder) ||
@@ -690,8 +939,21 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 		return isParentFolderChanged, models.ErrDashboardTypeMismatch
 	}
 
-	if !dash.IsFolder && dash.FolderId != existing.FolderId {
+	if dash.IsFolder && dash.FolderId > 0 && dash.FolderId != existing.FolderId {
+		if err := validateFolderParent(sess, dash.OrgId, existing.Id, dash.FolderId); err != nil {
+			return isParentFolderChanged, err
+		}
+	}
+
+	if dash.FolderId != existing.FolderId {
 		isParentFolderChanged = true
+
+		// alert rules are looked up by the title of their folder among the top level folders
+		if existing.IsFolder {
+			if err := validateFolderHasNoAlertRules(sess, existing); err != nil {
+				return isParentFolderChanged, err
+			}
+		}
 	}
 
 	// check for is someone else has written in between
@@ -711,11 +973,127 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 	return isParentFolderChanged, nil
 }
 
+// validateFolderParent checks that the folder with the given ID can be placed in the parent folder,
+// without creating a cycle or exceeding models.MaxFolderDepth. folderID is 0 for new folders.
+func validateFolderParent(sess *DBSession, orgID, folderID, parentID int64) error {
+	ancestors, err := getFolderAncestors(sess, orgID, parentID)
+	if err != nil {
+		return err
+	}
+
+	// the parent folder itself is the last ancestor of the folder
+	for _, ancestor := range ancestors {
+		if folderID > 0 && ancestor.Id == folderID {
+			return models.ErrDashboardFolderCannotBeMovedIntoItself
+		}
+	}
+
+	height := 1
+	if folderID > 0 {
+		descendants, err := getFolderDescendants(sess, orgID, folderID)
+		if err != nil {
+			return err
+		}
+		height += folderHeight(folderID, descendants)
+	}
+
+	if len(ancestors)+height > models.MaxFolderDepth {
+		return models.ErrDashboardFolderMaxDepthExceeded
+	}
+
+	return nil
+}
+
+// validateFolderHasNoAlertRules checks that no alert rules are stored in the folder, so that it can be moved.
+func validateFolderHasNoAlertRules(sess *DBSession, folder models.Dashboard) error {
+	count, err := sess.Table("alert_rule").Where("org_id=? AND namespace_uid=?", folder.OrgId, folder.Uid).Count()
+	if err != nil {
+		return fmt.Errorf("SQL query for alert rules in folder failed: %w", err)
+	}
+	if count > 0 {
+		return models.ErrDashboardFolderHasAlertRules
+	}
+	return nil
+}
+
+// getFolderAncestors returns the folder with the given ID and all folders above it,
+// ordered from the top level folder down to the folder itself.
+func getFolderAncestors(sess *DBSession, orgID, folderID int64) ([]*models.Dashboard, error) {
+	ancestors := make([]*models.Dashboard, 0)
+	for id := folderID; id > 0; {
+		// the depth of the tree is limited, so a longer chain can only be caused by a cycle
+		if len(ancestors) > models.MaxFolderDepth {
+			return nil, models.ErrDashboardFolderCannotBeMovedIntoItself
+		}
+
+		var folder models.Dashboard
+		exists, err := sess.Where("org_id=? AND id=? AND is_folder=?", orgID, id, dialect.BooleanStr(true)).Get(&folder)
+		if err != nil {
+			return nil, fmt.Errorf("SQL query for folder failed: %w", err)
+		}
+		if !exists {
+			return nil, models.ErrDashboardFolderNotFound
+		}
+
+		ancestors = append([]*models.Dashboard{&folder}, ancestors...)
+		id = folder.FolderId
+	}
+
+	return ancestors, nil
+}
+
+// getFolderDescendants returns all subfolders of the folder with the given ID, at any depth.
+func getFolderDescendants(sess *DBSession, orgID, folderID int64) ([]*models.Dashboard, error) {
+	descendants := make([]*models.Dashboard, 0)
+	parentIDs := []int64{folderID}
+	for depth := 0; len(parentIDs) > 0; depth++ {
+		if depth > models.MaxFolderDepth {
+			return nil, models.ErrDashboardFolderMaxDepthExceeded
+		}
+
+		children := make([]*models.Dashboard, 0)
+		err := sess.Where("org_id=? AND is_folder=?", orgID, dialect.BooleanStr(true)).
+			In("folder_id", parentIDs).Find(&children)
+		if err != nil {
+			return nil, fmt.Errorf("SQL query for subfolders failed: %w", err)
+		}
+
+		parentIDs = make([]int64, 0, len(children))
+		for _, child := range children {
+			parentIDs = append(parentIDs, child.Id)
+		}
+		descendants = append(descendants, children...)
+	}
+
+	return descendants, nil
+}
+
+// folderHeight returns the number of folder levels below the folder with the given ID.
+func folderHeight(folderID int64, descendants []*models.Dashboard) int {
+	height := 0
+	for _, d := range descendants {
+		if d.FolderId == folderID {
+			if h := folderHeight(d.Id, descendants) + 1; h > height {
+				height = h
+			}
+		}
+	}
+	return height
+}
+
 func getExistingDashboardByTitleAndFolder(sess *DBSession, dash *models.Dashboard, overwrite,
 	isParentFolderChanged bool) (bool, error) {
 	var existing models.Dashboard
-	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
-		dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	var exists bool
+	var err error
+	if dash.IsFolder {
+		// folder titles only have to be unique among the siblings of the folder
+		exists, err = sess.Where("org_id=? AND slug=? AND folder_id=?", dash.OrgId, dash.Slug,
+			dash.FolderId).Get(&existing)
+	} else {
+		exists, err = sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
+			dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	}
 	if err != nil {
 		return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by org ID or folder ID failed: %w", err)
 	}
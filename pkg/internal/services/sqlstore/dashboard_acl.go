@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/internal/bus"
 	"github.com/grafana/grafana/pkg/internal/models"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore/permissions"
 )
 
 func init() {
@@ -45,7 +46,7 @@ func (ss *SQLStore) UpdateDashboardACL(dashboardID int64, items []*models.Dashbo
 // GetDashboardAclInfoList returns a list of permissions for a dashboard. They can be fetched from three
 // different places.
 // 1) Permissions for the dashboard
-// 2) permissions for its parent folder
+// 2) permissions for its parent folder and the ancestors of the parent folder
 // 3) if no specific permissions have been set for the dashboard or its parent folder then get the default permissions
 func GetDashboardAclInfoList(query *models.GetDashboardAclInfoListQuery) error {
 	var err error
@@ -77,7 +78,7 @@ func GetDashboardAclInfoList(query *models.GetDashboardAclInfoListQuery) error {
 		err = x.SQL(sql).Find(&query.Result)
 	} else {
 		rawSQL := `
-			-- get permissions for the dashboard and its parent folder
+			-- get permissions for the dashboard and its ancestor folders
 			SELECT
 				da.id,
 				da.org_id,
@@ -96,18 +97,14 @@ func GetDashboardAclInfoList(query *models.GetDashboardAclInfoListQuery) error {
 				d.slug,
 				d.uid,
 				d.is_folder,
-				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR da.dashboard_id = d.folder_id THEN ` + dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
+				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR (da.dashboard_id <> -1 AND da.dashboard_id <> d.id) THEN ` + dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
 			FROM dashboard as d
-				LEFT JOIN dashboard folder on folder.id = d.folder_id
+				` + permissions.AncestorFolderJoins("d") + `
 				LEFT JOIN dashboard_acl AS da ON
-				da.dashboard_id = d.id OR
-				da.dashboard_id = d.folder_id OR
+				` + permissions.ACLOnDashboardOrAncestors("da", "d") + ` OR
 				(
 					-- include default permissions -->
-					da.org_id = -1 AND (
-					  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-					  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
-					)
+					da.org_id = -1 AND ` + permissions.UsesDefaultPermissions(dialect, "d") + `
 				)
 				LEFT JOIN ` + dialect.Quote("user") + ` AS u ON u.id = da.user_id
 				LEFT JOIN team ug on ug.id = da.team_id
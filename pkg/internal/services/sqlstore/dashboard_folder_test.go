@@ -3,9 +3,13 @@
 package sqlstore
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/internal/components/simplejson"
 	"github.com/grafana/grafana/pkg/internal/models"
@@ -497,3 +501,155 @@ func moveDashboard(t *testing.T, sqlStore *SQLStore, orgId int64, dashboard *sim
 
 	return dash
 }
+
+func TestNestedDashboardFolderDataAccess(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	parent := insertTestDashboard(t, sqlStore, "parent folder", 1, 0, true)
+	child := insertTestDashboard(t, sqlStore, "child folder", 1, parent.Id, true)
+	dashInChild := insertTestDashboard(t, sqlStore, "dash in child", 1, child.Id, false)
+	viewer := createUser(t, sqlStore, "viewer", "Viewer", false)
+	otherViewer := createUser(t, sqlStore, "other viewer", "Viewer", false)
+
+	searchDashInChild := func(t *testing.T) []*search.Hit {
+		t.Helper()
+
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser: &models.SignedInUser{UserId: viewer.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER},
+			OrgId:        1,
+			DashboardIds: []int64{dashInChild.Id},
+		}
+		err := SearchDashboards(query)
+		require.NoError(t, err)
+		return query.Result
+	}
+
+	t.Run("search hits include the path of folders", func(t *testing.T) {
+		hits := searchDashInChild(t)
+		require.Len(t, hits, 1)
+		require.Len(t, hits[0].FolderPath, 2)
+		require.Equal(t, parent.Uid, hits[0].FolderPath[0].UID)
+		require.Equal(t, child.Uid, hits[0].FolderPath[1].UID)
+	})
+
+	t.Run("permissions of ancestor folders are inherited", func(t *testing.T) {
+		err := testHelperUpdateDashboardAcl(t, sqlStore, parent.Id, models.DashboardAcl{
+			DashboardID: parent.Id, OrgID: 1, UserID: 999, Permission: models.PERMISSION_EDIT,
+		})
+		require.NoError(t, err)
+		require.Empty(t, searchDashInChild(t))
+
+		err = testHelperUpdateDashboardAcl(t, sqlStore, parent.Id, models.DashboardAcl{
+			DashboardID: parent.Id, OrgID: 1, UserID: viewer.Id, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+		require.Len(t, searchDashInChild(t), 1)
+
+		for _, user := range []models.User{viewer, otherViewer} {
+			query := models.GetDashboardPermissionsForUserQuery{
+				DashboardIds: []int64{dashInChild.Id},
+				OrgId:        1,
+				UserId:       user.Id,
+				OrgRole:      models.ROLE_VIEWER,
+			}
+			err = GetDashboardPermissionsForUser(&query)
+			require.NoError(t, err)
+			if user.Id == viewer.Id {
+				require.Len(t, query.Result, 1)
+				require.Equal(t, models.PERMISSION_VIEW, query.Result[0].Permission)
+			} else {
+				require.Empty(t, query.Result, "the permissions of the ancestor folder replace the role of other users")
+			}
+		}
+
+		query := models.GetDashboardAclInfoListQuery{DashboardID: dashInChild.Id, OrgID: 1}
+		err = GetDashboardAclInfoList(&query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+		require.Equal(t, parent.Id, query.Result[0].DashboardId)
+		require.True(t, query.Result[0].Inherited)
+	})
+
+	t.Run("the path of folders leaves out the folders the user cannot view", func(t *testing.T) {
+		err := testHelperUpdateDashboardAcl(t, sqlStore, dashInChild.Id, models.DashboardAcl{
+			DashboardID: dashInChild.Id, OrgID: 1, UserID: otherViewer.Id, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser: &models.SignedInUser{UserId: otherViewer.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER},
+			OrgId:        1,
+			DashboardIds: []int64{dashInChild.Id},
+		}
+		err = SearchDashboards(query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+		require.Empty(t, query.Result[0].FolderPath)
+	})
+
+	t.Run("folders are found by slug within their parent folder or at the top level", func(t *testing.T) {
+		topLevel := insertTestDashboard(t, sqlStore, child.Title, 1, 0, true)
+
+		for _, expected := range []*models.Dashboard{topLevel, child} {
+			query := models.GetDashboardQuery{Slug: child.Slug, OrgId: 1, FolderId: &expected.FolderId}
+			err := GetDashboard(&query)
+			require.NoError(t, err)
+			require.Equal(t, expected.Id, query.Result.Id)
+		}
+	})
+
+	t.Run("a folder cannot be moved into its subfolder", func(t *testing.T) {
+		moved := models.NewDashboardFolder(parent.Title)
+		moved.Id = parent.Id
+		moved.OrgId = 1
+		moved.FolderId = child.Id
+		moved.Version = parent.Version
+		_, err := sqlStore.ValidateDashboardBeforeSave(moved, false)
+		require.Equal(t, models.ErrDashboardFolderCannotBeMovedIntoItself, err)
+	})
+
+	t.Run("folders cannot be nested deeper than the maximum depth", func(t *testing.T) {
+		folderID := int64(0)
+		for i := 0; i < models.MaxFolderDepth; i++ {
+			folderID = insertTestDashboard(t, sqlStore, fmt.Sprintf("level %d", i+1), 1, folderID, true).Id
+		}
+
+		tooDeep := models.NewDashboardFolder("too deep")
+		tooDeep.OrgId = 1
+		tooDeep.FolderId = folderID
+		_, err := sqlStore.ValidateDashboardBeforeSave(tooDeep, false)
+		require.Equal(t, models.ErrDashboardFolderMaxDepthExceeded, err)
+
+		ancestors, err := sqlStore.GetFolderAncestors(1, folderID)
+		require.NoError(t, err)
+		require.Len(t, ancestors, models.MaxFolderDepth)
+	})
+
+	t.Run("a folder that contains alert rules cannot be moved", func(t *testing.T) {
+		withRules := insertTestDashboard(t, sqlStore, "folder with alert rules", 1, 0, true)
+		err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.Exec("INSERT INTO alert_rule (org_id, title, condition, data, updated, uid, namespace_uid, rule_group) VALUES(?,?,?,?,?,?,?,?)",
+				1, "rule", "A", "[]", time.Now(), "rule", withRules.Uid, "group")
+			return err
+		})
+		require.NoError(t, err)
+
+		moved := models.NewDashboardFolder(withRules.Title)
+		moved.Id = withRules.Id
+		moved.OrgId = 1
+		moved.FolderId = parent.Id
+		moved.Version = withRules.Version
+		_, err = sqlStore.ValidateDashboardBeforeSave(moved, false)
+		require.Equal(t, models.ErrDashboardFolderHasAlertRules, err)
+	})
+
+	t.Run("deleting a folder deletes its subfolders and their dashboards", func(t *testing.T) {
+		err := DeleteDashboard(&models.DeleteDashboardCommand{Id: parent.Id, OrgId: 1})
+		require.NoError(t, err)
+
+		for _, id := range []int64{child.Id, dashInChild.Id} {
+			err := GetDashboard(&models.GetDashboardQuery{Id: id, OrgId: 1})
+			require.Equal(t, models.ErrDashboardNotFound, err)
+		}
+	})
+}
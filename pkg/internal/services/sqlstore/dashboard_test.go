@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/internal/components/simplejson"
 	"github.com/grafana/grafana/pkg/internal/models"
 	"github.com/grafana/grafana/pkg/internal/services/search"
+	"github.com/grafana/grafana/pkg/internal/services/sqlstore/migrations"
 	"github.com/grafana/grafana/pkg/internal/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/internal/setting"
 	"github.com/grafana/grafana/pkg/internal/util"
@@ -499,3 +500,126 @@ func createUser(t *testing.T, sqlStore *SQLStore, name string, role string, isAd
 
 	return *currentUser
 }
+
+func TestDashboard_ContentSearch(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	saveDashboard := func(title string, panels ...interface{}) *models.Dashboard {
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId: 1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title":  title,
+				"panels": panels,
+			}),
+		})
+		require.NoError(t, err)
+		return dash
+	}
+
+	panel := func(id int64, title, datasource, expr string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":         id,
+			"title":      title,
+			"datasource": datasource,
+			"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": expr}},
+		}
+	}
+
+	cpu := saveDashboard("Node CPU", panel(1, "CPU usage", "Prometheus", "node_cpu_seconds_total"))
+	apiErrors := saveDashboard("API errors",
+		panel(1, "Errors", "Prometheus", "http_requests_total{status=~\"5..\"}"),
+		panel(2, "Requests", "Prometheus", "rate(http_requests_total[5m])"))
+	logs := saveDashboard("API logs", panel(1, "Logs", "Loki", "{app=\"api\"}"))
+
+	searchContent := func(content string, fields ...string) search.HitList {
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser:  &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_ADMIN},
+			Content:       content,
+			ContentFields: fields,
+		}
+		err := SearchDashboards(query)
+		require.NoError(t, err)
+		return query.Result
+	}
+
+	t.Run("matches queries of panels", func(t *testing.T) {
+		hits := searchContent("http_requests_total")
+		require.Len(t, hits, 1)
+		require.Equal(t, apiErrors.Id, hits[0].ID)
+		require.Len(t, hits[0].Matches, 2)
+		assert.Equal(t, models.DashboardContentQuery, hits[0].Matches[0].Field)
+		assert.Equal(t, int64(1), hits[0].Matches[0].PanelID)
+		assert.Equal(t, "Errors", hits[0].Matches[0].PanelTitle)
+		assert.Equal(t, []search.HitHighlight{{Start: 0, End: 19}}, hits[0].Matches[0].Highlights)
+	})
+
+	t.Run("matches wildcards literally", func(t *testing.T) {
+		hits := searchContent("http_requests")
+		require.Len(t, hits, 1)
+		require.Equal(t, apiErrors.Id, hits[0].ID)
+
+		require.Empty(t, searchContent("node_cpu%total"))
+		require.Empty(t, searchContent("node_cpu_seconds!"))
+	})
+
+	t.Run("matches data sources only when limited to data sources", func(t *testing.T) {
+		hits := searchContent("prometheus", models.DashboardContentDatasource)
+		require.Len(t, hits, 2)
+
+		hits = searchContent("api", models.DashboardContentDatasource)
+		require.Empty(t, hits)
+	})
+
+	t.Run("ranks matches in the dashboard title first", func(t *testing.T) {
+		hits := searchContent("api")
+		require.Len(t, hits, 2)
+		assert.Equal(t, logs.Id, hits[0].ID)
+		assert.Equal(t, apiErrors.Id, hits[1].ID)
+	})
+
+	t.Run("content is removed with the dashboard", func(t *testing.T) {
+		err := DeleteDashboard(&models.DeleteDashboardCommand{Id: cpu.Id, OrgId: 1})
+		require.NoError(t, err)
+		require.Empty(t, searchContent("node_cpu"))
+	})
+}
+
+func TestDashboardContentMigration(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	// dashboards saved before the dashboard_content table existed have no content
+	dash := models.NewDashboard("Saved before content search")
+	dash.OrgId = 1
+	dash.Data.Set("panels", []interface{}{map[string]interface{}{
+		"id":      1,
+		"title":   "Legacy",
+		"targets": []interface{}{map[string]interface{}{"refId": "A", "expr": "legacy_requests_total"}},
+	}})
+	err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+
+	searchContent := func(content string) search.HitList {
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_ADMIN},
+			Content:      content,
+		}
+		err := SearchDashboards(query)
+		require.NoError(t, err)
+		return query.Result
+	}
+	require.Empty(t, searchContent("legacy_requests_total"))
+
+	err = sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+		return (&migrations.FillDashboardContentMigration{}).Exec(sess.Session, nil)
+	})
+	require.NoError(t, err)
+
+	hits := searchContent("legacy_requests_total")
+	require.Len(t, hits, 1)
+	assert.Equal(t, dash.Id, hits[0].ID)
+	require.Len(t, hits[0].Matches, 1)
+	assert.Equal(t, "Legacy", hits[0].Matches[0].PanelTitle)
+}
@@ -0,0 +1,247 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// copyBatchSize is the number of rows inserted per statement when copying a table.
+const copyBatchSize = 100
+
+// TableCopyResult is the result of copying a table by CopyDatabase.
+type TableCopyResult struct {
+	Table      string
+	SourceRows int64
+	TargetRows int64
+}
+
+// CopyDatabase copies the rows of every table of source into target, which must be migrated, replacing
+// the rows of target. Tables are copied in dependency order and values are converted to the column types
+// of the target dialect. The rows of every table are counted in both databases afterwards and an error is
+// returned if they don't match.
+func CopyDatabase(ctx context.Context, source, target *SQLStore) ([]TableCopyResult, error) {
+	sourceTables, err := source.engine.DBMetas()
+	if err != nil {
+		return nil, errutil.Wrap("failed to read source tables", err)
+	}
+	targetTables, err := target.engine.DBMetas()
+	if err != nil {
+		return nil, errutil.Wrap("failed to read target tables", err)
+	}
+
+	targetByName := map[string]*core.Table{}
+	for _, table := range targetTables {
+		targetByName[table.Name] = table
+	}
+
+	var tables []*core.Table
+	for _, table := range sourceTables {
+		// the target has its own migration log
+		if table.Name == "migration_log" {
+			continue
+		}
+		if _, exists := targetByName[table.Name]; !exists {
+			return nil, fmt.Errorf("table %s of the source database doesn't exist in the target database", table.Name)
+		}
+		tables = append(tables, table)
+	}
+
+	var results []TableCopyResult
+	for _, table := range sortTablesByDependencies(tables) {
+		if err := copyTable(ctx, source, target, table, targetByName[table.Name]); err != nil {
+			return nil, errutil.Wrapf(err, "failed to copy table %s", table.Name)
+		}
+
+		result := TableCopyResult{Table: table.Name}
+		if result.SourceRows, err = countRows(ctx, source, table.Name); err != nil {
+			return nil, err
+		}
+		if result.TargetRows, err = countRows(ctx, target, table.Name); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	var mismatches []string
+	for _, result := range results {
+		if result.SourceRows != result.TargetRows {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%d != %d)", result.Table, result.SourceRows, result.TargetRows))
+		}
+	}
+	if len(mismatches) > 0 {
+		return results, fmt.Errorf("row counts of the source and target databases don't match: %s", strings.Join(mismatches, ", "))
+	}
+
+	return results, nil
+}
+
+func copyTable(ctx context.Context, source, target *SQLStore, sourceTable, targetTable *core.Table) error {
+	var columns []*core.Column
+	for _, col := range targetTable.Columns() {
+		if sourceTable.GetColumn(col.Name) != nil {
+			columns = append(columns, col)
+		}
+	}
+
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+
+	return target.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM " + target.Dialect.Quote(targetTable.Name)); err != nil {
+			return err
+		}
+		if err := target.Dialect.PreInsertId(targetTable.Name, sess.Session); err != nil {
+			return err
+		}
+
+		rows, err := source.engine.DB().Query(fmt.Sprintf("SELECT %s FROM %s",
+			quoteColumns(source, names), source.Dialect.Quote(sourceTable.Name)))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		insert := func(batch [][]interface{}) error {
+			if len(batch) == 0 {
+				return nil
+			}
+
+			placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ")"
+			values := make([]string, 0, len(batch))
+			args := []interface{}{""}
+			for _, row := range batch {
+				values = append(values, placeholders)
+				args = append(args, row...)
+			}
+			args[0] = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", target.Dialect.Quote(targetTable.Name),
+				quoteColumns(target, names), strings.Join(values, ", "))
+
+			_, err := sess.Exec(args...)
+			return err
+		}
+
+		var batch [][]interface{}
+		for rows.Next() {
+			row := make([]interface{}, len(columns))
+			dest := make([]interface{}, len(columns))
+			for i := range row {
+				dest[i] = &row[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				return err
+			}
+			for i, col := range columns {
+				row[i] = convertColumnValue(row[i], col)
+			}
+
+			batch = append(batch, row)
+			if len(batch) == copyBatchSize {
+				if err := insert(batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if err := insert(batch); err != nil {
+			return err
+		}
+
+		if targetTable.AutoIncrement == "id" {
+			return target.Dialect.PostInsertId(targetTable.Name, sess.Session)
+		}
+		return nil
+	})
+}
+
+// convertColumnValue converts a value read from the source database to the type of a column of the target database.
+// Drivers return text as bytes, which would be stored as binary, and SQLite and MySQL store booleans as integers.
+func convertColumnValue(value interface{}, col *core.Column) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if col.SQLType.IsBlob() {
+			return v
+		}
+		if col.SQLType.Name == core.Bool {
+			return convertColumnValue(string(v), col)
+		}
+		return string(v)
+	case string:
+		if col.SQLType.Name == core.Bool {
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+	case int64:
+		if col.SQLType.Name == core.Bool {
+			return v != 0
+		}
+	}
+	return value
+}
+
+// sortTablesByDependencies sorts tables so that a table referenced by the columns of another table, by the
+// <table>_id naming convention, comes first. Tables are sorted by name otherwise.
+func sortTablesByDependencies(tables []*core.Table) []*core.Table {
+	byName := map[string]*core.Table{}
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	var sorted []*core.Table
+	visited := map[string]bool{}
+	var visit func(table *core.Table)
+	visit = func(table *core.Table) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+
+		for _, col := range table.ColumnsSeq() {
+			referenced, exists := byName[strings.TrimSuffix(col, "_id")]
+			if strings.HasSuffix(col, "_id") && exists && referenced != table {
+				visit(referenced)
+			}
+		}
+		sorted = append(sorted, table)
+	}
+
+	for _, table := range tables {
+		visit(table)
+	}
+	return sorted
+}
+
+func quoteColumns(ss *SQLStore, names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, ss.Dialect.Quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func countRows(ctx context.Context, ss *SQLStore, table string) (int64, error) {
+	var count int64
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.SQL("SELECT COUNT(*) FROM " + ss.Dialect.Quote(table)).Get(&count)
+		return err
+	})
+	if err != nil {
+		return 0, errutil.Wrapf(err, "failed to count rows of table %s", table)
+	}
+	return count, nil
+}
@@ -0,0 +1,46 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/core"
+)
+
+func TestSortTablesByDependencies(t *testing.T) {
+	table := func(name string, columns ...string) *core.Table {
+		table := core.NewEmptyTable()
+		table.Name = name
+		for _, col := range columns {
+			table.AddColumn(&core.Column{Name: col})
+		}
+		return table
+	}
+
+	tables := []*core.Table{
+		table("dashboard_version", "id", "dashboard_id"),
+		table("dashboard", "id", "org_id", "folder_id"),
+		table("user", "id", "org_id"),
+		table("alert", "id", "org_id", "dashboard_id"),
+		table("org", "id"),
+	}
+
+	var names []string
+	for _, table := range sortTablesByDependencies(tables) {
+		names = append(names, table.Name)
+	}
+	assert.Equal(t, []string{"org", "dashboard", "alert", "dashboard_version", "user"}, names)
+}
+
+func TestConvertColumnValue(t *testing.T) {
+	boolCol := &core.Column{SQLType: core.SQLType{Name: core.Bool}}
+	textCol := &core.Column{SQLType: core.SQLType{Name: core.Text}}
+	blobCol := &core.Column{SQLType: core.SQLType{Name: core.Blob}}
+
+	assert.Equal(t, true, convertColumnValue(int64(1), boolCol))
+	assert.Equal(t, false, convertColumnValue([]byte("0"), boolCol))
+	assert.Equal(t, "text", convertColumnValue([]byte("text"), textCol))
+	assert.Equal(t, []byte("blob"), convertColumnValue([]byte("blob"), blobCol))
+	assert.Equal(t, int64(1), convertColumnValue(int64(1), textCol))
+	assert.Nil(t, convertColumnValue(nil, boolCol))
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
+	"github.com/grafana/grafana/pkg/internal/models"
+	. "github.com/grafana/grafana/pkg/internal/services/sqlstore/migrator"
+	"xorm.io/xorm"
+)
+
+func addDashboardContentMigrations(mg *Migrator) {
+	dashboardContentV1 := Table{
+		Name: "dashboard_content",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "field", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "term", Type: DB_Text, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_content table", NewAddTableMigration(dashboardContentV1))
+	mg.AddMigration("add index dashboard_content.dashboard_id", NewAddIndexMigration(dashboardContentV1, dashboardContentV1.Indices[0]))
+	// the ID of a migration is stored in migration_log, so it is kept as is
+	mg.AddMigration("index content of existing dashboards", &FillDashboardContentMigration{})
+}
+
+// FillDashboardContentMigration stores the content of the dashboards that were saved before
+// the dashboard_content table existed, so that content search finds them.
+type FillDashboardContentMigration struct {
+	MigrationBase
+}
+
+func (m *FillDashboardContentMigration) SQL(dialect Dialect) string {
+	return "code migration"
+}
+
+type dashboardContentDTO struct {
+	Id          int64
+	DashboardId int64
+	PanelId     int64
+	Field       string
+	Term        string
+}
+
+func (m *FillDashboardContentMigration) Exec(sess *xorm.Session, mg *Migrator) error {
+	var dashboards []struct {
+		Id   int64
+		Data *simplejson.Json
+	}
+	if err := sess.SQL("SELECT id, data FROM dashboard").Find(&dashboards); err != nil {
+		return err
+	}
+
+	for _, d := range dashboards {
+		dash := &models.Dashboard{Id: d.Id, Data: d.Data}
+		for _, item := range dash.GetContent() {
+			_, err := sess.Table("dashboard_content").Insert(&dashboardContentDTO{
+				DashboardId: d.Id,
+				PanelId:     item.PanelId,
+				Field:       item.Field,
+				Term:        item.Term,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
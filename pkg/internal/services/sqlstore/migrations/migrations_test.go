@@ -1,6 +1,7 @@
 package migrations
 
 import (
+	"path/filepath"
 	"testing"
 
 	. "github.com/grafana/grafana/pkg/internal/services/sqlstore/migrator"
@@ -48,3 +49,53 @@ func TestMigrations(t *testing.T) {
 	require.True(t, has)
 	require.Equal(t, expectedMigrations, result.Count)
 }
+
+func TestMigrationsStatusAndDryRun(t *testing.T) {
+	x, err := xorm.NewEngine(SQLite, "file:"+filepath.Join(t.TempDir(), "grafana.db"))
+	require.NoError(t, err)
+
+	mg := NewMigrator(x, &setting.Cfg{})
+	AddMigrations(mg)
+
+	statuses, err := mg.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, mg.MigrationsCount())
+	for _, status := range statuses {
+		require.Equal(t, MigrationPending, status.State)
+		require.NotEmpty(t, status.SQL)
+	}
+
+	t.Run("Dry run rolls back the pending migrations", func(t *testing.T) {
+		statuses, err := mg.DryRun()
+		require.NoError(t, err)
+		for _, status := range statuses {
+			require.Equal(t, MigrationPending, status.State, status.ID)
+		}
+
+		exists, err := x.IsTableExist("migration_log")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("Applied migrations are listed after running them", func(t *testing.T) {
+		require.NoError(t, mg.Start())
+
+		statuses, err := mg.Status()
+		require.NoError(t, err)
+		for _, status := range statuses {
+			require.Equal(t, MigrationApplied, status.State, status.ID)
+		}
+	})
+
+	t.Run("Dry run reports the failing migration", func(t *testing.T) {
+		mg.AddMigration("broken migration", NewRawSQLMigration("SELECT * FROM does_not_exist"))
+		mg.AddMigration("next migration", NewRawSQLMigration("SELECT 1"))
+
+		statuses, err := mg.DryRun()
+		require.NoError(t, err)
+		broken := statuses[len(statuses)-2]
+		require.Equal(t, MigrationFailed, broken.State)
+		require.Contains(t, broken.Error, "does_not_exist")
+		require.Equal(t, MigrationPending, statuses[len(statuses)-1].State)
+	})
+}
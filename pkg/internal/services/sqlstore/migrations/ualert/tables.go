@@ -20,6 +20,24 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Alertmanager configurations
 	AddAlertmanagerConfigMigrations(mg)
+
+	// Create the table of the scheduler instances sharing the alert rules
+	AddSchedulerInstanceMigrations(mg)
+
+	// Add recording rules
+	AddRecordingRuleMigrations(mg)
+
+	// Add the paused flags of alert rules and rule groups
+	AddPausedAlertRuleMigrations(mg)
+
+	// Add keeping alerts firing and flap detection
+	AddKeepFiringMigrations(mg)
+
+	// Add sequential rule groups
+	AddSequentialRuleGroupMigrations(mg)
+
+	// Add the expanded annotations of alert instances
+	AddAlertInstanceAnnotationsMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -255,3 +273,89 @@ func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
 		Name: "default", Type: migrator.DB_Bool, Nullable: false, Default: "0",
 	}))
 }
+
+func AddSchedulerInstanceMigrations(mg *migrator.Migrator) {
+	schedulerInstance := migrator.Table{
+		Name: "alert_scheduler_instance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "instance_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "last_heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"instance_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_instance table", migrator.NewAddTableMigration(schedulerInstance))
+	mg.AddMigration("add unique index in alert_scheduler_instance on instance_id column", migrator.NewAddIndexMigration(schedulerInstance, schedulerInstance.Indices[0]))
+}
+
+func AddRecordingRuleMigrations(mg *migrator.Migrator) {
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+
+	recordingConfig := migrator.Table{
+		Name: "alert_recording_config",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "remote_write_url", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "basic_auth_user", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "secure_settings", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_recording_config table", migrator.NewAddTableMigration(recordingConfig))
+	mg.AddMigration("add unique index in alert_recording_config on org_id column", migrator.NewAddIndexMigration(recordingConfig, recordingConfig.Indices[0]))
+}
+
+func AddPausedAlertRuleMigrations(mg *migrator.Migrator) {
+	for _, table := range []string{"alert_rule", "alert_rule_version"} {
+		for _, column := range []string{"is_paused", "rule_group_paused"} {
+			mg.AddMigration(fmt.Sprintf("add column %s to %s", column, table), migrator.NewAddColumnMigration(migrator.Table{Name: table}, &migrator.Column{
+				Name: column, Type: migrator.DB_Bool, Nullable: false, Default: "0",
+			}))
+		}
+	}
+}
+
+func AddKeepFiringMigrations(mg *migrator.Migrator) {
+	for _, table := range []string{"alert_rule", "alert_rule_version"} {
+		mg.AddMigration(fmt.Sprintf("add column keep_firing_for to %s", table), migrator.NewAddColumnMigration(migrator.Table{Name: table}, &migrator.Column{
+			Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+		}))
+	}
+
+	alertInstance := migrator.Table{Name: "alert_instance"}
+	mg.AddMigration("add column keep_firing_since to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "keep_firing_since", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column flapping to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "flapping", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column state_changes to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "state_changes", Type: migrator.DB_Text, Nullable: true,
+	}))
+}
+
+func AddSequentialRuleGroupMigrations(mg *migrator.Migrator) {
+	for _, table := range []string{"alert_rule", "alert_rule_version"} {
+		mg.AddMigration(fmt.Sprintf("add column rule_group_index to %s", table), migrator.NewAddColumnMigration(migrator.Table{Name: table}, &migrator.Column{
+			Name: "rule_group_index", Type: migrator.DB_Int, Nullable: false, Default: "0",
+		}))
+		mg.AddMigration(fmt.Sprintf("add column rule_group_sequential to %s", table), migrator.NewAddColumnMigration(migrator.Table{Name: table}, &migrator.Column{
+			Name: "rule_group_sequential", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+		}))
+	}
+}
+
+func AddAlertInstanceAnnotationsMigrations(mg *migrator.Migrator) {
+	mg.AddMigration("add column annotations to alert_instance", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name: "annotations", Type: migrator.DB_Text, Nullable: true,
+	}))
+}
@@ -204,14 +204,13 @@ func (db *PostgresDialect) IsDeadlock(err error) bool {
 	return db.isThisError(err, "40P01")
 }
 
+// PostInsertId syncs the primary key sequence of a table after rows were inserted with explicit IDs.
 func (db *PostgresDialect) PostInsertId(table string, sess *xorm.Session) error {
-	if table != "org" {
-		return nil
-	}
-
-	// sync primary key sequence of org table
-	if _, err := sess.Exec("SELECT setval('org_id_seq', (SELECT max(id) FROM org));"); err != nil {
-		return errutil.Wrapf(err, "failed to sync primary key for org table")
+	// setval is a no-op for empty tables since max(id) is null
+	quoted := db.Quote(table)
+	if _, err := sess.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), (SELECT max(id) FROM %s));",
+		quoted, quoted)); err != nil {
+		return errutil.Wrapf(err, "failed to sync primary key for %s table", table)
 	}
 	return nil
 }
@@ -0,0 +1,117 @@
+package migrator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/internal/util/errutil"
+)
+
+// ErrDryRunNotSupported is returned by DryRun for databases that commit schema changes implicitly.
+var ErrDryRunNotSupported = errors.New("dry run is not supported for MySQL since schema changes are committed implicitly")
+
+// MigrationState is the state of a migration in the migration log.
+type MigrationState string
+
+const (
+	MigrationApplied MigrationState = "applied"
+	MigrationPending MigrationState = "pending"
+	// MigrationFailed is the state of a pending migration that failed the last time it was run.
+	MigrationFailed MigrationState = "failed"
+)
+
+// MigrationStatus is the status of a migration. SQL is the SQL the migration executes with the dialect of the
+// database, or "code migration" for migrations implemented in code. Error is the error of the last run of a
+// failed migration, or of the dry run.
+type MigrationStatus struct {
+	ID        string
+	State     MigrationState
+	Timestamp time.Time
+	SQL       string
+	Error     string
+}
+
+// Status returns the status of every migration, in the order they run.
+func (mg *Migrator) Status() ([]MigrationStatus, error) {
+	var logItems []MigrationLog
+	exists, err := mg.x.IsTableExist(new(MigrationLog))
+	if err != nil {
+		return nil, errutil.Wrap("failed to check table existence", err)
+	}
+	if exists {
+		if err := mg.x.Asc("id").Find(&logItems); err != nil {
+			return nil, err
+		}
+	}
+
+	logMap := map[string]MigrationLog{}
+	for _, logItem := range logItems {
+		if existing, ok := logMap[logItem.MigrationID]; ok && existing.Success {
+			continue
+		}
+		logMap[logItem.MigrationID] = logItem
+	}
+
+	statuses := make([]MigrationStatus, 0, len(mg.migrations))
+	for _, m := range mg.migrations {
+		status := MigrationStatus{ID: m.Id(), State: MigrationPending, SQL: m.SQL(mg.Dialect)}
+		if logItem, ok := logMap[m.Id()]; ok {
+			status.Timestamp = logItem.Timestamp
+			if logItem.Success {
+				status.State = MigrationApplied
+			} else {
+				status.State = MigrationFailed
+				status.Error = logItem.Error
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// DryRun runs the pending migrations in a single transaction that is rolled back, and returns their status.
+// It stops at the first migration that fails, whose status holds the error. The migrations after it are
+// returned as pending.
+func (mg *Migrator) DryRun() ([]MigrationStatus, error) {
+	if mg.Dialect.DriverName() == MySQL {
+		return nil, ErrDryRunNotSupported
+	}
+
+	statuses, err := mg.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	sess := mg.x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	var ran int
+	var failed bool
+	for i := range statuses {
+		if statuses[i].State == MigrationApplied {
+			continue
+		}
+
+		// Statuses are in the order of the migrations, whose IDs are not unique.
+		if err := mg.exec(mg.migrations[i], sess); err != nil {
+			statuses[i].State = MigrationFailed
+			statuses[i].Error = err.Error()
+			failed = true
+			break
+		}
+		statuses[i].State = MigrationPending
+		statuses[i].Error = ""
+		ran++
+	}
+
+	if err := sess.Rollback(); err != nil {
+		return nil, errutil.Wrap("failed to roll back dry run", err)
+	}
+	mg.Logger.Info("Rolled back dry run of migrations", "migrations", ran, "failed", failed)
+
+	return statuses, nil
+}
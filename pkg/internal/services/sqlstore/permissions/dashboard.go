@@ -1,6 +1,7 @@
 package permissions
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/internal/models"
@@ -25,17 +26,14 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 		okRoles = append(okRoles, models.ROLE_VIEWER)
 	}
 
-	falseStr := d.Dialect.BooleanStr(false)
-
 	sql := `(
 		dashboard.id IN (
 			SELECT distinct DashboardId from (
 				SELECT d.id AS DashboardId
 					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					` + AncestorFolderJoins("d") + `
 					LEFT JOIN dashboard_acl AS da ON
-						da.dashboard_id = d.id OR
-						da.dashboard_id = d.folder_id
+						` + ACLOnDashboardOrAncestors("da", "d") + `
 					LEFT JOIN team_member as ugm on ugm.team_id = da.team_id
 					WHERE
 						d.org_id = ? AND
@@ -48,14 +46,11 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 				UNION
 				SELECT d.id AS DashboardId
 					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					` + AncestorFolderJoins("d") + `
 					LEFT JOIN dashboard_acl AS da ON
 						(
 							-- include default permissions -->
-							da.org_id = -1 AND (
-							  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-							  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
-							)
+							da.org_id = -1 AND ` + UsesDefaultPermissions(d.Dialect, "d") + `
 						)
 					WHERE
 						d.org_id = ? AND
@@ -75,3 +70,61 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 	params = append(params, okRoles...)
 	return sql, params
 }
+
+// ancestorFolderAlias returns the alias of the ancestor folder of a dashboard at the given level,
+// where the parent folder is at level 1.
+func ancestorFolderAlias(level int) string {
+	return fmt.Sprintf("folder%d", level)
+}
+
+// AncestorFolderJoins returns LEFT JOINs for all ancestor folders of the dashboard with alias dashAlias,
+// aliased folder1 (the parent folder) to folderN, where N is models.MaxFolderDepth.
+func AncestorFolderJoins(dashAlias string) string {
+	joins := make([]string, 0, models.MaxFolderDepth)
+	child := dashAlias
+	for level := 1; level <= models.MaxFolderDepth; level++ {
+		alias := ancestorFolderAlias(level)
+		joins = append(joins, fmt.Sprintf("LEFT JOIN dashboard AS %s ON %s.id = %s.folder_id", alias, alias, child))
+		child = alias
+	}
+	return strings.Join(joins, "\n")
+}
+
+// ACLOnDashboardOrAncestors returns a condition matching the ACL items with alias aclAlias that are set on
+// the dashboard with alias dashAlias or on one of its ancestor folders. It requires AncestorFolderJoins.
+func ACLOnDashboardOrAncestors(aclAlias, dashAlias string) string {
+	conditions := []string{fmt.Sprintf("%s.dashboard_id = %s.id", aclAlias, dashAlias)}
+	for level := 1; level <= models.MaxFolderDepth; level++ {
+		conditions = append(conditions, fmt.Sprintf("%s.dashboard_id = %s.id", aclAlias, ancestorFolderAlias(level)))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// UsesDefaultPermissions returns a condition matching dashboards with alias dashAlias that fall back to the
+// default permissions. Dashboards in a folder use the default permissions unless the folder or one of its
+// ancestors has permissions of its own. It requires AncestorFolderJoins.
+func UsesDefaultPermissions(dialect migrator.Dialect, dashAlias string) string {
+	falseStr := dialect.BooleanStr(false)
+
+	inFolder := []string{fmt.Sprintf("%s.id IS NOT NULL", ancestorFolderAlias(1))}
+	for level := 1; level <= models.MaxFolderDepth; level++ {
+		alias := ancestorFolderAlias(level)
+		inFolder = append(inFolder, fmt.Sprintf("(%s.id IS NULL OR %s.has_acl = %s)", alias, alias, falseStr))
+	}
+
+	return fmt.Sprintf("((%s) OR (%s.id IS NULL AND %s.has_acl = %s))",
+		strings.Join(inFolder, " AND "), ancestorFolderAlias(1), dashAlias, falseStr)
+}
+
+// HasACLOnDashboardOrAncestors returns a condition matching dashboards with alias dashAlias that have permissions
+// of their own or in one of their ancestor folders. It requires AncestorFolderJoins.
+func HasACLOnDashboardOrAncestors(dialect migrator.Dialect, dashAlias string) string {
+	trueStr := dialect.BooleanStr(true)
+	falseStr := dialect.BooleanStr(false)
+
+	conditions := []string{fmt.Sprintf("%s.has_acl = %s", dashAlias, trueStr)}
+	for level := 1; level <= models.MaxFolderDepth; level++ {
+		conditions = append(conditions, fmt.Sprintf("COALESCE(%s.has_acl, %s) = %s", ancestorFolderAlias(level), falseStr, trueStr))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
@@ -4,9 +4,8 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 	"github.com/grafana/grafana/pkg/internal/models"
-	"github.com/grafana/grafana/pkg/internal/setting"
-	"github.com/grafana/grafana/pkg/internal/util"
 )
 
 func init() {
@@ -79,7 +78,7 @@ func UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) error {
 			return err
 		}
 		for key, data := range cmd.SecureJsonData {
-			encryptedData, err := util.Encrypt([]byte(data), setting.SecretKey)
+			encryptedData, err := envelope.Encrypt([]byte(data))
 			if err != nil {
 				return err
 			}
@@ -28,7 +28,7 @@ func (b *Builder) ToSQL(limit, page int64) (string, []interface{}) {
 	b.buildSelect()
 
 	b.sql.WriteString("( ")
-	orderQuery := b.applyFilters()
+	orderQuery, orderParams := b.applyFilters()
 
 	b.sql.WriteString(b.Dialect.LimitOffset(limit, (page-1)*limit) + `) AS ids
 		INNER JOIN dashboard ON ids.id = dashboard.id`)
@@ -39,6 +39,7 @@ func (b *Builder) ToSQL(limit, page int64) (string, []interface{}) {
 		LEFT OUTER JOIN dashboard_tag ON dashboard.id = dashboard_tag.dashboard_id`)
 	b.sql.WriteString("\n")
 	b.sql.WriteString(orderQuery)
+	b.params = append(b.params, orderParams...)
 
 	return b.sql.String(), b.params
 }
@@ -66,7 +67,7 @@ func (b *Builder) buildSelect() {
 	b.sql.WriteString(` FROM `)
 }
 
-func (b *Builder) applyFilters() (ordering string) {
+func (b *Builder) applyFilters() (ordering string, orderParams []interface{}) {
 	joins := []string{}
 	orderJoins := []string{}
 
@@ -77,6 +78,7 @@ func (b *Builder) applyFilters() (ordering string) {
 	groupParams := []interface{}{}
 
 	orders := []string{}
+	orderParams = []interface{}{}
 
 	for _, f := range b.Filters {
 		if f, ok := f.(FilterLeftJoin); ok {
@@ -104,6 +106,9 @@ func (b *Builder) applyFilters() (ordering string) {
 				orderJoins = append(orderJoins, fmt.Sprintf(" LEFT OUTER JOIN %s ", f.LeftJoin()))
 			}
 			orders = append(orders, f.OrderBy())
+			if f, ok := f.(FilterOrderByParams); ok {
+				orderParams = append(orderParams, f.OrderByParams()...)
+			}
 		}
 	}
 
@@ -126,8 +131,9 @@ func (b *Builder) applyFilters() (ordering string) {
 
 	orderBy := fmt.Sprintf(" ORDER BY %s", strings.Join(orders, ", "))
 	b.sql.WriteString(orderBy)
+	b.params = append(b.params, orderParams...)
 
 	order := strings.Join(orderJoins, "")
 	order += orderBy
-	return order
+	return order, orderParams
 }
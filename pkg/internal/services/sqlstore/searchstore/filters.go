@@ -26,6 +26,12 @@ type FilterOrderBy interface {
 	OrderBy() string
 }
 
+// FilterOrderByParams can be implemented by a FilterOrderBy whose
+// ordering contains SQL parameters.
+type FilterOrderByParams interface {
+	OrderByParams() []interface{}
+}
+
 // FilterLeftJoin adds the returned string as a "LEFT OUTER JOIN" to
 // allow for fetching extra columns from a table outside of the
 // dashboard column.
@@ -86,6 +92,65 @@ func (f TitleFilter) Where() (string, []interface{}) {
 	return fmt.Sprintf("dashboard.title %s ?", f.Dialect.LikeStr()), []interface{}{"%" + f.Title + "%"}
 }
 
+// ContentFilter limits the result to dashboards with content matching the query,
+// such as panel titles, query expressions, data sources and variable names.
+// The dashboards are ranked by their matches, with matches in titles and in
+// the dashboard itself ranking higher than matches in panels.
+type ContentFilter struct {
+	Dialect migrator.Dialect
+	Query   string
+	// Fields limits the matches to the given fields of the content, if set.
+	Fields []string
+}
+
+func (f ContentFilter) Where() (string, []interface{}) {
+	sql, params := f.match()
+	return "dashboard.id IN (SELECT dashboard_content.dashboard_id FROM dashboard_content WHERE " + sql + ")", params
+}
+
+func (f ContentFilter) OrderBy() string {
+	sql, _ := f.match()
+	return `(SELECT SUM(CASE
+				WHEN dashboard_content.field = 'title' AND dashboard_content.panel_id = 0 THEN 8
+				WHEN dashboard_content.field = 'title' THEN 4
+				WHEN dashboard_content.panel_id = 0 THEN 2
+				ELSE 1 END)
+			FROM dashboard_content
+			WHERE dashboard_content.dashboard_id = dashboard.id AND ` + sql + `) DESC`
+}
+
+func (f ContentFilter) OrderByParams() []interface{} {
+	_, params := f.match()
+	return params
+}
+
+func (f ContentFilter) match() (string, []interface{}) {
+	sql, param := LikeContains(f.Dialect, "dashboard_content.term", f.Query)
+	params := []interface{}{param}
+
+	if len(f.Fields) > 0 {
+		sql += " AND dashboard_content.field IN (?" + strings.Repeat(",?", len(f.Fields)-1) + ")"
+		for _, field := range f.Fields {
+			params = append(params, field)
+		}
+	}
+
+	return sql, params
+}
+
+// likeEscape is the escape character of LIKE patterns. It isn't a backslash, which MySQL string
+// literals would have to escape in turn.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// LikeContains returns the condition matching the column when it contains value, and its parameter.
+// The % and _ wildcards in value are matched literally.
+func LikeContains(dialect migrator.Dialect, column, value string) (string, interface{}) {
+	sql := fmt.Sprintf("%s %s ? ESCAPE '%s'", column, dialect.LikeStr(), likeEscape)
+	return sql, "%" + likeEscaper.Replace(value) + "%"
+}
+
 type FolderFilter struct {
 	IDs []int64
 }
@@ -85,17 +85,7 @@ func (ss *SQLStore) Init() error {
 	dialect = ss.Dialect
 
 	if !ss.dbCfg.SkipMigrations {
-		migrator := migrator.NewMigrator(ss.engine, ss.Cfg)
-		migrations.AddMigrations(migrator)
-
-		for _, descriptor := range registry.GetServices() {
-			sc, ok := descriptor.Instance.(registry.DatabaseMigrator)
-			if ok {
-				sc.AddMigration(migrator)
-			}
-		}
-
-		if err := migrator.Start(); err != nil {
+		if err := ss.newMigrator().Start(); err != nil {
 			return err
 		}
 	}
@@ -123,6 +113,42 @@ func (ss *SQLStore) Init() error {
 	return nil
 }
 
+// Migrate connects to the database and runs the migrations. Unlike Init, it doesn't register handlers
+// or set the global state used by other services, so it can be used for a second database such as
+// the target of CopyDatabase.
+func (ss *SQLStore) Migrate() error {
+	mg, err := ss.NewMigrator()
+	if err != nil {
+		return err
+	}
+	return mg.Start()
+}
+
+// NewMigrator connects to the database and returns a migrator with all migrations, without running them.
+func (ss *SQLStore) NewMigrator() (*migrator.Migrator, error) {
+	ss.log = log.New("sqlstore")
+	if err := ss.initEngine(); err != nil {
+		return nil, errutil.Wrap("failed to connect to database", err)
+	}
+
+	ss.Dialect = migrator.NewDialect(ss.engine)
+	return ss.newMigrator(), nil
+}
+
+func (ss *SQLStore) newMigrator() *migrator.Migrator {
+	migrator := migrator.NewMigrator(ss.engine, ss.Cfg)
+	migrations.AddMigrations(migrator)
+
+	for _, descriptor := range registry.GetServices() {
+		sc, ok := descriptor.Instance.(registry.DatabaseMigrator)
+		if ok {
+			sc.AddMigration(migrator)
+		}
+	}
+
+	return migrator
+}
+
 // Sync syncs changes to the database.
 func (ss *SQLStore) Sync() error {
 	return ss.engine.Sync2()
@@ -7,3 +7,12 @@ type DashboardTag struct {
 	DashboardId int64
 	Term        string
 }
+
+// DashboardContent is a piece of text from a dashboard stored for content search.
+type DashboardContent struct {
+	Id          int64
+	DashboardId int64
+	PanelId     int64
+	Field       string
+	Term        string
+}
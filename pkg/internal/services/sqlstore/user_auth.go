@@ -6,9 +6,8 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/envelope"
 	"github.com/grafana/grafana/pkg/internal/models"
-	"github.com/grafana/grafana/pkg/internal/setting"
-	"github.com/grafana/grafana/pkg/internal/util"
 )
 
 var getTime = time.Now
@@ -269,9 +268,9 @@ func DeleteAuthInfo(cmd *models.DeleteAuthInfoCommand) error {
 }
 
 // decodeAndDecrypt will decode the string with the standard bas64 decoder
-// and then decrypt it with grafana's secretKey
+// and then decrypt it with envelope encryption or grafana's secretKey
 func decodeAndDecrypt(s string) (string, error) {
-	// Bail out if empty string since it'll cause a segfault in util.Decrypt
+	// Bail out if empty string since it'll cause a segfault in envelope.Decrypt
 	if s == "" {
 		return "", nil
 	}
@@ -279,17 +278,17 @@ func decodeAndDecrypt(s string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	decrypted, err := util.Decrypt(decoded, setting.SecretKey)
+	decrypted, err := envelope.Decrypt(decoded)
 	if err != nil {
 		return "", err
 	}
 	return string(decrypted), nil
 }
 
-// encryptAndEncode will encrypt a string with grafana's secretKey, and
+// encryptAndEncode will encrypt a string with envelope encryption or grafana's secretKey, and
 // then encode it with the standard bas64 encoder
 func encryptAndEncode(s string) (string, error) {
-	encrypted, err := util.Encrypt([]byte(s), setting.SecretKey)
+	encrypted, err := envelope.Encrypt([]byte(s))
 	if err != nil {
 		return "", err
 	}
@@ -86,13 +86,16 @@ var (
 	StaticRootPath                 string
 
 	// Security settings.
-	SecretKey              string
-	DisableGravatar        bool
-	EmailCodeValidMinutes  int
-	DataProxyWhiteList     map[string]bool
-	CookieSecure           bool
-	CookieSameSiteDisabled bool
-	CookieSameSiteMode     http.SameSite
+	SecretKey                          string
+	EnvelopeEncryptionProvider         string
+	EnvelopeEncryptionKeyFile          string
+	EnvelopeEncryptionPreviousKeyFiles []string
+	DisableGravatar                    bool
+	EmailCodeValidMinutes              int
+	DataProxyWhiteList                 map[string]bool
+	CookieSecure                       bool
+	CookieSameSiteDisabled             bool
+	CookieSameSiteMode                 http.SameSite
 
 	// Snapshots
 	ExternalSnapshotUrl   string
@@ -256,8 +259,12 @@ type Cfg struct {
 	PluginsAppsSkipVerifyTLS bool
 	PluginSettings           PluginSettings
 	PluginsAllowUnsigned     []string
+	PluginSigningKeys        []PluginSigningKey
 	CatalogURL               string
 	CatalogAppEnabled        bool
+	// PluginsHotReload enables watching of the external plugin directories for added, updated or removed plugins.
+	PluginsHotReload         bool
+	PluginsHotReloadInterval time.Duration
 	DisableSanitizeHtml      bool
 	EnterpriseLicensePath    string
 
@@ -269,7 +276,9 @@ type Cfg struct {
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
-	DefaultHomeDashboardPath string
+	DefaultHomeDashboardPath  string
+	DashboardSchemaValidation bool
+	DashboardSchemaMigration  bool
 
 	// Auth
 	LoginCookieName              string
@@ -341,6 +350,11 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationsBackend                 string
+	AnnotationsLoki                    AnnotationsLokiSettings
+
+	// Unified Alerting
+	UnifiedAlerting UnifiedAlertingSettings
 
 	// Sentry config
 	Sentry Sentry
@@ -525,6 +539,37 @@ func (cfg *Cfg) readAnnotationSettings() {
 	cfg.AlertingAnnotationCleanupSetting = newAnnotationCleanupSettings(alertingSection, "max_annotation_age")
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
+
+	cfg.AnnotationsBackend = section.Key("backend").In("sql", []string{"sql", "loki"})
+	lokiSection := cfg.Raw.Section("annotations.loki")
+	cfg.AnnotationsLoki = AnnotationsLokiSettings{
+		URL:               lokiSection.Key("url").MustString(""),
+		TenantID:          lokiSection.Key("tenant_id").MustString(""),
+		BasicAuthUser:     lokiSection.Key("basic_auth_user").MustString(""),
+		BasicAuthPassword: lokiSection.Key("basic_auth_password").MustString(""),
+		Types:             util.SplitString(lokiSection.Key("types").MustString("alert")),
+	}
+}
+
+func (cfg *Cfg) readUnifiedAlertingSettings() {
+	section := cfg.Raw.Section("unified_alerting")
+	cfg.UnifiedAlerting = UnifiedAlertingSettings{
+		SchedulerSharding:        section.Key("scheduler_sharding").MustBool(false),
+		SchedulerInstanceTimeout: section.Key("scheduler_instance_timeout").MustDuration(30 * time.Second),
+		// The instance name is suffixed so that replicas sharing a host name have distinct identifiers.
+		InstanceID: fmt.Sprintf("%s-%s", InstanceName, util.GenerateShortUID()),
+
+		EvaluationTimeout:                     section.Key("evaluation_timeout").MustDuration(30 * time.Second),
+		MaxAttempts:                           section.Key("max_attempts").MustInt64(3),
+		RetryBackoff:                          section.Key("retry_backoff").MustDuration(time.Second),
+		MaxConcurrentEvaluationsPerDatasource: section.Key("max_concurrent_evaluations_per_datasource").MustInt(0),
+
+		FlapDetectionWindow:    section.Key("flap_detection_window").MustDuration(time.Hour),
+		FlapDetectionThreshold: section.Key("flap_detection_threshold").MustInt(0),
+	}
+	if cfg.UnifiedAlerting.MaxAttempts < 1 {
+		cfg.UnifiedAlerting.MaxAttempts = 1
+	}
 }
 
 func (cfg *Cfg) readExpressionsSettings() {
@@ -537,6 +582,40 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+// UnifiedAlertingSettings configures the unified alerting scheduler. When SchedulerSharding is enabled, the
+// alert rules are partitioned between the instances sharing the database, identified by InstanceID. Instances
+// that have not sent a heartbeat for SchedulerInstanceTimeout are considered gone.
+//
+// The evaluations failing to execute are attempted up to MaxAttempts times, waiting RetryBackoff, doubled
+// after each attempt, between the attempts. MaxConcurrentEvaluationsPerDatasource limits the evaluations
+// querying each data source at once, zero meaning no limit.
+//
+// The alerts changing state more than FlapDetectionThreshold times within FlapDetectionWindow are marked
+// as flapping, zero disabling the detection.
+type UnifiedAlertingSettings struct {
+	SchedulerSharding        bool
+	SchedulerInstanceTimeout time.Duration
+	InstanceID               string
+
+	EvaluationTimeout                     time.Duration
+	MaxAttempts                           int64
+	RetryBackoff                          time.Duration
+	MaxConcurrentEvaluationsPerDatasource int
+
+	FlapDetectionWindow    time.Duration
+	FlapDetectionThreshold int
+}
+
+// AnnotationsLokiSettings configures the Loki annotation backend. Types lists the kinds of annotations
+// stored in Loki: alert and dashboard.
+type AnnotationsLokiSettings struct {
+	URL               string
+	TenantID          string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Types             []string
+}
+
 func envKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -844,6 +923,8 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardSchemaValidation = dashboards.Key("schema_validation").MustBool(false)
+	cfg.DashboardSchemaMigration = dashboards.Key("schema_migration").MustBool(false)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -885,6 +966,10 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.PluginsEnableAlpha = pluginsSection.Key("enable_alpha").MustBool(false)
 	cfg.PluginsAppsSkipVerifyTLS = pluginsSection.Key("app_tls_skip_verify_insecure").MustBool(false)
 	cfg.PluginSettings = extractPluginSettings(iniFile.Sections())
+	cfg.PluginSigningKeys, err = extractPluginSigningKeys(iniFile.Sections())
+	if err != nil {
+		return err
+	}
 	pluginsAllowUnsigned := pluginsSection.Key("allow_loading_unsigned_plugins").MustString("")
 	for _, plug := range strings.Split(pluginsAllowUnsigned, ",") {
 		plug = strings.TrimSpace(plug)
@@ -892,6 +977,8 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	}
 	cfg.CatalogURL = pluginsSection.Key("catalog_url").MustString("https://grafana.com/grafana/plugins/")
 	cfg.CatalogAppEnabled = pluginsSection.Key("catalog_app_enabled").MustBool(false)
+	cfg.PluginsHotReload = pluginsSection.Key("hot_reload").MustBool(false)
+	cfg.PluginsHotReloadInterval = pluginsSection.Key("hot_reload_interval").MustDuration(10 * time.Second)
 
 	// Read and populate feature toggles list
 	featureTogglesSection := iniFile.Section("feature_toggles")
@@ -914,6 +1001,7 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readQuotaSettings()
 	cfg.readAnnotationSettings()
 	cfg.readExpressionsSettings()
+	cfg.readUnifiedAlertingSettings()
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -1077,6 +1165,9 @@ func (cfg *Cfg) SectionWithEnvOverrides(s string) *DynamicSection {
 func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	security := iniFile.Section("security")
 	SecretKey = valueAsString(security, "secret_key", "")
+	EnvelopeEncryptionProvider = valueAsString(security, "envelope_encryption_provider", "")
+	EnvelopeEncryptionKeyFile = valueAsString(security, "envelope_encryption_key_file", "")
+	EnvelopeEncryptionPreviousKeyFiles = util.SplitString(valueAsString(security, "envelope_encryption_previous_key_files", ""))
 	DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
 
@@ -1,8 +1,10 @@
 package setting
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/internal/util"
 	"gopkg.in/ini.v1"
 )
 
@@ -23,3 +25,53 @@ func extractPluginSettings(sections []*ini.Section) PluginSettings {
 
 	return psMap
 }
+
+// PluginSigningKey is a public key trusted to sign plugins, in addition to Grafana's own key.
+type PluginSigningKey struct {
+	Name          string
+	PublicKeyPath string
+	// SignatureTypes restricts the signature types of the plugins the key may sign. If empty, the key may sign any
+	// type but grafana.
+	SignatureTypes []string
+	// RootURLs restricts the key to Grafana instances running at one of the URLs, if not empty.
+	RootURLs []string
+}
+
+// pluginSignatureTypes are the plugin signature types a signing key can be restricted to.
+var pluginSignatureTypes = []string{"grafana", "private"}
+
+func extractPluginSigningKeys(sections []*ini.Section) ([]PluginSigningKey, error) {
+	var keys []PluginSigningKey
+	for _, section := range sections {
+		sectionName := section.Name()
+		if !strings.HasPrefix(sectionName, "plugin_signing_key.") {
+			continue
+		}
+
+		signatureTypes := util.SplitString(section.Key("signature_types").String())
+		for _, t := range signatureTypes {
+			if !isPluginSignatureType(t) {
+				return nil, fmt.Errorf("invalid signature type %q in [%s], expected one of: %s", t, sectionName,
+					strings.Join(pluginSignatureTypes, ", "))
+			}
+		}
+
+		keys = append(keys, PluginSigningKey{
+			Name:           strings.TrimPrefix(sectionName, "plugin_signing_key."),
+			PublicKeyPath:  section.Key("public_key_path").String(),
+			SignatureTypes: signatureTypes,
+			RootURLs:       util.SplitString(section.Key("root_urls").String()),
+		})
+	}
+
+	return keys, nil
+}
+
+func isPluginSignatureType(t string) bool {
+	for _, signatureType := range pluginSignatureTypes {
+		if t == signatureType {
+			return true
+		}
+	}
+	return false
+}
@@ -41,3 +41,32 @@ func TestPluginSettings(t *testing.T) {
 	require.Equal(t, ps["plugin2"]["key3"], "value3")
 	require.Equal(t, ps["plugin2"]["key4"], "value4")
 }
+
+func TestPluginSigningKeys(t *testing.T) {
+	cfg := NewCfg()
+	sec, err := cfg.Raw.NewSection("plugin_signing_key.internal")
+	require.NoError(t, err)
+	_, err = sec.NewKey("public_key_path", "/etc/grafana/internal.asc")
+	require.NoError(t, err)
+	_, err = sec.NewKey("signature_types", "private, grafana")
+	require.NoError(t, err)
+	_, err = sec.NewKey("root_urls", "https://grafana.internal/")
+	require.NoError(t, err)
+
+	_, err = cfg.Raw.NewSection("plugin.plugin")
+	require.NoError(t, err)
+
+	keys, err := extractPluginSigningKeys(cfg.Raw.Sections())
+	require.NoError(t, err)
+	require.Equal(t, []PluginSigningKey{{
+		Name:           "internal",
+		PublicKeyPath:  "/etc/grafana/internal.asc",
+		SignatureTypes: []string{"private", "grafana"},
+		RootURLs:       []string{"https://grafana.internal/"},
+	}}, keys)
+
+	sec.Key("signature_types").SetValue("privat")
+	_, err = extractPluginSigningKeys(cfg.Raw.Sections())
+	require.EqualError(t, err,
+		`invalid signature type "privat" in [plugin_signing_key.internal], expected one of: grafana, private`)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/internal/bus"
+	"github.com/grafana/grafana/pkg/internal/components/simplejson"
 	"github.com/grafana/grafana/pkg/internal/models"
 	apimodels "github.com/grafana/grafana/pkg/internal/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/internal/services/ngalert/models"
@@ -296,3 +297,70 @@ func createRule(t *testing.T, grafanaListedAddr string, folder string) {
 	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
 	require.JSONEq(t, `{"message":"rule group updated successfully"}`, string(b))
 }
+
+func TestAlertRulesInSubfolder(t *testing.T) {
+	// Setup Grafana and its Database
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "grafana", "password"))
+
+	// the subfolder shares its title with a top level folder
+	require.NoError(t, createFolder(t, store, 0, "folder1"))
+	parent, err := store.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     1,
+		IsFolder:  true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "parent"}),
+	})
+	require.NoError(t, err)
+	subfolder, err := store.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     1,
+		FolderId:  parent.Id,
+		IsFolder:  true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "folder1"}),
+	})
+	require.NoError(t, err)
+
+	// the rules of a subfolder are posted to the UID of the folder
+	createRule(t, grafanaListedAddr, subfolder.Uid)
+
+	getBody := func(t *testing.T, u string, status int) []byte {
+		t.Helper()
+		// nolint:gosec
+		resp, err := http.Get(u)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			err := resp.Body.Close()
+			require.NoError(t, err)
+		})
+		require.Equal(t, status, resp.StatusCode)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return b
+	}
+
+	t.Run("the rules are found by the UID of the subfolder", func(t *testing.T) {
+		var byUID apimodels.NamespaceConfigResponse
+		require.NoError(t, json.Unmarshal(getBody(t, fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/%s", grafanaListedAddr, subfolder.Uid), http.StatusAccepted), &byUID))
+		require.Len(t, byUID[subfolder.Uid], 1)
+		assert.Equal(t, fmt.Sprintf("rule under folder %s", subfolder.Uid), byUID[subfolder.Uid][0].Rules[0].GrafanaManagedAlert.Title)
+
+		var byTitle apimodels.NamespaceConfigResponse
+		require.NoError(t, json.Unmarshal(getBody(t, fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/folder1", grafanaListedAddr), http.StatusAccepted), &byTitle))
+		assert.Empty(t, byTitle["folder1"])
+	})
+
+	t.Run("the rules are exported by folder UID", func(t *testing.T) {
+		var export apimodels.RulesExport
+		require.NoError(t, json.Unmarshal(getBody(t, fmt.Sprintf("http://grafana:password@%s/api/v1/rules/export?format=json", grafanaListedAddr), http.StatusOK), &export))
+		require.Len(t, export, 1)
+		require.Len(t, export[subfolder.Uid], 1)
+		assert.Equal(t, "arulegroup", export[subfolder.Uid][0].Name)
+	})
+}